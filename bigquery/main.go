@@ -62,6 +62,7 @@ type bqNotifier struct {
 	filter   notifiers.EventFilter
 	tmpl     *template.Template
 	client   bq
+	name     string
 	br       notifiers.BindingResolver
 	tmplView *notifiers.TemplateView
 }
@@ -157,6 +158,8 @@ func imageManifestToBuildImage(image string) (*buildImage, error) {
 }
 
 func (n *bqNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, bigQueryJson string, _ notifiers.SecretGetter, br notifiers.BindingResolver) error {
+	n.name = notifiers.NotifierName(cfg, n)
+
 	prd, err := notifiers.MakeCELPredicate(cfg.Spec.Notification.Filter)
 	if err != nil {
 		return fmt.Errorf("failed to make a CEL predicate: %v", err)
@@ -166,18 +169,21 @@ func (n *bqNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, bigQueryJ
 		return fmt.Errorf("expected table string: %v", cfg.Spec.Notification.Delivery)
 	}
 
+	// Extract dataset id and table id from config
+	rs := tableResource.FindStringSubmatch(parsed)
+	if len(rs) != 3 {
+		return fmt.Errorf("failed to parse valid table URI: %v", parsed)
+	}
+	if notifiers.Validating(ctx) {
+		return nil
+	}
+
 	// Initialize client
 	n.filter = prd
 	n.client, err = n.bqf.Make(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to initialize bigquery client: %v", err)
 	}
-
-	// Extract dataset id and table id from config
-	rs := tableResource.FindStringSubmatch(parsed)
-	if len(rs) != 3 {
-		return fmt.Errorf("failed to parse valid table URI: %v", parsed)
-	}
 	if err = n.client.EnsureDataset(ctx, rs[1]); err != nil {
 		return err
 	}
@@ -185,7 +191,7 @@ func (n *bqNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, bigQueryJ
 		return err
 	}
 
-	tmpl, err := template.New("bq_json_template").Parse(bigQueryJson)
+	tmpl, err := template.New("bq_json_template").Funcs(notifiers.TemplateFuncs()).Parse(bigQueryJson)
 	n.tmpl = tmpl
 	n.br = br
 
@@ -287,8 +293,9 @@ func (n *bqNotifier) SendNotification(ctx context.Context, build *cbpb.Build) er
 	}
 
 	n.tmplView = &notifiers.TemplateView{
-		Build:  &notifiers.BuildView{Build: build},
-		Params: bindings,
+		Build:        &notifiers.BuildView{Build: build},
+		Params:       bindings,
+		NotifierName: n.name,
 	}
 	var buf bytes.Buffer
 	if err := n.tmpl.Execute(&buf, n.tmplView); err != nil {
@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// maxAnnotations is the most annotations GitHub accepts in one check run request.
+const maxAnnotations = 50
+
+// maxAnnotatedLogLineBytes is the longest build log line that is parsed for annotations.
+const maxAnnotatedLogLineBytes = 1 << 20
+
+// annotationPattern matches the `path:line[:column]: message` diagnostics of most compilers, linters, and test runners,
+// with the path relative to the repo root or to /workspace, where Cloud Build checks it out.
+var annotationPattern = regexp.MustCompile(`^(?:\./|/workspace/)?([\w.-]+(?:/[\w.-]+)*\.\w+):(\d+)(?::(\d+))?:\s+(.+)$`)
+
+// checkRunAnnotation is a check run annotation, which GitHub shows on the annotated line of a pull request's diff.
+// See https://docs.github.com/en/rest/checks/runs#update-a-check-run.
+type checkRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	StartColumn     int    `json:"start_column,omitempty"`
+	EndColumn       int    `json:"end_column,omitempty"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+// failedStepPrefixes maps the prefix that Cloud Build puts on the log lines of each failed step of the build, e.g.
+// `Step #2 - "test": `, to the step's name for annotation titles.
+func failedStepPrefixes(build *cbpb.Build) map[string]string {
+	prefixes := map[string]string{}
+	for i, s := range build.GetSteps() {
+		switch s.GetStatus() {
+		case cbpb.Build_FAILURE, cbpb.Build_TIMEOUT:
+		default:
+			continue
+		}
+		prefix := notifiers.StepLogPrefix(i, s)
+		prefixes[prefix] = strings.TrimSuffix(prefix, ": ")
+	}
+	return prefixes
+}
+
+// parseAnnotations returns the failure annotations for the diagnostics that the build's failed steps logged, without
+// duplicates and at most maxAnnotations of them.
+func parseAnnotations(build *cbpb.Build, r io.Reader) ([]checkRunAnnotation, error) {
+	prefixes := failedStepPrefixes(build)
+	if len(prefixes) == 0 {
+		return nil, nil
+	}
+	var annotations []checkRunAnnotation
+	seen := map[checkRunAnnotation]bool{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxAnnotatedLogLineBytes)
+	for sc.Scan() && len(annotations) < maxAnnotations {
+		line := sc.Text()
+		for prefix, step := range prefixes {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			m := annotationPattern.FindStringSubmatch(strings.TrimSpace(notifiers.StripANSI(strings.TrimPrefix(line, prefix))))
+			if m == nil {
+				break
+			}
+			a := checkRunAnnotation{Path: m[1], AnnotationLevel: "failure", Title: step, Message: m[4]}
+			a.StartLine, _ = strconv.Atoi(m[2])
+			a.EndLine = a.StartLine
+			if m[3] != "" {
+				a.StartColumn, _ = strconv.Atoi(m[3])
+				a.EndColumn = a.StartColumn
+			}
+			if a.StartLine > 0 && !seen[a] {
+				seen[a] = true
+				annotations = append(annotations, a)
+			}
+			break
+		}
+	}
+	return annotations, sc.Err()
+}
+
+// annotations returns the annotations of the failed build's check run, if checkRunAnnotations is enabled. Failures to
+// read the build's log are logged, and the check run is sent without annotations.
+func (g *githubissuesNotifier) annotations(ctx context.Context, build *cbpb.Build) []checkRunAnnotation {
+	if g.openLog == nil || !failed(build.Status) {
+		return nil
+	}
+	r, ok, err := g.openLog(ctx, build)
+	if err != nil {
+		log.Warningf("failed to open the log of Build %q for check run annotations: %v", build.Id, err)
+		return nil
+	}
+	if !ok {
+		log.V(2).Infof("Build %q doesn't log to GCS, sending its check run without annotations", build.Id)
+		return nil
+	}
+	defer r.Close()
+	annotations, err := parseAnnotations(build, r)
+	if err != nil {
+		log.Warningf("failed to read the log of Build %q for check run annotations: %v", build.Id, err)
+	}
+	return annotations
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+const annotatedLog = `Starting Step #0 - "build"
+Step #0 - "build": go build ./...
+Finished Step #0 - "build"
+Starting Step #1 - "test"
+Step #1 - "test": ./pkg/server.go:12:5: undefined: handler
+Step #1 - "test": /workspace/main.go:40: unused variable x
+Step #1 - "test": ./pkg/server.go:12:5: undefined: handler
+Step #1 - "test": ` + "\x1b[31mlint.yaml:3:1: \x1b[1mbad indent\x1b[0m" + `
+Step #1 - "test": FAIL github.com/somename/somerepo/pkg 0.01s
+Starting Step #2
+Step #2: other.go:1:1: not a failed step
+`
+
+func TestParseAnnotations(t *testing.T) {
+	build := &cbpb.Build{Steps: []*cbpb.BuildStep{
+		{Id: "build", Status: cbpb.Build_SUCCESS},
+		{Id: "test", Status: cbpb.Build_FAILURE},
+		{Status: cbpb.Build_CANCELLED},
+	}}
+	got, err := parseAnnotations(build, strings.NewReader(annotatedLog))
+	if err != nil {
+		t.Fatalf("parseAnnotations failed: %v", err)
+	}
+	step := `Step #1 - "test"`
+	want := []checkRunAnnotation{
+		{Path: "pkg/server.go", StartLine: 12, EndLine: 12, StartColumn: 5, EndColumn: 5, AnnotationLevel: "failure", Title: step, Message: "undefined: handler"},
+		{Path: "main.go", StartLine: 40, EndLine: 40, AnnotationLevel: "failure", Title: step, Message: "unused variable x"},
+		{Path: "lint.yaml", StartLine: 3, EndLine: 3, StartColumn: 1, EndColumn: 1, AnnotationLevel: "failure", Title: step, Message: "bad indent"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected annotations (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseAnnotationsLimit(t *testing.T) {
+	build := &cbpb.Build{Steps: []*cbpb.BuildStep{{Status: cbpb.Build_FAILURE}}}
+	var b strings.Builder
+	for i := 1; i <= maxAnnotations+10; i++ {
+		fmt.Fprintf(&b, "Step #0: main.go:%d: error\n", i)
+	}
+	got, err := parseAnnotations(build, strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("parseAnnotations failed: %v", err)
+	}
+	if len(got) != maxAnnotations {
+		t.Errorf("got %d annotations, want %d", len(got), maxAnnotations)
+	}
+}
+
+func TestSendCheckRunAnnotations(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/check-runs"
+	for _, tc := range []struct {
+		name    string
+		status  cbpb.Build_Status
+		openLog func(context.Context, *cbpb.Build) (io.ReadCloser, bool, error)
+		want    int
+	}{{
+		name:   "failed build",
+		status: cbpb.Build_FAILURE,
+		openLog: func(context.Context, *cbpb.Build) (io.ReadCloser, bool, error) {
+			return ioutil.NopCloser(strings.NewReader(annotatedLog)), true, nil
+		},
+		want: 3,
+	}, {
+		name:   "successful build",
+		status: cbpb.Build_SUCCESS,
+		openLog: func(context.Context, *cbpb.Build) (io.ReadCloser, bool, error) {
+			t.Error("opened the log of a successful build")
+			return nil, false, nil
+		},
+	}, {
+		name:   "unreadable log",
+		status: cbpb.Build_FAILURE,
+		openLog: func(context.Context, *cbpb.Build) (io.ReadCloser, bool, error) {
+			return nil, false, errors.New("permission denied")
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{create: {http.StatusCreated, `{"id": 42}`}}}
+			n := newTestNotifier(t, map[string]interface{}{"target": "checkRun"}, issuePayload, fg)
+			n.openLog = tc.openLog
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        tc.status,
+				Steps:         []*cbpb.BuildStep{{Id: "build"}, {Id: "test", Status: cbpb.Build_FAILURE}},
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "COMMIT_SHA": "abc123"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			output, _ := fg.bodies[create]["output"].(map[string]interface{})
+			annotations, _ := output["annotations"].([]interface{})
+			if len(annotations) != tc.want {
+				t.Errorf("got %d annotations, want %d: %v", len(annotations), tc.want, annotations)
+			}
+		})
+	}
+}
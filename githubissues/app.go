@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+const (
+	// appJWTLifetime is how long the JWTs that authenticate as the GitHub App are valid. GitHub allows at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appTokenRefreshMargin is how long before it expires an installation token is replaced, so requests don't race its
+	// expiry.
+	appTokenRefreshMargin = 5 * time.Minute
+)
+
+// appTokenSource mints, caches, and refreshes the installation tokens of a GitHub App installation.
+// See https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/authenticating-as-a-github-app-installation.
+type appTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	client         *http.Client
+	// apiURL is the base URL of the GitHub REST API that installation tokens are requested from.
+	apiURL string
+	// now returns the current time. It defaults to time.Now if nil.
+	now func() time.Time
+
+	mu      sync.Mutex
+	tok     string
+	expires time.Time
+}
+
+// parseGitHubApp parses the `githubApp` delivery config field, a map of the App's `appId`, the `installationId` of its
+// installation on the notified repos, and a `privateKey: {secretRef: <name>}` referencing its PEM-encoded private key,
+// which it fetches.
+func parseGitHubApp(ctx context.Context, cfg *notifiers.Config, sg notifiers.SecretGetter, client *http.Client, apiURL string) (*appTokenSource, error) {
+	v := cfg.Spec.Notification.Delivery[githubAppField]
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected delivery config field %q to be a map of `appId`, `installationId`, and `privateKey`, got %v", githubAppField, v)
+	}
+	id := func(name string) (int64, error) {
+		var n int64
+		switch v := m[name].(type) {
+		case int:
+			n = int64(v)
+		case string:
+			n, _ = strconv.ParseInt(v, 10, 64)
+		}
+		if n <= 0 {
+			return 0, fmt.Errorf("expected delivery config field %q to have a positive integer %q, got %v", githubAppField, name, m[name])
+		}
+		return n, nil
+	}
+	appID, err := id("appId")
+	if err != nil {
+		return nil, err
+	}
+	installationID, err := id("installationId")
+	if err != nil {
+		return nil, err
+	}
+	pk, _ := m["privateKey"].(map[interface{}]interface{})
+	ref, _ := pk["secretRef"].(string)
+	if ref == "" {
+		return nil, fmt.Errorf("expected delivery config field %q to have a `privateKey: {secretRef: <name>}` map, got %v", githubAppField, m["privateKey"])
+	}
+	resource, err := notifiers.FindSecretResourceName(cfg.Spec.Secrets, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Secret for the GitHub App private key (ref %q): %w", ref, err)
+	}
+	if notifiers.Validating(ctx) {
+		// The secret is a placeholder, so there's no key to parse.
+		return &appTokenSource{appID: appID, installationID: installationID, client: client, apiURL: apiURL}, nil
+	}
+	pemKey, err := sg.GetSecret(ctx, resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the GitHub App private key: %w", err)
+	}
+	key, err := parsePrivateKey([]byte(pemKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the GitHub App private key: %w", err)
+	}
+	return &appTokenSource{appID: appID, installationID: installationID, key: key, client: client, apiURL: apiURL}, nil
+}
+
+// parsePrivateKey parses a PEM-encoded RSA private key in PKCS #1 form, as GitHub generates them, or PKCS #8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA private key, got %T", k)
+	}
+	return key, nil
+}
+
+func (a *appTokenSource) clock() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
+
+// jwt returns a JWT authenticating as the App, signed with its private key.
+func (a *appTokenSource) jwt() (string, error) {
+	now := a.clock()
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		// Backdated to allow for clock drift, as GitHub recommends.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(a.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// token returns the cached installation token, minting a new one if it's missing or about to expire.
+func (a *appTokenSource) token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.tok != "" && a.clock().Before(a.expires.Add(-appTokenRefreshMargin)) {
+		return a.tok, nil
+	}
+	jwt, err := a.jwt()
+	if err != nil {
+		return "", err
+	}
+	u := fmt.Sprintf("%s/app/installations/%d/access_tokens", a.apiURL, a.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create a new HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("User-Agent", "GCB-Notifier/0.1 (http)")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", &statusError{method: http.MethodPost, url: u, code: resp.StatusCode, status: resp.Status}
+	}
+	var it struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&it); err != nil || it.Token == "" {
+		return "", fmt.Errorf("failed to decode installation token from %q: %v", u, err)
+	}
+	a.tok, a.expires = it.Token, it.ExpiresAt
+	log.V(2).Infof("minted an installation token of GitHub App %d, expiring at %v", a.appID, it.ExpiresAt)
+	return a.tok, nil
+}
+
+// current returns the cached installation token, or "" if there is none.
+func (a *appTokenSource) current() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.tok
+}
+
+// invalidate drops the cached installation token if it's the given one, e.g. because GitHub rejected it, so the next
+// request mints a new one.
+func (a *appTokenSource) invalidate(tok string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.tok == tok {
+		a.tok = ""
+	}
+}
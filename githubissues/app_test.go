@@ -0,0 +1,278 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+const accessTokens = "POST /app/installations/67890/access_tokens"
+
+// mapSecretGetter returns the secrets in the map by resource name.
+type mapSecretGetter map[string]string
+
+func (m mapSecretGetter) GetSecret(_ context.Context, name string) (string, error) {
+	s, ok := m[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return s, nil
+}
+
+// authRecorder records the Authorization header of each call before passing it on.
+type authRecorder struct {
+	h    http.Handler
+	mu   sync.Mutex
+	auth map[string][]string
+}
+
+func (a *authRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	call := r.Method + " " + r.URL.Path
+	a.auth[call] = append(a.auth[call], r.Header.Get("Authorization"))
+	a.mu.Unlock()
+	a.h.ServeHTTP(w, r)
+}
+
+func newAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func appConfig(app interface{}, extra map[string]interface{}) *notifiers.Config {
+	d := map[string]interface{}{"githubApp": app, "githubRepo": "somename/somerepo"}
+	for k, v := range extra {
+		d[k] = v
+	}
+	return &notifiers.Config{Spec: &notifiers.Spec{
+		Notification: &notifiers.Notification{Filter: `build.status == Build.Status.FAILURE`, Delivery: d},
+		Secrets:      []*notifiers.Secret{{LocalName: "app-key", ResourceName: "projects/p/secrets/app-key/versions/1"}},
+	}}
+}
+
+var testApp = map[interface{}]interface{}{
+	"appId":          12345,
+	"installationId": "67890",
+	"privateKey":     map[interface{}]interface{}{"secretRef": "app-key"},
+}
+
+// verifyAppJWT checks that the JWT was signed with the key and issued by App 12345.
+func verifyAppJWT(t *testing.T, key *rsa.PrivateKey, jwt string) {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("got malformed JWT %q", jwt)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+		t.Errorf("JWT signature doesn't verify: %v", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Iss != "12345" || claims.Exp-claims.Iat > int64((10*time.Minute).Seconds()) {
+		t.Errorf("got JWT claims %+v, want issuer 12345 and a lifetime of at most 10m", claims)
+	}
+}
+
+func TestGitHubAppAuth(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	key, pemKey := newAppKey(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{
+		accessTokens: {http.StatusCreated, `{"token": "ghs_installation1", "expires_at": "2026-01-01T13:00:00Z"}`},
+	}}
+	ar := &authRecorder{h: fg, auth: map[string][]string{}}
+	srv := httptest.NewServer(ar)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &githubissuesNotifier{httpClient: &http.Client{Transport: &rewriteTransport{target: u}}}
+	sg := mapSecretGetter{"projects/p/secrets/app-key/versions/1": pemKey}
+	if err := n.SetUp(context.Background(), appConfig(testApp, nil), issuePayload, sg, new(fakeBindingResolver)); err != nil {
+		t.Fatalf("SetUp failed: %v", err)
+	}
+	n.app.now = func() time.Time { return now }
+
+	send := func() {
+		t.Helper()
+		build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"}}
+		if err := n.SendNotification(context.Background(), build); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+	}
+
+	// The first request mints a token, which later ones reuse until it's about to expire.
+	send()
+	now = now.Add(54 * time.Minute)
+	send()
+	fg.mu.Lock()
+	fg.responses[accessTokens] = fakeResponse{http.StatusCreated, `{"token": "ghs_installation2", "expires_at": "2026-01-01T14:00:00Z"}`}
+	fg.mu.Unlock()
+	now = now.Add(time.Minute)
+	send()
+
+	if diff := cmp.Diff([]string{accessTokens, create, create, accessTokens, create}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+	want := []string{"token ghs_installation1", "token ghs_installation1", "token ghs_installation2"}
+	if diff := cmp.Diff(want, ar.auth[create]); diff != "" {
+		t.Errorf("issues created with unexpected Authorization (-want +got):\n%s", diff)
+	}
+	for _, authz := range ar.auth[accessTokens] {
+		if !strings.HasPrefix(authz, "Bearer ") {
+			t.Fatalf("token minted with Authorization %q, want a bearer JWT", authz)
+		}
+		verifyAppJWT(t, key, strings.TrimPrefix(authz, "Bearer "))
+	}
+}
+
+func TestGitHubAppTokenInvalidatedOnUnauthorized(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	_, pemKey := newAppKey(t)
+	fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{
+		accessTokens: {http.StatusCreated, `{"token": "ghs_installation", "expires_at": "2099-01-01T00:00:00Z"}`},
+		create:       {http.StatusUnauthorized, `{}`},
+	}}
+	srv := httptest.NewServer(fg)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &githubissuesNotifier{httpClient: &http.Client{Transport: &rewriteTransport{target: u}}}
+	sg := mapSecretGetter{"projects/p/secrets/app-key/versions/1": pemKey}
+	if err := n.SetUp(context.Background(), appConfig(testApp, map[string]interface{}{"maxAttempts": 1}), issuePayload, sg, new(fakeBindingResolver)); err != nil {
+		t.Fatalf("SetUp failed: %v", err)
+	}
+	build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"}}
+	for i := 0; i < 2; i++ {
+		// The rejected create is returned, so the event is redelivered.
+		if err := n.SendNotification(context.Background(), build); err == nil || permanent(err) {
+			t.Fatalf("SendNotification got error %v, want a retryable error", err)
+		}
+	}
+	if diff := cmp.Diff([]string{accessTokens, create, accessTokens, create}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitHubAppConfigErrors(t *testing.T) {
+	key, pemKey := newAppKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sg := mapSecretGetter{
+		"projects/p/secrets/app-key/versions/1": pemKey,
+		"projects/p/secrets/pkcs8/versions/1":   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+		"projects/p/secrets/garbage/versions/1": "not a key",
+	}
+	withKey := func(ref string) map[interface{}]interface{} {
+		return map[interface{}]interface{}{"appId": 12345, "installationId": 67890, "privateKey": map[interface{}]interface{}{"secretRef": ref}}
+	}
+	for _, tc := range []struct {
+		name    string
+		cfg     *notifiers.Config
+		wantErr bool
+	}{
+		{name: "valid", cfg: appConfig(testApp, nil)},
+		{name: "PKCS #8 key", cfg: withSecrets(appConfig(withKey("pkcs8"), nil), "pkcs8")},
+		{name: "not a map", cfg: appConfig("12345", nil), wantErr: true},
+		{name: "missing appId", cfg: appConfig(map[interface{}]interface{}{"installationId": 67890, "privateKey": testApp["privateKey"]}, nil), wantErr: true},
+		{name: "non-numeric installationId", cfg: appConfig(map[interface{}]interface{}{"appId": 12345, "installationId": "abc", "privateKey": testApp["privateKey"]}, nil), wantErr: true},
+		{name: "missing privateKey", cfg: appConfig(map[interface{}]interface{}{"appId": 12345, "installationId": 67890}, nil), wantErr: true},
+		{name: "unknown secretRef", cfg: appConfig(withKey("unknown"), nil), wantErr: true},
+		{name: "malformed key", cfg: withSecrets(appConfig(withKey("garbage"), nil), "garbage"), wantErr: true},
+		{name: "with githubToken", cfg: appConfig(testApp, map[string]interface{}{"githubToken": map[interface{}]interface{}{"secretRef": "app-key"}}), wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := new(githubissuesNotifier).SetUp(context.Background(), tc.cfg, issuePayload, sg, new(fakeBindingResolver))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("SetUp got error %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitHubAppConfigValidation(t *testing.T) {
+	cfg := appConfig(testApp, map[string]interface{}{
+		"stateStore":          "gs://some-bucket/state",
+		"logTailLines":        20,
+		"target":              "checkRun",
+		"checkRunAnnotations": true,
+		"artifactUrlTtl":      "1h",
+	})
+	// The --validate mode gets placeholder secret values like this one.
+	sg := mapSecretGetter{"projects/p/secrets/app-key/versions/1": "[SECRET VALUE FOR \"projects/p/secrets/app-key/versions/1\"]"}
+	ctx := notifiers.WithValidation(context.Background())
+	if err := new(githubissuesNotifier).SetUp(ctx, cfg, issuePayload, sg, new(fakeBindingResolver)); err != nil {
+		t.Errorf("SetUp failed when validating: %v", err)
+	}
+	if err := new(githubissuesNotifier).SetUp(ctx, appConfig(map[interface{}]interface{}{"appId": 12345, "privateKey": testApp["privateKey"]}, nil), issuePayload, sg, new(fakeBindingResolver)); err == nil {
+		t.Error("SetUp got no error when validating a config without installationId")
+	}
+	if err := new(githubissuesNotifier).SetUp(context.Background(), appConfig(testApp, nil), issuePayload, sg, new(fakeBindingResolver)); err == nil {
+		t.Error("SetUp got no error for a placeholder key when not validating")
+	}
+}
+
+// withSecrets adds Secrets named like their refs to the config.
+func withSecrets(cfg *notifiers.Config, refs ...string) *notifiers.Config {
+	for _, ref := range refs {
+		cfg.Spec.Secrets = append(cfg.Spec.Secrets, &notifiers.Secret{LocalName: ref, ResourceName: "projects/p/secrets/" + ref + "/versions/1"})
+	}
+	return cfg
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+// autoClosePolicy is the autoClose delivery config field: which created issues are closed right away, and after how
+// long. Settings it leaves unset fall back to the repo's DISABLE_AUTO_CLOSE__ and AUTO_CLOSE_DELAY__ environment
+// variables and to closing the issues of successful builds. A nil policy leaves everything to the fallbacks.
+type autoClosePolicy struct {
+	// enabled, statuses, and delay are nil if not configured.
+	enabled  *bool
+	statuses map[cbpb.Build_Status]bool
+	delay    *time.Duration
+	// repos override the policy for each `owner/repo`.
+	repos map[string]*autoClosePolicy
+}
+
+// parseAutoClosePolicy parses the autoClose delivery config field: a map of `enabled`, `onlyForStatuses`,
+// `closeDelaySeconds`, and per-repo overrides of them in `repos`.
+func parseAutoClosePolicy(v interface{}) (*autoClosePolicy, error) {
+	p, err := parseAutoCloseSettings(autoCloseField, v)
+	if err != nil {
+		return nil, err
+	}
+	m := v.(map[interface{}]interface{})
+	r, ok := m["repos"]
+	if !ok {
+		return p, nil
+	}
+	repos, ok := r.(map[interface{}]interface{})
+	if !ok || len(repos) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty map of repos to auto-close settings, got %v", autoCloseField+".repos", r)
+	}
+	p.repos = map[string]*autoClosePolicy{}
+	for k, s := range repos {
+		repo, _ := k.(string)
+		if repo == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to have `owner/repo` keys, got %v", autoCloseField+".repos", k)
+		}
+		field := autoCloseField + ".repos." + repo
+		if sm, ok := s.(map[interface{}]interface{}); ok {
+			if _, ok := sm["repos"]; ok {
+				return nil, fmt.Errorf("delivery config field %q can't have its own %q", field, "repos")
+			}
+		}
+		if p.repos[repo], err = parseAutoCloseSettings(field, s); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// parseAutoCloseSettings parses the `enabled`, `onlyForStatuses`, and `closeDelaySeconds` of the auto-close settings
+// in the named delivery config field.
+func parseAutoCloseSettings(field string, v interface{}) (*autoClosePolicy, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected delivery config field %q to be a map of auto-close settings, got %v", field, v)
+	}
+	p := &autoClosePolicy{}
+	for k, s := range m {
+		switch k {
+		case "enabled":
+			b, ok := s.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected delivery config field %q to be a boolean, got %v", field+".enabled", s)
+			}
+			p.enabled = &b
+		case "onlyForStatuses":
+			l, ok := s.([]interface{})
+			if !ok || len(l) == 0 {
+				return nil, fmt.Errorf("expected delivery config field %q to be a non-empty list of build statuses, got %v", field+".onlyForStatuses", s)
+			}
+			p.statuses = map[cbpb.Build_Status]bool{}
+			for _, e := range l {
+				name, _ := e.(string)
+				status, ok := cbpb.Build_Status_value[name]
+				if !ok {
+					return nil, fmt.Errorf("expected delivery config field %q to list build statuses like %q, got %v", field+".onlyForStatuses", "SUCCESS", e)
+				}
+				p.statuses[cbpb.Build_Status(status)] = true
+			}
+		case "closeDelaySeconds":
+			secs, ok := s.(int)
+			if !ok || secs < 0 {
+				return nil, fmt.Errorf("expected delivery config field %q to be a non-negative number of seconds, got %v", field+".closeDelaySeconds", s)
+			}
+			d := time.Duration(secs) * time.Second
+			p.delay = &d
+		case "repos":
+			// Parsed by parseAutoClosePolicy.
+		default:
+			return nil, fmt.Errorf("unknown delivery config field %q, expected one of %q, %q, or %q", fmt.Sprintf("%s.%v", field, k), "enabled", "onlyForStatuses", "closeDelaySeconds")
+		}
+	}
+	return p, nil
+}
+
+// forRepo returns the repo's override of the policy, or nil if it has none.
+func (p *autoClosePolicy) forRepo(repo string) *autoClosePolicy {
+	if p == nil {
+		return nil
+	}
+	return p.repos[repo]
+}
+
+// closes returns true iff issues created for builds with the status in the repo are auto-closed: per the repo's
+// onlyForStatuses, else the policy's, else only those of successful builds.
+func (p *autoClosePolicy) closes(repo string, status cbpb.Build_Status) bool {
+	for _, q := range []*autoClosePolicy{p.forRepo(repo), p} {
+		if q != nil && q.statuses != nil {
+			return q.statuses[status]
+		}
+	}
+	return status == cbpb.Build_SUCCESS
+}
+
+// disabled returns true iff auto-close is disabled for the repo: per the repo's enabled setting, else the policy's,
+// else the repo's DISABLE_AUTO_CLOSE__ environment variable.
+func (p *autoClosePolicy) disabled(repo string) bool {
+	for _, q := range []*autoClosePolicy{p.forRepo(repo), p} {
+		if q != nil && q.enabled != nil {
+			return !*q.enabled
+		}
+	}
+	return autoCloseDisabled(repo)
+}
+
+// closeDelay returns how long to wait before auto-closing an issue in the repo: per the repo's closeDelaySeconds, else
+// the policy's, else the repo's AUTO_CLOSE_DELAY__ environment variable.
+func (p *autoClosePolicy) closeDelay(repo string) time.Duration {
+	for _, q := range []*autoClosePolicy{p.forRepo(repo), p} {
+		if q != nil && q.delay != nil {
+			return *q.delay
+		}
+	}
+	return autoCloseDelay(repo)
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAutoClosePolicy(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/issues"
+		close  = "PATCH /repos/somename/somerepo/issues/7"
+	)
+	for _, tc := range []struct {
+		name       string
+		autoClose  map[interface{}]interface{}
+		status     cbpb.Build_Status
+		envDelay   string
+		disabled   string
+		wantDelays []time.Duration
+		wantCalls  []string
+	}{{
+		name:      "disabled",
+		autoClose: map[interface{}]interface{}{"enabled": false},
+		status:    cbpb.Build_SUCCESS,
+		wantCalls: []string{create},
+	}, {
+		name:      "enabled overrides the env var",
+		autoClose: map[interface{}]interface{}{"enabled": true},
+		status:    cbpb.Build_SUCCESS,
+		disabled:  "true",
+		wantCalls: []string{create, close},
+	}, {
+		name:      "env var applies when enabled isn't set",
+		autoClose: map[interface{}]interface{}{"closeDelaySeconds": 10},
+		status:    cbpb.Build_SUCCESS,
+		disabled:  "true",
+		wantCalls: []string{create},
+	}, {
+		name:       "delay overrides the env var",
+		autoClose:  map[interface{}]interface{}{"closeDelaySeconds": 10},
+		status:     cbpb.Build_SUCCESS,
+		envDelay:   "30",
+		wantDelays: []time.Duration{10 * time.Second},
+		wantCalls:  []string{create, close},
+	}, {
+		name:       "env delay applies when closeDelaySeconds isn't set",
+		autoClose:  map[interface{}]interface{}{"enabled": true},
+		status:     cbpb.Build_SUCCESS,
+		envDelay:   "30",
+		wantDelays: []time.Duration{30 * time.Second},
+		wantCalls:  []string{create, close},
+	}, {
+		name: "repo override",
+		autoClose: map[interface{}]interface{}{
+			"enabled": false,
+			"repos": map[interface{}]interface{}{
+				"somename/somerepo": map[interface{}]interface{}{"enabled": true, "closeDelaySeconds": 5},
+				"somename/other":    map[interface{}]interface{}{"enabled": false},
+			},
+		},
+		status:     cbpb.Build_SUCCESS,
+		wantDelays: []time.Duration{5 * time.Second},
+		wantCalls:  []string{create, close},
+	}, {
+		name:      "other repo's override doesn't apply",
+		autoClose: map[interface{}]interface{}{"repos": map[interface{}]interface{}{"somename/other": map[interface{}]interface{}{"enabled": false}}},
+		status:    cbpb.Build_SUCCESS,
+		wantCalls: []string{create, close},
+	}, {
+		name:      "status not in onlyForStatuses",
+		autoClose: map[interface{}]interface{}{"onlyForStatuses": []interface{}{"FAILURE"}},
+		status:    cbpb.Build_SUCCESS,
+		wantCalls: []string{create},
+	}, {
+		name:      "status in onlyForStatuses",
+		autoClose: map[interface{}]interface{}{"onlyForStatuses": []interface{}{"SUCCESS", "FAILURE"}},
+		status:    cbpb.Build_FAILURE,
+		wantCalls: []string{create, close},
+	}, {
+		name:      "failures aren't closed by default",
+		autoClose: map[interface{}]interface{}{"enabled": true},
+		status:    cbpb.Build_FAILURE,
+		wantCalls: []string{create},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("AUTO_CLOSE_DELAY__SOMENAME_SOMEREPO", tc.envDelay)
+			t.Setenv("DISABLE_AUTO_CLOSE__SOMENAME_SOMEREPO", tc.disabled)
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"autoClose": tc.autoClose}, issuePayload, fg)
+			var delays []time.Duration
+			n.sleep = func(_ context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        tc.status,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantDelays, delays); diff != "" {
+				t.Errorf("unexpected delays (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseAutoClosePolicyErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "not a map", v: true},
+		{name: "enabled not a boolean", v: map[interface{}]interface{}{"enabled": "yes"}},
+		{name: "empty statuses", v: map[interface{}]interface{}{"onlyForStatuses": []interface{}{}}},
+		{name: "unknown status", v: map[interface{}]interface{}{"onlyForStatuses": []interface{}{"BROKEN"}}},
+		{name: "negative delay", v: map[interface{}]interface{}{"closeDelaySeconds": -1}},
+		{name: "delay not seconds", v: map[interface{}]interface{}{"closeDelaySeconds": "30s"}},
+		{name: "unknown setting", v: map[interface{}]interface{}{"enable": true}},
+		{name: "repos not a map", v: map[interface{}]interface{}{"repos": []interface{}{"somename/somerepo"}}},
+		{name: "bad repo settings", v: map[interface{}]interface{}{"repos": map[interface{}]interface{}{"somename/somerepo": map[interface{}]interface{}{"enabled": 1}}}},
+		{
+			name: "nested repos",
+			v: map[interface{}]interface{}{"repos": map[interface{}]interface{}{
+				"somename/somerepo": map[interface{}]interface{}{"repos": map[interface{}]interface{}{}},
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseAutoClosePolicy(tc.v); err == nil {
+				t.Errorf("parseAutoClosePolicy(%v) succeeded, want an error", tc.v)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	log "github.com/golang/glog"
+)
+
+// parseBranches parses the `branches` delivery config field, a non-empty list of glob patterns as understood by
+// path.Match.
+func parseBranches(delivery map[string]interface{}) ([]string, error) {
+	patterns, err := getStringListField(delivery, branchesField)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty list of branch patterns", branchesField)
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to contain glob patterns like %q, got %q", branchesField, "release/*", p)
+		}
+	}
+	return patterns, nil
+}
+
+// branchAllowed returns true iff the build's BRANCH_NAME matches one of the configured branch patterns. Builds without
+// a branch, such as tag builds, never match.
+func (g *githubissuesNotifier) branchAllowed(build *cbpb.Build) bool {
+	branch := build.Substitutions["BRANCH_NAME"]
+	if branch == "" {
+		if tag := build.Substitutions["TAG_NAME"]; tag != "" {
+			log.V(2).Infof("not sending response for event (build id = %s, status = %v): tag %q builds have no branch to match %q", build.Id, build.Status, tag, branchesField)
+		} else {
+			log.V(2).Infof("not sending response for event (build id = %s, status = %v): no BRANCH_NAME to match %q", build.Id, build.Status, branchesField)
+		}
+		return false
+	}
+	for _, p := range g.branches {
+		if ok, _ := path.Match(p, branch); ok {
+			return true
+		}
+	}
+	log.V(2).Infof("not sending response for event (build id = %s, status = %v): branch %q matches none of %q", build.Id, build.Status, branch, g.branches)
+	return false
+}
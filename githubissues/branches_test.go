@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+func TestBranches(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	for _, tc := range []struct {
+		name          string
+		status        cbpb.Build_Status
+		substitutions map[string]string
+		wantCreate    bool
+	}{{
+		name:          "exact match",
+		status:        cbpb.Build_FAILURE,
+		substitutions: map[string]string{"BRANCH_NAME": "main"},
+		wantCreate:    true,
+	}, {
+		name:          "glob match",
+		status:        cbpb.Build_FAILURE,
+		substitutions: map[string]string{"BRANCH_NAME": "release/1.2"},
+		wantCreate:    true,
+	}, {
+		name:          "glob doesn't span slashes",
+		status:        cbpb.Build_FAILURE,
+		substitutions: map[string]string{"BRANCH_NAME": "release/1.2/hotfix"},
+	}, {
+		name:          "no match",
+		status:        cbpb.Build_FAILURE,
+		substitutions: map[string]string{"BRANCH_NAME": "feature/x"},
+	}, {
+		name:          "tag build",
+		status:        cbpb.Build_FAILURE,
+		substitutions: map[string]string{"TAG_NAME": "v1.2.0"},
+	}, {
+		name:          "no branch",
+		status:        cbpb.Build_FAILURE,
+		substitutions: map[string]string{},
+	}, {
+		name:          "matching branch still needs the CEL filter",
+		status:        cbpb.Build_WORKING,
+		substitutions: map[string]string{"BRANCH_NAME": "main"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"branches": []interface{}{"main", "release/*"}}, issuePayload, fg)
+			tc.substitutions["REPO_FULL_NAME"] = "somename/somerepo"
+			build := &cbpb.Build{Id: "some-build-id", Status: tc.status, Substitutions: tc.substitutions}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if _, created := fg.bodies[create]; created != tc.wantCreate {
+				t.Errorf("created an issue: %t, want %t", created, tc.wantCreate)
+			}
+		})
+	}
+}
+
+func TestParseBranches(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		wantErr bool
+	}{
+		{name: "valid", v: []interface{}{"main", "release/*"}},
+		{name: "not a list", v: "main", wantErr: true},
+		{name: "empty", v: []interface{}{}, wantErr: true},
+		{name: "empty pattern", v: []interface{}{""}, wantErr: true},
+		{name: "bad pattern", v: []interface{}{"release/["}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseBranches(map[string]interface{}{branchesField: tc.v}); (err != nil) != tc.wantErr {
+				t.Errorf("parseBranches(%v) = %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+const defaultCheckRunName = "Cloud Build"
+
+// checkRun is the subset of a GitHub check run resource that the notifier sends and uses.
+// See https://docs.github.com/en/rest/checks/runs#create-a-check-run.
+type checkRun struct {
+	ID         int64           `json:"id,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	HeadSHA    string          `json:"head_sha,omitempty"`
+	Status     string          `json:"status,omitempty"`
+	Conclusion string          `json:"conclusion,omitempty"`
+	DetailsURL string          `json:"details_url,omitempty"`
+	ExternalID string          `json:"external_id,omitempty"`
+	Output     *checkRunOutput `json:"output,omitempty"`
+}
+
+type checkRunOutput struct {
+	Title       string               `json:"title"`
+	Summary     string               `json:"summary"`
+	Annotations []checkRunAnnotation `json:"annotations,omitempty"`
+}
+
+// checkRunStatus maps a Build status to a check run status and, for completed builds, conclusion.
+func checkRunStatus(s cbpb.Build_Status) (status, conclusion string) {
+	switch s {
+	case cbpb.Build_WORKING:
+		return "in_progress", ""
+	case cbpb.Build_SUCCESS:
+		return "completed", "success"
+	case cbpb.Build_FAILURE, cbpb.Build_INTERNAL_ERROR:
+		return "completed", "failure"
+	case cbpb.Build_TIMEOUT:
+		return "completed", "timed_out"
+	case cbpb.Build_CANCELLED, cbpb.Build_EXPIRED:
+		return "completed", "cancelled"
+	default:
+		// STATUS_UNKNOWN, PENDING, and QUEUED.
+		return "queued", ""
+	}
+}
+
+// renderedIssue is the issue template's output, which also provides the title and text of other targets.
+type renderedIssue struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// checkRunPayload returns the check run for the build, titled and summarized from the rendered issue template output.
+func (g *githubissuesNotifier) checkRunPayload(build *cbpb.Build, sha string, rendered []byte) (*checkRun, error) {
+	var ri renderedIssue
+	if err := json.Unmarshal(rendered, &ri); err != nil {
+		return nil, fmt.Errorf("failed to decode rendered template as an issue title and body: %w", err)
+	}
+	status, conclusion := checkRunStatus(build.Status)
+	return &checkRun{
+		Name:       g.checkRunName,
+		HeadSHA:    sha,
+		Status:     status,
+		Conclusion: conclusion,
+		DetailsURL: build.LogUrl,
+		ExternalID: build.Id,
+		Output:     &checkRunOutput{Title: ri.Title, Summary: ri.Body},
+	}, nil
+}
+
+// sendCheckRun creates a check run on the build's COMMIT_SHA, or updates the one it created for an earlier event of the
+// same build. Creating check runs requires a GitHub App installation token.
+func (g *githubissuesNotifier) sendCheckRun(ctx context.Context, build *cbpb.Build, repo string, rendered []byte) error {
+	sha := build.Substitutions["COMMIT_SHA"]
+	if sha == "" {
+		log.Warningf("Build %q has no COMMIT_SHA, skipping check run", build.Id)
+		notifiers.RecordFiltered(notifiers.FilterReasonNoRef)
+		return nil
+	}
+	cr, err := g.checkRunPayload(build, sha, rendered)
+	if err != nil {
+		return err
+	}
+	cr.Output.Annotations = g.annotations(ctx, build)
+	body, err := json.Marshal(cr)
+	if err != nil {
+		return fmt.Errorf("failed to encode check run: %w", err)
+	}
+	g.logPayload(build, "check run", body)
+
+	if id, ok := g.checkRuns.get(ctx, repo+"@"+build.Id); ok {
+		url := fmt.Sprintf("%s/check-runs/%d", g.repoURL(repo), id)
+		if err := g.doRequest(ctx, http.MethodPatch, url, body, nil); err != nil {
+			return fmt.Errorf("failed to update check run %d: %w%s", id, err, g.errorPayload("check run", body))
+		}
+		log.V(2).Infof("updated check run %d in %q for Build %q", id, repo, build.Id)
+		return nil
+	}
+
+	created := new(checkRun)
+	if err := g.doRequest(ctx, http.MethodPost, fmt.Sprintf("%s/check-runs", g.repoURL(repo)), body, created); err != nil {
+		return fmt.Errorf("failed to create check run: %w%s", err, g.errorPayload("check run", body))
+	}
+	g.checkRuns.put(ctx, repo+"@"+build.Id, created.ID)
+	log.V(2).Infof("created check run %d in %q for Build %q", created.ID, repo, build.Id)
+	return nil
+}
+
+// idCache remembers, in its state store, the ID of the GitHub resource created for each key, e.g. the check run created
+// for each repo and build ID, so later events update it. Entries are kept for statusRetention. Store failures are
+// logged and treated as misses, so at worst a duplicate resource is created.
+type idCache struct {
+	store notifiers.StateStore
+	// prefix namespaces the cache's keys in the store.
+	prefix string
+}
+
+func (c *idCache) get(ctx context.Context, key string) (int64, bool) {
+	v, ok, err := c.store.Get(ctx, c.prefix+key)
+	if err != nil {
+		log.Warningf("failed to look up %s%s: %v", c.prefix, key, err)
+		return 0, false
+	}
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Warningf("ignoring malformed ID %q stored for %s%s", v, c.prefix, key)
+		return 0, false
+	}
+	return id, true
+}
+
+func (c *idCache) put(ctx context.Context, key string, id int64) {
+	if err := c.store.Put(ctx, c.prefix+key, strconv.FormatInt(id, 10), statusRetention); err != nil {
+		log.Warningf("failed to record %s%s: %v", c.prefix, key, err)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckRunStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status         cbpb.Build_Status
+		wantStatus     string
+		wantConclusion string
+	}{
+		{cbpb.Build_STATUS_UNKNOWN, "queued", ""},
+		{cbpb.Build_PENDING, "queued", ""},
+		{cbpb.Build_QUEUED, "queued", ""},
+		{cbpb.Build_WORKING, "in_progress", ""},
+		{cbpb.Build_SUCCESS, "completed", "success"},
+		{cbpb.Build_FAILURE, "completed", "failure"},
+		{cbpb.Build_INTERNAL_ERROR, "completed", "failure"},
+		{cbpb.Build_TIMEOUT, "completed", "timed_out"},
+		{cbpb.Build_CANCELLED, "completed", "cancelled"},
+		{cbpb.Build_EXPIRED, "completed", "cancelled"},
+	} {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			status, conclusion := checkRunStatus(tc.status)
+			if status != tc.wantStatus || conclusion != tc.wantConclusion {
+				t.Errorf("checkRunStatus(%v) = (%q, %q), want (%q, %q)", tc.status, status, conclusion, tc.wantStatus, tc.wantConclusion)
+			}
+		})
+	}
+}
+
+func TestSendCheckRun(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/check-runs"
+		update = "PATCH /repos/somename/somerepo/check-runs/42"
+	)
+	fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{
+		create: {http.StatusCreated, `{"id": 42}`},
+		update: {http.StatusOK, `{"id": 42}`},
+	}}
+	n := newTestNotifier(t, map[string]interface{}{"target": "checkRun", "checkRunName": "CI"}, issuePayload, fg)
+
+	build := &cbpb.Build{
+		ProjectId:     "my-project-id",
+		Id:            "some-build-id",
+		Status:        cbpb.Build_WORKING,
+		LogUrl:        "https://some.example.com/log/url",
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "COMMIT_SHA": "abc123"},
+	}
+	// The test notifier's filter drops WORKING builds, so send the first event directly.
+	if err := n.sendCheckRun(context.Background(), build, "somename/somerepo", []byte(`{"title": "started", "body": "working"}`)); err != nil {
+		t.Fatalf("sendCheckRun failed: %v", err)
+	}
+	build.Status = cbpb.Build_FAILURE
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{create, update}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+
+	got := fg.bodies[update]
+	for k, want := range map[string]interface{}{
+		"name":        "CI",
+		"head_sha":    "abc123",
+		"status":      "completed",
+		"conclusion":  "failure",
+		"external_id": "some-build-id",
+	} {
+		if got[k] != want {
+			t.Errorf("check run field %q = %v, want %v", k, got[k], want)
+		}
+	}
+	output, _ := got["output"].(map[string]interface{})
+	if want := "Cloud Build [my-project-id]: FAILURE"; output["title"] != want {
+		t.Errorf("check run output title = %v, want %q", output["title"], want)
+	}
+	if output["summary"] == "" {
+		t.Error("check run output summary is empty")
+	}
+	if _, ok := fg.bodies[create]["conclusion"]; ok {
+		t.Errorf("in-progress check run has a conclusion: %v", fg.bodies[create])
+	}
+}
+
+func TestSendCheckRunWithoutSHA(t *testing.T) {
+	fg := &fakeGitHub{t: t}
+	n := newTestNotifier(t, map[string]interface{}{"target": "checkRun"}, issuePayload, fg)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if calls := fg.gotCalls(); len(calls) != 0 {
+		t.Errorf("got unexpected GitHub API calls: %v", calls)
+	}
+}
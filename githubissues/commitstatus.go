@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+const (
+	defaultStatusContext = "Cloud Build"
+	// maxStatusDescription is GitHub's limit on the length of a commit status description.
+	maxStatusDescription = 140
+)
+
+// commitStatus is a GitHub commit status.
+// See https://docs.github.com/en/rest/commits/statuses#create-a-commit-status.
+type commitStatus struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// commitStatusState maps a Build status to a commit status state. Builds that Cloud Build failed to run, rather than
+// that failed their steps, are errors.
+func commitStatusState(s cbpb.Build_Status) string {
+	switch s {
+	case cbpb.Build_SUCCESS:
+		return "success"
+	case cbpb.Build_FAILURE, cbpb.Build_TIMEOUT, cbpb.Build_CANCELLED:
+		return "failure"
+	case cbpb.Build_INTERNAL_ERROR, cbpb.Build_EXPIRED:
+		return "error"
+	default:
+		// STATUS_UNKNOWN, PENDING, QUEUED, and WORKING.
+		return "pending"
+	}
+}
+
+// commitStatusPayload returns the commit status for the build, described by the rendered issue template's title.
+func (g *githubissuesNotifier) commitStatusPayload(build *cbpb.Build, rendered []byte) (*commitStatus, error) {
+	var ri renderedIssue
+	if err := json.Unmarshal(rendered, &ri); err != nil {
+		return nil, fmt.Errorf("failed to decode rendered template as an issue title and body: %w", err)
+	}
+	desc := []rune(ri.Title)
+	if len(desc) > maxStatusDescription {
+		desc = append(desc[:maxStatusDescription-1], '…')
+	}
+	return &commitStatus{
+		State:       commitStatusState(build.Status),
+		TargetURL:   build.LogUrl,
+		Description: string(desc),
+		Context:     g.statusContext,
+	}, nil
+}
+
+// sendCommitStatus sets a commit status on the build's COMMIT_SHA. Later events of the same build replace it, as GitHub
+// shows the latest status for each context.
+func (g *githubissuesNotifier) sendCommitStatus(ctx context.Context, build *cbpb.Build, repo string, rendered []byte) error {
+	sha := build.Substitutions["COMMIT_SHA"]
+	if sha == "" {
+		log.Warningf("Build %q has no COMMIT_SHA, skipping commit status", build.Id)
+		notifiers.RecordFiltered(notifiers.FilterReasonNoRef)
+		return nil
+	}
+	cs, err := g.commitStatusPayload(build, rendered)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(cs)
+	if err != nil {
+		return fmt.Errorf("failed to encode commit status: %w", err)
+	}
+	g.logPayload(build, "commit status", body)
+
+	statusURL := fmt.Sprintf("%s/statuses/%s", g.repoURL(repo), url.PathEscape(sha))
+	if err := g.doRequest(ctx, http.MethodPost, statusURL, body, nil); err != nil {
+		return fmt.Errorf("failed to set commit status: %w%s", err, g.errorPayload("commit status", body))
+	}
+	log.V(2).Infof("set commit status %q of %s in %q to %q for Build %q", cs.Context, sha, repo, cs.State, build.Id)
+	return nil
+}
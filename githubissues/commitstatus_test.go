@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommitStatusState(t *testing.T) {
+	for _, tc := range []struct {
+		status cbpb.Build_Status
+		want   string
+	}{
+		{cbpb.Build_STATUS_UNKNOWN, "pending"},
+		{cbpb.Build_PENDING, "pending"},
+		{cbpb.Build_QUEUED, "pending"},
+		{cbpb.Build_WORKING, "pending"},
+		{cbpb.Build_SUCCESS, "success"},
+		{cbpb.Build_FAILURE, "failure"},
+		{cbpb.Build_INTERNAL_ERROR, "error"},
+		{cbpb.Build_TIMEOUT, "failure"},
+		{cbpb.Build_CANCELLED, "failure"},
+		{cbpb.Build_EXPIRED, "error"},
+	} {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			if got := commitStatusState(tc.status); got != tc.want {
+				t.Errorf("commitStatusState(%v) = %q, want %q", tc.status, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSendCommitStatus(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/statuses/abc123"
+	fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{create: {http.StatusCreated, `{"id": 1}`}}}
+	n := newTestNotifier(t, map[string]interface{}{"target": "commitStatus", "statusContext": "ci/cloud-build"}, issuePayload, fg)
+
+	build := &cbpb.Build{
+		ProjectId:     "my-project-id",
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		LogUrl:        "https://some.example.com/log/url",
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "COMMIT_SHA": "abc123"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{create}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+	want := map[string]interface{}{
+		"state":       "failure",
+		"target_url":  "https://some.example.com/log/url?utm_campaign=google-cloud-build-notifiers&utm_medium=http&utm_source=google-cloud-build",
+		"description": "Cloud Build [my-project-id]: FAILURE",
+		"context":     "ci/cloud-build",
+	}
+	if diff := cmp.Diff(want, fg.bodies[create]); diff != "" {
+		t.Errorf("unexpected commit status (-want +got):\n%s", diff)
+	}
+}
+
+func TestCommitStatusPayloadTruncatesDescription(t *testing.T) {
+	n := &githubissuesNotifier{statusContext: defaultStatusContext}
+	title := strings.Repeat("é", 200)
+	cs, err := n.commitStatusPayload(&cbpb.Build{Status: cbpb.Build_SUCCESS}, []byte(`{"title": "`+title+`"}`))
+	if err != nil {
+		t.Fatalf("commitStatusPayload failed: %v", err)
+	}
+	if got := len([]rune(cs.Description)); got != maxStatusDescription {
+		t.Errorf("got a description of %d characters, want %d", got, maxStatusDescription)
+	}
+	if !strings.HasSuffix(cs.Description, "…") {
+		t.Errorf("got description %q, want it to end with an ellipsis", cs.Description)
+	}
+	if cs.State != "success" || cs.Context != defaultStatusContext {
+		t.Errorf("got state %q and context %q, want %q and %q", cs.State, cs.Context, "success", defaultStatusContext)
+	}
+}
+
+func TestSendCommitStatusWithoutSHA(t *testing.T) {
+	fg := &fakeGitHub{t: t}
+	n := newTestNotifier(t, map[string]interface{}{"target": "commitStatus"}, issuePayload, fg)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if calls := fg.gotCalls(); len(calls) != 0 {
+		t.Errorf("got unexpected GitHub API calls: %v", calls)
+	}
+}
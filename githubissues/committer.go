@@ -0,0 +1,356 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// committerLoginSubst is the substitution that GetAndSetCommitterInfo sets to the GitHub login of the build's committer.
+const committerLoginSubst = "GH_COMMITTER_LOGIN"
+
+// defaultCommitterSources is the default committerSource fallback chain: the commit (or release) author's GitHub
+// login, then the committer's GitHub login, then the commit author's name.
+var defaultCommitterSources = []string{"author.login", "committer.login", "commit.author.name"}
+
+// githubUser is the subset of a GitHub user resource that committer lookups use.
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+func (u *githubUser) login() string {
+	if u == nil {
+		return ""
+	}
+	return u.Login
+}
+
+// gitActor is the subset of a git commit's author or committer that committer lookups use.
+type gitActor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (a *gitActor) name() string {
+	if a == nil {
+		return ""
+	}
+	return a.Name
+}
+
+func (a *gitActor) email() string {
+	if a == nil {
+		return ""
+	}
+	return a.Email
+}
+
+// committerResource is the subset of a GitHub commit or release resource that committer lookups use. Releases only
+// have an author.
+type committerResource struct {
+	Author    *githubUser `json:"author"`
+	Committer *githubUser `json:"committer"`
+	Commit    struct {
+		Author    *gitActor `json:"author"`
+		Committer *gitActor `json:"committer"`
+	} `json:"commit"`
+}
+
+// committerSourceFields maps the supported committerSource values, dotted JSON paths in a committerResource, to the
+// fields they read.
+var committerSourceFields = map[string]func(*committerResource) string{
+	"author.login":           func(r *committerResource) string { return r.Author.login() },
+	"committer.login":        func(r *committerResource) string { return r.Committer.login() },
+	"commit.author.name":     func(r *committerResource) string { return r.Commit.Author.name() },
+	"commit.author.email":    func(r *committerResource) string { return r.Commit.Author.email() },
+	"commit.committer.name":  func(r *committerResource) string { return r.Commit.Committer.name() },
+	"commit.committer.email": func(r *committerResource) string { return r.Commit.Committer.email() },
+}
+
+// supportedCommitterSources returns the committerSource values, sorted.
+func supportedCommitterSources() []string {
+	srcs := make([]string, 0, len(committerSourceFields))
+	for src := range committerSourceFields {
+		srcs = append(srcs, src)
+	}
+	sort.Strings(srcs)
+	return srcs
+}
+
+// decodeCommitterResource decodes a GitHub commit or release resource. Fields of unexpected types (e.g. an author that
+// isn't an object) are left empty rather than failing the lookup, so the other committer sources are still tried.
+func decodeCommitterResource(data []byte) (*committerResource, error) {
+	var res committerResource
+	if err := json.Unmarshal(data, &res); err != nil {
+		var te *json.UnmarshalTypeError
+		if !errors.As(err, &te) {
+			return nil, err
+		}
+		log.V(2).Infof("ignoring committer lookup fields of unexpected types: %v", err)
+	}
+	return &res, nil
+}
+
+// defaultCommitterCacheTTL is how long a looked-up committer is cached by default.
+const defaultCommitterCacheTTL = 10 * time.Minute
+
+// GetAndSetCommitterInfo looks up who is responsible for the build's ref and stores it in the GH_COMMITTER_LOGIN
+// substitution, so templates can mention them. Lookup failures are logged and leave the substitutions unchanged.
+func (g *githubissuesNotifier) GetAndSetCommitterInfo(ctx context.Context, build *cbpb.Build, repo string) {
+	if g.skipCommitterLookup {
+		return
+	}
+	if err := g.committerEnricher(repo).Enrich(ctx, build); err != nil {
+		log.Warningf("failed to enrich Build %q with its committer: %v", build.Id, err)
+	}
+}
+
+// committerEnricher returns the enricher of GetAndSetCommitterInfo, which looks committers up in the given repo. It runs
+// per notified repo, after the notifier's filters, rather than as one of the notifier's Enrichers.
+func (g *githubissuesNotifier) committerEnricher(repo string) *notifiers.CommitterEnricher {
+	return &notifiers.CommitterEnricher{
+		Substitution: committerLoginSubst,
+		Lookup: func(ctx context.Context, build *cbpb.Build) (string, error) {
+			committer, err := g.getCommitter(ctx, build, repo)
+			if err == nil && committer == "" {
+				log.V(2).Infof("no committer found for Build %q", build.Id)
+			}
+			return committer, err
+		},
+		Set: func(build *cbpb.Build, key, value string) {
+			g.setSubstitution(build, key, value)
+		},
+	}
+}
+
+// getCommitter returns the first present value of the notifier's committer sources, looked up in the build's tag
+// release or ref commit resource (see committerLookupPath).
+// If that lookup 404s (e.g. the commit was force-pushed away or the tag has no release), it makes exactly one more attempt
+// against the COMMIT_SHA commit, or the default branch's HEAD commit if the build has no COMMIT_SHA.
+// It returns "" if the build has no ref to look up, neither lookup finds anything, or no source is present.
+// Results for builds with a COMMIT_SHA are cached per repo and SHA (see committerCache).
+func (g *githubissuesNotifier) getCommitter(ctx context.Context, build *cbpb.Build, repo string) (string, error) {
+	path := committerLookupPath(build)
+	if path == "" {
+		return "", nil
+	}
+	var key string
+	if sha := build.Substitutions["COMMIT_SHA"]; sha != "" {
+		key = repo + "@" + sha
+		if committer, ok := g.committers.get(key); ok {
+			log.V(2).Infof("using cached committer %q of %q for Build %q", committer, key, build.Id)
+			return committer, nil
+		}
+	}
+	committer, err := g.lookupCommitter(ctx, build, repo, path)
+	if err == nil && key != "" {
+		g.committers.put(key, committer)
+	}
+	return committer, err
+}
+
+// lookupCommitter looks up the committer of getCommitter in the build's resource at the given repo-relative path.
+func (g *githubissuesNotifier) lookupCommitter(ctx context.Context, build *cbpb.Build, repo, path string) (string, error) {
+	lookupURL := fmt.Sprintf("%s/%s", g.repoURL(repo), path)
+	ctx = withRequestTimeout(ctx, g.timeouts.lookup)
+
+	var data json.RawMessage
+	err := g.doRequest(ctx, http.MethodGet, lookupURL, nil, &data)
+	if isNotFound(err) {
+		fallback := "HEAD"
+		if sha := build.Substitutions["COMMIT_SHA"]; sha != "" {
+			fallback = sha
+		}
+		fallbackURL := fmt.Sprintf("%s/commits/%s", g.repoURL(repo), url.PathEscape(fallback))
+		log.V(2).Infof("committer lookup at %q was not found, falling back to %q", lookupURL, fallbackURL)
+		err = g.doRequest(ctx, http.MethodGet, fallbackURL, nil, &data)
+	}
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	res, err := decodeCommitterResource(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode committer lookup response: %w", err)
+	}
+	for _, src := range g.committerSources {
+		if v := committerSourceFields[src](res); v != "" {
+			return v, nil
+		}
+	}
+	return "", nil
+}
+
+// committerCache is an in-memory cache of looked-up committers, keyed by repo and commit SHA, so repeated notifications
+// for a commit (e.g. its build's start and end) share one lookup. Entries expire after ttl. A nil committerCache caches
+// nothing.
+type committerCache struct {
+	ttl time.Duration
+	// now defaults to time.Now if nil.
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedCommitter
+}
+
+type cachedCommitter struct {
+	committer string
+	expires   time.Time
+}
+
+func (c *committerCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *committerCache) get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.clock().Before(e.expires) {
+		return "", false
+	}
+	return e.committer, true
+}
+
+// put caches the committer of the key, and drops expired entries so the cache only grows with the recent commits.
+func (c *committerCache) put(key, committer string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if c.entries == nil {
+		c.entries = map[string]cachedCommitter{}
+	}
+	c.entries[key] = cachedCommitter{committer: committer, expires: now.Add(c.ttl)}
+}
+
+// committerLookupPath returns the repo-relative API path of the resource the build's committer is looked up in: the
+// release of the build's tag, or else the commit its ref points to. Refs are URL-escaped, so branch names with slashes
+// (e.g. `feature/x`) stay a single path segment. It returns "" if the build has no ref.
+//
+// A build is for a tag if it has a TAG_NAME, or if its REF_NAME is fully qualified as `refs/tags/...`. A fully qualified
+// `refs/heads/...` REF_NAME is a branch. Builds without a REF_NAME fall back to their BRANCH_NAME.
+func committerLookupPath(build *cbpb.Build) string {
+	subs := build.Substitutions
+	ref := subs["REF_NAME"]
+	if tag := subs["TAG_NAME"]; tag != "" {
+		return "releases/tags/" + url.PathEscape(tag)
+	}
+	if tag := strings.TrimPrefix(ref, "refs/tags/"); tag != ref && tag != "" {
+		return "releases/tags/" + url.PathEscape(tag)
+	}
+	ref = strings.TrimPrefix(ref, "refs/heads/")
+	if ref == "" {
+		ref = subs["BRANCH_NAME"]
+	}
+	if ref == "" {
+		return ""
+	}
+	return "commits/" + url.PathEscape(ref)
+}
+
+// isNotFound returns true iff err is a 404 statusError.
+func isNotFound(err error) bool {
+	se, ok := err.(*statusError)
+	return ok && se.code == http.StatusNotFound
+}
+
+// setSubstitution is the write path for enriching the build's substitutions, which are available to templates.
+// It logs every value it sets and refuses to overwrite a non-empty substitution (e.g. one the user set on the build)
+// unless the notifier is configured with `overwriteSubstitutions: true`. It returns true iff the value was set.
+func (g *githubissuesNotifier) setSubstitution(build *cbpb.Build, key, value string) bool {
+	if old := build.Substitutions[key]; old != "" && !g.overwriteSubstitutions {
+		log.Infof("not overwriting substitution %q of Build %q (existing value %q, enriched value %q)", key, build.Id, old, value)
+		return false
+	}
+	if build.Substitutions == nil {
+		build.Substitutions = map[string]string{}
+	}
+	build.Substitutions[key] = value
+	log.Infof("enriched Build %q with substitution %q = %q", build.Id, key, value)
+	return true
+}
+
+// loginGlob is a GitHub login pattern in which `*` matches any run of characters.
+type loginGlob struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// parseSuppressCommitters parses the `suppressCommitters` delivery config field, a non-empty list of login globs. Only
+// `*` is special in them, so that e.g. `*[bot]` matches bot logins like `dependabot[bot]`.
+func parseSuppressCommitters(delivery map[string]interface{}) ([]loginGlob, error) {
+	globs, err := getStringListField(delivery, suppressCommittersField)
+	if err != nil {
+		return nil, err
+	}
+	if len(globs) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty list of login patterns", suppressCommittersField)
+	}
+	var out []loginGlob
+	for _, glob := range globs {
+		if glob == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to contain login patterns like %q, got %q", suppressCommittersField, "*[bot]", glob)
+		}
+		expr := strings.ReplaceAll(regexp.QuoteMeta(glob), `\*`, ".*")
+		out = append(out, loginGlob{glob: glob, re: regexp.MustCompile("(?i)^" + expr + "$")})
+	}
+	return out, nil
+}
+
+// suppressingCommitterGlob returns the suppressCommitters glob that the build's GH_COMMITTER_LOGIN matches,
+// case-insensitively like GitHub logins, and false if it matches none or is unset.
+func (g *githubissuesNotifier) suppressingCommitterGlob(build *cbpb.Build) (string, bool) {
+	login := build.Substitutions[committerLoginSubst]
+	if login == "" {
+		return "", false
+	}
+	for _, lg := range g.suppressCommitters {
+		if lg.re.MatchString(login) {
+			return lg.glob, true
+		}
+	}
+	return "", false
+}
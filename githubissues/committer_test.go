@@ -0,0 +1,523 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetAndSetCommitterInfo(t *testing.T) {
+	const (
+		commitPath  = "GET /repos/somename/somerepo/commits/main"
+		releasePath = "GET /repos/somename/somerepo/releases/tags/v1.0.0"
+	)
+	for _, tc := range []struct {
+		name      string
+		subs      map[string]string
+		responses map[string]fakeResponse
+		want      string
+	}{{
+		name: "commit author login",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			commitPath: {http.StatusOK, `{"author": {"login": "author"}, "committer": {"login": "committer"}, "commit": {"author": {"name": "Author Name"}}}`},
+		},
+		want: "author",
+	}, {
+		name: "commit committer login when author is not a GitHub user",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			commitPath: {http.StatusOK, `{"author": null, "committer": {"login": "committer"}, "commit": {"author": {"name": "Author Name"}}}`},
+		},
+		want: "committer",
+	}, {
+		name: "commit author name when neither is a GitHub user",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			commitPath: {http.StatusOK, `{"commit": {"author": {"name": "Author Name"}}}`},
+		},
+		want: "Author Name",
+	}, {
+		name: "release author for tags",
+		subs: map[string]string{"REF_NAME": "v1.0.0", "TAG_NAME": "v1.0.0"},
+		responses: map[string]fakeResponse{
+			releasePath: {http.StatusOK, `{"author": {"login": "releaser"}}`},
+		},
+		want: "releaser",
+	}, {
+		name: "unknown ref",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			commitPath: {http.StatusNotFound, `{"message": "Not Found"}`},
+		},
+		want: "",
+	}, {
+		name: "unexpected author type",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			commitPath: {http.StatusOK, `{"author": "author", "committer": {"login": 42}, "commit": {"author": {"name": "Author Name"}}}`},
+		},
+		want: "Author Name",
+	}, {
+		name: "unexpected resource type",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			commitPath: {http.StatusOK, `[{"author": {"login": "author"}}]`},
+		},
+		want: "",
+	}, {
+		name: "no ref",
+		subs: map[string]string{},
+		want: "",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, responses: tc.responses}
+			n := newTestNotifier(t, nil, issuePayload, fg)
+
+			build := &cbpb.Build{Id: "some-build-id", Substitutions: tc.subs}
+			n.GetAndSetCommitterInfo(context.Background(), build, "somename/somerepo")
+			if got := build.Substitutions[committerLoginSubst]; got != tc.want {
+				t.Errorf("got %s %q, want %q", committerLoginSubst, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetSubstitution(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		overwrite bool
+		subs      map[string]string
+		wantSet   bool
+		want      string
+	}{{
+		name:    "unset key",
+		subs:    map[string]string{},
+		wantSet: true,
+		want:    "enriched",
+	}, {
+		name:    "nil substitutions",
+		wantSet: true,
+		want:    "enriched",
+	}, {
+		name:    "empty key",
+		subs:    map[string]string{committerLoginSubst: ""},
+		wantSet: true,
+		want:    "enriched",
+	}, {
+		name:    "user-set key is kept",
+		subs:    map[string]string{committerLoginSubst: "user-set"},
+		wantSet: false,
+		want:    "user-set",
+	}, {
+		name:      "user-set key is overwritten with flag",
+		overwrite: true,
+		subs:      map[string]string{committerLoginSubst: "user-set"},
+		wantSet:   true,
+		want:      "enriched",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := &githubissuesNotifier{overwriteSubstitutions: tc.overwrite}
+			build := &cbpb.Build{Id: "some-build-id", Substitutions: tc.subs}
+			if got := n.setSubstitution(build, committerLoginSubst, "enriched"); got != tc.wantSet {
+				t.Errorf("setSubstitution() = %v, want %v", got, tc.wantSet)
+			}
+			if got := build.Substitutions[committerLoginSubst]; got != tc.want {
+				t.Errorf("got %s %q, want %q", committerLoginSubst, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommitterSources(t *testing.T) {
+	const (
+		commitPath = "GET /repos/somename/somerepo/commits/main"
+		commit     = `{"author": null, "committer": {"login": "committer"}, "commit": {"author": {"name": "Author Name"}, "committer": {"email": "c@example.com"}}}`
+	)
+	for _, tc := range []struct {
+		name    string
+		sources []interface{}
+		want    string
+	}{{
+		name: "default chain falls back past missing author",
+		want: "committer",
+	}, {
+		name:    "author name first",
+		sources: []interface{}{"commit.author.name", "committer.login"},
+		want:    "Author Name",
+	}, {
+		name:    "missing fields are skipped",
+		sources: []interface{}{"author.login", "commit.committer.name", "commit.committer.email"},
+		want:    "c@example.com",
+	}, {
+		name:    "nothing present",
+		sources: []interface{}{"author.login", "commit.author.email"},
+		want:    "",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var delivery map[string]interface{}
+			if tc.sources != nil {
+				delivery = map[string]interface{}{"committerSource": tc.sources}
+			}
+			fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{commitPath: {http.StatusOK, commit}}}
+			n := newTestNotifier(t, delivery, issuePayload, fg)
+
+			build := &cbpb.Build{Id: "some-build-id", Substitutions: map[string]string{"REF_NAME": "main"}}
+			got, err := n.getCommitter(context.Background(), build, "somename/somerepo")
+			if err != nil {
+				t.Fatalf("getCommitter() got unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("getCommitter() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitterNotFoundFallback(t *testing.T) {
+	const (
+		refPath  = "GET /repos/somename/somerepo/commits/main"
+		shaPath  = "GET /repos/somename/somerepo/commits/abc123"
+		headPath = "GET /repos/somename/somerepo/commits/HEAD"
+		tagPath  = "GET /repos/somename/somerepo/releases/tags/v1.0.0"
+	)
+	notFound := fakeResponse{http.StatusNotFound, `{"message": "Not Found"}`}
+	for _, tc := range []struct {
+		name      string
+		subs      map[string]string
+		responses map[string]fakeResponse
+		want      string
+		wantCalls []string
+	}{{
+		name:      "ref found",
+		subs:      map[string]string{"REF_NAME": "main", "COMMIT_SHA": "abc123"},
+		responses: map[string]fakeResponse{refPath: {http.StatusOK, `{"author": {"login": "ref-author"}}`}},
+		want:      "ref-author",
+		wantCalls: []string{refPath},
+	}, {
+		name: "ref not found falls back to COMMIT_SHA",
+		subs: map[string]string{"REF_NAME": "main", "COMMIT_SHA": "abc123"},
+		responses: map[string]fakeResponse{
+			refPath: notFound,
+			shaPath: {http.StatusOK, `{"author": {"login": "sha-author"}}`},
+		},
+		want:      "sha-author",
+		wantCalls: []string{refPath, shaPath},
+	}, {
+		name: "ref not found falls back to default branch without COMMIT_SHA",
+		subs: map[string]string{"REF_NAME": "main"},
+		responses: map[string]fakeResponse{
+			refPath:  notFound,
+			headPath: {http.StatusOK, `{"author": {"login": "head-author"}}`},
+		},
+		want:      "head-author",
+		wantCalls: []string{refPath, headPath},
+	}, {
+		name: "tag without release falls back to COMMIT_SHA",
+		subs: map[string]string{"REF_NAME": "v1.0.0", "TAG_NAME": "v1.0.0", "COMMIT_SHA": "abc123"},
+		responses: map[string]fakeResponse{
+			tagPath: notFound,
+			shaPath: {http.StatusOK, `{"author": {"login": "sha-author"}}`},
+		},
+		want:      "sha-author",
+		wantCalls: []string{tagPath, shaPath},
+	}, {
+		name:      "only one extra attempt",
+		subs:      map[string]string{"REF_NAME": "main", "COMMIT_SHA": "abc123"},
+		responses: map[string]fakeResponse{refPath: notFound, shaPath: notFound},
+		want:      "",
+		wantCalls: []string{refPath, shaPath},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, responses: tc.responses}
+			n := newTestNotifier(t, nil, issuePayload, fg)
+
+			build := &cbpb.Build{Id: "some-build-id", Substitutions: tc.subs}
+			got, err := n.getCommitter(context.Background(), build, "somename/somerepo")
+			if err != nil {
+				t.Fatalf("getCommitter() got unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("getCommitter() = %q, want %q", got, tc.want)
+			}
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeCommitterResource(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr bool
+	}{{
+		name: "commit",
+		data: `{"author": {"login": "octocat", "type": null, "id": 1}, "committer": {"login": "web-flow"}, "commit": {"author": {"name": "Octo Cat", "email": "o@example.com"}}}`,
+		want: map[string]string{"author.login": "octocat", "committer.login": "web-flow", "commit.author.name": "Octo Cat", "commit.author.email": "o@example.com"},
+	}, {
+		name: "release",
+		data: `{"author": {"login": "octocat"}, "tag_name": "v1.0.0"}`,
+		want: map[string]string{"author.login": "octocat"},
+	}, {
+		name: "fields of unexpected types are left empty",
+		data: `{"author": ["not", "an", "object"], "committer": {"login": 42}, "commit": {"author": {"name": "Octo Cat"}, "committer": "web-flow"}}`,
+		want: map[string]string{"commit.author.name": "Octo Cat"},
+	}, {
+		name: "not an object",
+		data: `[{"author": {"login": "octocat"}}]`,
+		want: map[string]string{},
+	}, {
+		name:    "malformed",
+		data:    `{"author": `,
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := decodeCommitterResource([]byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("decodeCommitterResource() got error %v, want error: %t", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := map[string]string{}
+			for src, field := range committerSourceFields {
+				if v := field(res); v != "" {
+					got[src] = v
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected committer sources (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommitterLookupPath(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		subs map[string]string
+		want string
+	}{{
+		name: "branch",
+		subs: map[string]string{"REF_NAME": "main"},
+		want: "commits/main",
+	}, {
+		name: "branch with slashes",
+		subs: map[string]string{"REF_NAME": "feature/x", "BRANCH_NAME": "feature/x"},
+		want: "commits/feature%2Fx",
+	}, {
+		name: "branch with special characters",
+		subs: map[string]string{"REF_NAME": "fix/50% off#1"},
+		want: "commits/fix%2F50%25%20off%231",
+	}, {
+		name: "fully qualified branch",
+		subs: map[string]string{"REF_NAME": "refs/heads/feature/x"},
+		want: "commits/feature%2Fx",
+	}, {
+		name: "tag",
+		subs: map[string]string{"REF_NAME": "v1.0.0", "TAG_NAME": "v1.0.0"},
+		want: "releases/tags/v1.0.0",
+	}, {
+		name: "tag with slashes",
+		subs: map[string]string{"REF_NAME": "release/v1", "TAG_NAME": "release/v1"},
+		want: "releases/tags/release%2Fv1",
+	}, {
+		name: "fully qualified tag without TAG_NAME",
+		subs: map[string]string{"REF_NAME": "refs/tags/v2.0.0"},
+		want: "releases/tags/v2.0.0",
+	}, {
+		name: "BRANCH_NAME without REF_NAME",
+		subs: map[string]string{"BRANCH_NAME": "dev/y"},
+		want: "commits/dev%2Fy",
+	}, {
+		name: "no ref",
+		subs: map[string]string{"COMMIT_SHA": "abc123"},
+		want: "",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := committerLookupPath(&cbpb.Build{Substitutions: tc.subs}); got != tc.want {
+				t.Errorf("committerLookupPath() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitterEscapesRef(t *testing.T) {
+	var gotPaths []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.EscapedPath())
+		fmt.Fprint(w, `{"author": {"login": "author"}}`)
+	})
+	n := newTestNotifier(t, nil, issuePayload, h)
+
+	build := &cbpb.Build{Id: "some-build-id", Substitutions: map[string]string{"REF_NAME": "feature/x"}}
+	got, err := n.getCommitter(context.Background(), build, "somename/somerepo")
+	if err != nil {
+		t.Fatalf("getCommitter failed: %v", err)
+	}
+	if got != "author" {
+		t.Errorf("getCommitter() = %q, want %q", got, "author")
+	}
+	if want := []string{"/repos/somename/somerepo/commits/feature%2Fx"}; !cmp.Equal(gotPaths, want) {
+		t.Errorf("got request paths %q, want %q", gotPaths, want)
+	}
+}
+
+func TestGetCommitterCache(t *testing.T) {
+	var lookups []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups = append(lookups, r.URL.Path)
+		fmt.Fprintf(w, `{"author": {"login": "author-%d"}}`, len(lookups))
+	})
+	n := newTestNotifier(t, map[string]interface{}{"committerCacheTTL": "1m"}, issuePayload, h)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	n.committers.now = func() time.Time { return now }
+
+	getCommitter := func(repo, sha string) string {
+		t.Helper()
+		build := &cbpb.Build{Id: "some-build-id", Substitutions: map[string]string{"REF_NAME": "main", "COMMIT_SHA": sha}}
+		got, err := n.getCommitter(context.Background(), build, repo)
+		if err != nil {
+			t.Fatalf("getCommitter failed: %v", err)
+		}
+		return got
+	}
+	for _, tc := range []struct {
+		name    string
+		repo    string
+		sha     string
+		advance time.Duration
+		want    string
+	}{
+		{name: "first lookup", repo: "somename/somerepo", sha: "abc", want: "author-1"},
+		{name: "cached", repo: "somename/somerepo", sha: "abc", advance: 59 * time.Second, want: "author-1"},
+		{name: "other SHA", repo: "somename/somerepo", sha: "def", want: "author-2"},
+		{name: "other repo", repo: "somename/otherrepo", sha: "abc", want: "author-3"},
+		{name: "expired", repo: "somename/somerepo", sha: "abc", advance: time.Second, want: "author-4"},
+		{name: "no SHA", repo: "somename/somerepo", want: "author-5"},
+		{name: "no SHA again", repo: "somename/somerepo", want: "author-6"},
+	} {
+		now = now.Add(tc.advance)
+		if got := getCommitter(tc.repo, tc.sha); got != tc.want {
+			t.Errorf("%s: getCommitter() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if len(n.committers.entries) != 3 {
+		t.Errorf("got %d cached committers, want the expired one replaced: %v", len(n.committers.entries), n.committers.entries)
+	}
+}
+
+func TestCommitterCacheDisabled(t *testing.T) {
+	n := newTestNotifier(t, map[string]interface{}{"committerCacheTTL": "0s"}, issuePayload, http.NotFoundHandler())
+	if n.committers != nil {
+		t.Errorf("got committer cache %v, want none", n.committers)
+	}
+}
+
+func TestSkipCommitterLookup(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{"skipCommitterLookup": true}, issuePayload, fg)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "REF_NAME": "main"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	for _, call := range fg.gotCalls() {
+		if strings.Contains(call, "/commits/") {
+			t.Errorf("got committer lookup %q, want none", call)
+		}
+	}
+	if _, ok := build.Substitutions[committerLoginSubst]; ok {
+		t.Errorf("got %s %q, want it unset", committerLoginSubst, build.Substitutions[committerLoginSubst])
+	}
+}
+
+func TestSuppressCommitters(t *testing.T) {
+	const (
+		lookup = "GET /repos/somename/somerepo/commits/main"
+		create = "POST /repos/somename/somerepo/issues"
+	)
+	for _, tc := range []struct {
+		name       string
+		author     string
+		wantCreate bool
+	}{
+		{name: "bot", author: "dependabot[bot]"},
+		{name: "bot, other case", author: "Renovate[Bot]"},
+		{name: "exact login", author: "release-robot"},
+		{name: "human", author: "octocat", wantCreate: true},
+		{name: "human with bot-like name", author: "bot", wantCreate: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{
+				lookup: {http.StatusOK, fmt.Sprintf(`{"author": {"login": %q}}`, tc.author)},
+			}}
+			n := newTestNotifier(t, map[string]interface{}{
+				"suppressCommitters": []interface{}{"*[bot]", "release-robot"},
+				"firstFailureOnly":   true,
+			}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "REF_NAME": "main"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			calls := fg.gotCalls()
+			if len(calls) == 0 || calls[0] != lookup {
+				t.Errorf("got calls %v, want the committer lookup first", calls)
+			}
+			if _, created := fg.bodies[create]; created != tc.wantCreate {
+				t.Errorf("created an issue: %t, want %t", created, tc.wantCreate)
+			}
+		})
+	}
+}
+
+func TestParseSuppressCommitters(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		wantErr bool
+	}{
+		{name: "valid", v: []interface{}{"*[bot]", "octocat"}},
+		{name: "not a list", v: "*[bot]", wantErr: true},
+		{name: "empty", v: []interface{}{}, wantErr: true},
+		{name: "empty pattern", v: []interface{}{""}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseSuppressCommitters(map[string]interface{}{suppressCommittersField: tc.v}); (err != nil) != tc.wantErr {
+				t.Errorf("parseSuppressCommitters(%v) = %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// cooldown records in its state store when a key was last allowed through and rejects it again until the window has
+// passed. A zero window allows everything.
+type cooldown struct {
+	window time.Duration
+	store  notifiers.StateStore
+}
+
+func newCooldown(store notifiers.StateStore, window time.Duration) *cooldown {
+	return &cooldown{window: window, store: store}
+}
+
+// allow returns true iff the key was not recorded within the last window. It fails open: if the state store can't be
+// reached, the key is allowed.
+func (c *cooldown) allow(ctx context.Context, key string) bool {
+	if c.window <= 0 {
+		return true
+	}
+	_, ok, err := c.store.Get(ctx, "cooldown/"+key)
+	if err != nil {
+		log.Warningf("failed to check success cooldown of %q, allowing it: %v", key, err)
+		return true
+	}
+	return !ok
+}
+
+// record opens the window for the key. It's called once the key's notification has been sent, so that a notification
+// that failed doesn't hold back its own redelivery.
+func (c *cooldown) record(ctx context.Context, key string) {
+	if c.window <= 0 {
+		return
+	}
+	if err := c.store.Put(ctx, "cooldown/"+key, time.Now().UTC().Format(time.RFC3339), c.window); err != nil {
+		log.Warningf("failed to record success cooldown of %q: %v", key, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+func TestCooldown(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	c := newCooldown(&notifiers.MemoryStateStore{Now: func() time.Time { return now }}, time.Minute)
+
+	for _, step := range []struct {
+		name    string
+		elapsed time.Duration
+		key     string
+		want    bool
+	}{
+		{name: "first event", elapsed: 0, key: "a", want: true},
+		{name: "same key within window", elapsed: 30 * time.Second, key: "a", want: false},
+		{name: "other key within window", elapsed: 30 * time.Second, key: "b", want: true},
+		{name: "same key at window end", elapsed: time.Minute, key: "a", want: true},
+		{name: "same key right after re-allow", elapsed: time.Minute + time.Second, key: "a", want: false},
+	} {
+		now = start.Add(step.elapsed)
+		got := c.allow(context.Background(), step.key)
+		if got != step.want {
+			t.Errorf("%s: allow(%q) = %v, want %v", step.name, step.key, got, step.want)
+		}
+		if got {
+			c.record(context.Background(), step.key)
+		}
+	}
+}
+
+func TestCooldownZeroWindow(t *testing.T) {
+	c := newCooldown(new(notifiers.MemoryStateStore), 0)
+	for i := 0; i < 3; i++ {
+		if !c.allow(context.Background(), "a") {
+			t.Fatalf("allow() call %d with zero window = false, want true", i)
+		}
+	}
+}
+
+// failingStore is a notifiers.StateStore that can't be reached.
+type failingStore struct{}
+
+var errStoreDown = errors.New("store down")
+
+func (failingStore) Get(context.Context, string) (string, bool, error) {
+	return "", false, errStoreDown
+}
+func (failingStore) Put(context.Context, string, string, time.Duration) error {
+	return errStoreDown
+}
+func (failingStore) PutIfAbsent(context.Context, string, string, time.Duration) (bool, error) {
+	return false, errStoreDown
+}
+func (failingStore) Swap(context.Context, string, string, time.Duration) (string, bool, error) {
+	return "", false, errStoreDown
+}
+
+func TestCooldownFailsOpen(t *testing.T) {
+	c := newCooldown(failingStore{}, time.Minute)
+	for i := 0; i < 2; i++ {
+		if !c.allow(context.Background(), "a") {
+			t.Fatalf("allow() call %d with failing store = false, want true", i)
+		}
+	}
+}
+
+func TestCooldownUnrecorded(t *testing.T) {
+	// A key whose notification failed isn't recorded, so it's allowed again.
+	c := newCooldown(new(notifiers.MemoryStateStore), time.Minute)
+	for i := 0; i < 2; i++ {
+		if !c.allow(context.Background(), "a") {
+			t.Fatalf("allow() call %d without a recorded notification = false, want true", i)
+		}
+	}
+}
+
+func TestCooldownSharedStore(t *testing.T) {
+	// Two instances sharing a store share the window.
+	store := new(notifiers.MemoryStateStore)
+	first := newCooldown(store, time.Minute)
+	if !first.allow(context.Background(), "a") {
+		t.Fatal("allow() on first instance = false, want true")
+	}
+	first.record(context.Background(), "a")
+	if newCooldown(store, time.Minute).allow(context.Background(), "a") {
+		t.Error("allow() on second instance within window = true, want false")
+	}
+}
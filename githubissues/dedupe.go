@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	log "github.com/golang/glog"
+)
+
+// buildTrigger returns the ID of the build's trigger or, if it has none, its TRIGGER_NAME substitution.
+func buildTrigger(build *cbpb.Build) string {
+	if build.BuildTriggerId != "" {
+		return build.BuildTriggerId
+	}
+	return build.Substitutions["TRIGGER_NAME"]
+}
+
+// duplicateMarker returns the marker embedded in failure issues of the build's trigger and branch in the repo, so later
+// failures of the same trigger and branch can find them, and "" if the build has neither a trigger nor a branch.
+func duplicateMarker(repo string, build *cbpb.Build) string {
+	trigger, branch := buildTrigger(build), build.Substitutions["BRANCH_NAME"]
+	if trigger == "" && branch == "" {
+		return ""
+	}
+	return newMarker("dedupe", repo+"@"+trigger+":"+branch)
+}
+
+// commentOnDuplicate comments the body of the rendered issue on the open failure issue of the failed build's trigger
+// and branch, if there is one, instead of creating another issue. It returns false if there is none, or it can't be
+// looked up, so the issue is created.
+func (g *githubissuesNotifier) commentOnDuplicate(ctx context.Context, build *cbpb.Build, repo string, rendered []byte) (bool, error) {
+	marker := duplicateMarker(repo, build)
+	if marker == "" {
+		return false, nil
+	}
+	open, err := g.openFailureIssues(ctx, repo, marker)
+	if err != nil {
+		log.Warningf("failed to look up open failure issues of Build %q's trigger and branch in %q, creating an issue: %v", build.Id, repo, err)
+		return false, nil
+	}
+	if len(open) == 0 {
+		return false, nil
+	}
+	iss := open[0]
+	if err := checkAPIURL(g.apiURL, iss.URL); err != nil {
+		log.Warningf("not commenting on failure issue #%d in %q, creating an issue: %v", iss.Number, repo, err)
+		return false, nil
+	}
+	body, err := commentPayload(rendered)
+	if err != nil {
+		return false, err
+	}
+	g.logPayload(build, "comment", body)
+	if err := g.doRequest(ctx, http.MethodPost, iss.URL+"/comments", body, nil); err != nil {
+		return true, fmt.Errorf("failed to comment on failure issue #%d: %w%s", iss.Number, err, g.errorPayload("comment", body))
+	}
+	log.Infof("commented Build %q on open failure issue #%d in %q instead of creating a duplicate", build.Id, iss.Number, repo)
+	return true, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDuplicateMarker(t *testing.T) {
+	build := func(triggerID, triggerName, branch string) *cbpb.Build {
+		return &cbpb.Build{BuildTriggerId: triggerID, Substitutions: map[string]string{"TRIGGER_NAME": triggerName, "BRANCH_NAME": branch}}
+	}
+	if got := duplicateMarker("somename/somerepo", build("", "", "")); got != "" {
+		t.Errorf("duplicateMarker of a build without trigger and branch = %q, want \"\"", got)
+	}
+	byID := duplicateMarker("somename/somerepo", build("1234", "nightly", "main"))
+	if byID != duplicateMarker("somename/somerepo", build("1234", "renamed", "main")) {
+		t.Error("duplicateMarker depends on TRIGGER_NAME for builds with a trigger ID")
+	}
+	for _, other := range []string{
+		duplicateMarker("somename/somerepo", build("5678", "nightly", "main")),
+		duplicateMarker("somename/somerepo", build("1234", "nightly", "dev")),
+		duplicateMarker("somename/other", build("1234", "nightly", "main")),
+		duplicateMarker("somename/somerepo", build("", "nightly", "main")),
+	} {
+		if other == byID {
+			t.Errorf("duplicateMarker(...) = %q for different triggers, branches, or repos", other)
+		}
+	}
+}
+
+func TestDedupeFailures(t *testing.T) {
+	const (
+		search  = "GET /search/issues"
+		create  = "POST /repos/somename/somerepo/issues"
+		comment = "POST /repos/somename/somerepo/issues/3/comments"
+		lookup  = "GET /repos/somename/somerepo/commits/main"
+	)
+	mainBuild := &cbpb.Build{BuildTriggerId: "1234", Substitutions: map[string]string{"BRANCH_NAME": "main"}}
+	marker := duplicateMarker("somename/somerepo", mainBuild)
+	openIssue := fmt.Sprintf(`{"items": [{"number": 3, "url": "https://api.github.com/repos/somename/somerepo/issues/3", "body": "failed\n\n<!-- %s -->"}]}`, marker)
+
+	for _, tc := range []struct {
+		name        string
+		status      cbpb.Build_Status
+		branch      string
+		responses   map[string]fakeResponse
+		wantCalls   []string
+		wantMarker  bool
+		wantComment bool
+		wantErr     bool
+	}{{
+		name:       "first failure creates a marked issue",
+		status:     cbpb.Build_FAILURE,
+		branch:     "main",
+		wantCalls:  []string{lookup, search, create},
+		wantMarker: true,
+	}, {
+		name:        "repeated failure comments on the open issue",
+		status:      cbpb.Build_FAILURE,
+		branch:      "main",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls:   []string{lookup, search, comment},
+		wantComment: true,
+	}, {
+		name:   "open issues of other triggers or branches don't count",
+		status: cbpb.Build_FAILURE,
+		branch: "main",
+		responses: map[string]fakeResponse{
+			search: {http.StatusOK, `{"items": [{"number": 3, "url": "https://api.github.com/repos/somename/somerepo/issues/3", "body": "failed"}]}`},
+		},
+		wantCalls:  []string{lookup, search, create},
+		wantMarker: true,
+	}, {
+		name:       "failed search creates an issue",
+		status:     cbpb.Build_FAILURE,
+		branch:     "main",
+		responses:  map[string]fakeResponse{search: {http.StatusUnprocessableEntity, `{}`}},
+		wantCalls:  []string{lookup, search, create},
+		wantMarker: true,
+	}, {
+		name:        "failed comment isn't retried as an issue",
+		status:      cbpb.Build_FAILURE,
+		branch:      "main",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}, comment: {http.StatusForbidden, `{}`}},
+		wantCalls:   []string{lookup, search, comment},
+		wantComment: true,
+		wantErr:     true,
+	}, {
+		name:        "comment failed by GitHub is returned for redelivery",
+		status:      cbpb.Build_FAILURE,
+		branch:      "main",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}, comment: {http.StatusBadGateway, `{}`}},
+		wantCalls:   []string{lookup, search, comment},
+		wantComment: true,
+		wantErr:     true,
+	}, {
+		name:      "successes aren't deduplicated",
+		status:    cbpb.Build_SUCCESS,
+		branch:    "main",
+		responses: map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls: []string{lookup, create, "PATCH /repos/somename/somerepo/issues/7"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: tc.responses}
+			n := newTestNotifier(t, map[string]interface{}{"dedupeFailures": true}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:             "some-build-id",
+				Status:         tc.status,
+				BuildTriggerId: "1234",
+				Substitutions:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": tc.branch},
+			}
+			err := n.SendNotification(context.Background(), build)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+			// GitHub's answer decides whether the failed comment is redelivered.
+			if code := tc.responses[comment].code; err != nil && notifiers.IsPermanent(err) != (code < 500) {
+				t.Errorf("SendNotification got error %v, want permanent: %t", err, code < 500)
+			}
+
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+			body, _ := fg.bodies[create]["body"].(string)
+			if got := strings.Contains(body, marker); got != tc.wantMarker {
+				t.Errorf("created issue body %q contains the marker: %t, want %t", body, got, tc.wantMarker)
+			}
+			if got := fg.bodies[comment]["body"]; tc.wantComment && !strings.HasPrefix(fmt.Sprint(got), "Cloud Build") {
+				t.Errorf("comment body = %v, want the rendered issue body", got)
+			}
+		})
+	}
+}
+
+func TestDedupeFailuresExcludesFirstFailureOnly(t *testing.T) {
+	d := map[string]interface{}{
+		"githubToken":      map[interface{}]interface{}{"secretRef": "mytoken"},
+		"githubRepo":       "somename/somerepo",
+		"dedupeFailures":   true,
+		"firstFailureOnly": true,
+	}
+	cfg := &notifiers.Config{Spec: &notifiers.Spec{
+		Notification: &notifiers.Notification{Filter: `build.status == Build.Status.FAILURE`, Delivery: d},
+		Secrets:      []*notifiers.Secret{{LocalName: "mytoken", ResourceName: "mysekrit"}},
+	}}
+	if err := new(githubissuesNotifier).SetUp(context.Background(), cfg, issuePayload, new(fakeSecretGetter), new(fakeBindingResolver)); err == nil {
+		t.Error("SetUp with both dedupeFailures and firstFailureOnly succeeded, want error")
+	}
+}
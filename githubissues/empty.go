@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/golang/glog"
+)
+
+// Supported values of the `onEmptyRender` delivery config field.
+const (
+	onEmptyDefault = "default"
+	onEmptySkip    = "skip"
+)
+
+// The defaults of the `emptyTitle` and `emptyBody` delivery config fields.
+const (
+	defaultEmptyTitle = "Cloud Build notification"
+	defaultEmptyBody  = "The notification template rendered an empty body."
+)
+
+// emptyRenderPolicy handles templates that render a blank title or body, which GitHub rejects.
+type emptyRenderPolicy struct {
+	// skip skips notifications with a blank title or body instead of filling them in with title and body.
+	skip        bool
+	title, body string
+}
+
+// parseEmptyRenderPolicy parses the `onEmptyRender`, `emptyTitle`, and `emptyBody` delivery config fields.
+func parseEmptyRenderPolicy(delivery map[string]interface{}) (*emptyRenderPolicy, error) {
+	p := &emptyRenderPolicy{title: defaultEmptyTitle, body: defaultEmptyBody}
+	if v, ok := delivery[onEmptyRenderField]; ok {
+		switch v {
+		case onEmptyDefault:
+		case onEmptySkip:
+			p.skip = true
+		default:
+			return nil, fmt.Errorf("expected delivery config field %q to be %q or %q, got %v", onEmptyRenderField, onEmptyDefault, onEmptySkip, v)
+		}
+	}
+	for field, dst := range map[string]*string{emptyTitleField: &p.title, emptyBodyField: &p.body} {
+		v, ok := delivery[field]
+		if !ok {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok || strings.TrimSpace(s) == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to be a non-blank string, got %v", field, v)
+		}
+		if p.skip {
+			return nil, fmt.Errorf("delivery config field %q requires %q to be %q", field, onEmptyRenderField, onEmptyDefault)
+		}
+		*dst = s
+	}
+	return p, nil
+}
+
+// apply returns the rendered output with a blank or missing title, or a blank body, replaced by the policy's default,
+// or false if the policy skips such output. Output that renders nothing at all counts as having a blank title. Other
+// output that isn't a JSON object is returned as-is, for the target to reject.
+func (p *emptyRenderPolicy) apply(buildID string, rendered []byte) ([]byte, bool) {
+	fields := map[string]interface{}{}
+	if len(bytes.TrimSpace(rendered)) > 0 {
+		if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+			return rendered, true
+		}
+	}
+	var blank []string
+	if s, _ := fields["title"].(string); strings.TrimSpace(s) == "" {
+		blank = append(blank, "title")
+		fields["title"] = p.title
+	}
+	if s, ok := fields["body"].(string); ok && strings.TrimSpace(s) == "" {
+		blank = append(blank, "body")
+		fields["body"] = p.body
+	}
+	if len(blank) == 0 {
+		return rendered, true
+	}
+	if p.skip {
+		log.Warningf("template rendered a blank %s for Build %q, skipping the notification", strings.Join(blank, " and "), buildID)
+		return nil, false
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return rendered, true
+	}
+	log.Warningf("template rendered a blank %s for Build %q, sending the default instead", strings.Join(blank, " and "), buildID)
+	return out, true
+}
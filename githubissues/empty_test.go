@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSendNotificationEmptyRender(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	for _, tc := range []struct {
+		name     string
+		delivery map[string]interface{}
+		tmpl     string
+		// want is the title and body of the created issue, or nil if none is created.
+		want map[string]interface{}
+	}{{
+		name: "not blank",
+		tmpl: `{"title": "Build {{.Build.Id}} failed", "body": "see logs"}`,
+		want: map[string]interface{}{"title": "Build some-build-id failed", "body": "see logs"},
+	}, {
+		name: "blank title",
+		tmpl: `{"title": "{{with .Build.Substitutions._MISSING}}{{.}}{{end}} \n ", "body": "see logs"}`,
+		want: map[string]interface{}{"title": defaultEmptyTitle, "body": "see logs"},
+	}, {
+		name: "missing title",
+		tmpl: `{"body": "see logs"}`,
+		want: map[string]interface{}{"title": defaultEmptyTitle, "body": "see logs"},
+	}, {
+		name:     "blank body with a configured default",
+		delivery: map[string]interface{}{emptyBodyField: "Build failed, see the logs."},
+		tmpl:     `{"title": "failed", "body": "  "}`,
+		want:     map[string]interface{}{"title": "failed", "body": "Build failed, see the logs."},
+	}, {
+		name:     "nothing rendered",
+		delivery: map[string]interface{}{onEmptyRenderField: onEmptyDefault, emptyTitleField: "Build failed"},
+		tmpl:     `{{if .Build.Substitutions._MISSING}}{"title": "unused"}{{end}}  `,
+		want:     map[string]interface{}{"title": "Build failed", "body": ""},
+	}, {
+		name:     "skip blank body",
+		delivery: map[string]interface{}{onEmptyRenderField: onEmptySkip},
+		tmpl:     `{"title": "failed", "body": ""}`,
+	}, {
+		name:     "skip blank title",
+		delivery: map[string]interface{}{onEmptyRenderField: onEmptySkip},
+		tmpl:     `{"title": " ", "body": "see logs"}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, tc.delivery, tc.tmpl, fg)
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+			}
+			before := notifiers.FilteredTotal(notifiers.FilterReasonEmpty)
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			body, created := fg.bodies[create]
+			if tc.want == nil {
+				if created {
+					t.Errorf("created issue %v, want none", body)
+				}
+				if got := notifiers.FilteredTotal(notifiers.FilterReasonEmpty) - before; got != 1 {
+					t.Errorf("recorded %d empty notifications, want 1", got)
+				}
+				return
+			}
+			if !created {
+				t.Fatal("created no issue")
+			}
+			// Drop the marker embedded in the body.
+			b, _ := body["body"].(string)
+			got := map[string]interface{}{"title": body["title"], "body": strings.SplitN(b, "\n\n<!-- ", 2)[0]}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("created issue with unexpected title and body (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseEmptyRenderPolicyErrors(t *testing.T) {
+	for _, delivery := range []map[string]interface{}{
+		{onEmptyRenderField: "drop"},
+		{emptyTitleField: " "},
+		{emptyBodyField: 42},
+		{onEmptyRenderField: onEmptySkip, emptyTitleField: "unused"},
+	} {
+		if got, err := parseEmptyRenderPolicy(delivery); err == nil {
+			t.Errorf("parseEmptyRenderPolicy(%v) = %+v, want error", delivery, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// coreIssueFields are the issue fields that only the issue template sets.
+var coreIssueFields = map[string]bool{"title": true, "body": true}
+
+// parseExtraFields parses the `extraFields` delivery config field, a map of issue field names to templates.
+func parseExtraFields(v interface{}) (map[string]*template.Template, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty map of issue fields to templates, got %v", extraFieldsField, v)
+	}
+	fields := map[string]*template.Template{}
+	for k, t := range m {
+		name, _ := k.(string)
+		if name == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to have issue field name keys, got %v", extraFieldsField, k)
+		}
+		if coreIssueFields[name] {
+			return nil, fmt.Errorf("delivery config field %q can't set %q, which the issue template sets", extraFieldsField, name)
+		}
+		ts, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected delivery config field %q to map %q to a template string, got %v", extraFieldsField, name, t)
+		}
+		tmpl, err := template.New(extraFieldsField + "." + name).Funcs(notifiers.TemplateFuncs()).Parse(ts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template of extra field %q: %w", name, err)
+		}
+		fields[name] = tmpl
+	}
+	return fields, nil
+}
+
+// mergeExtraFields renders the extra fields over the view and adds them to the rendered issue. A field renders to the
+// JSON value it spells, e.g. a number or a list, or else to a string. Fields that the rendered issue already sets keep
+// the issue's value.
+func (g *githubissuesNotifier) mergeExtraFields(rendered []byte, view *notifiers.TemplateView) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to add extra fields to, got %q", rendered)
+	}
+	names := make([]string, 0, len(g.extraFields))
+	for name := range g.extraFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := fields[name]; ok {
+			log.Warningf("not setting extra field %q: the issue template already sets it", name)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := notifiers.ExecuteTemplate(g.extraFields[name], &buf, view); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(buf.Bytes(), &value); err != nil {
+			value = buf.String()
+		}
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
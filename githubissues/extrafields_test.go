@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtraFields(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/issues"
+		tmpl   = `{"title": "failed", "body": "see logs", "labels": ["ci"]}`
+	)
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{
+		"extraFields": map[interface{}]interface{}{
+			"milestone": "{{.Build.Substitutions._MILESTONE}}",
+			"type":      "{{.Build.Substitutions._TYPE}}",
+			"assignees": `["{{.Build.Substitutions._ONCALL}}"]`,
+			"labels":    `["ignored"]`,
+		},
+	}, tmpl, fg)
+
+	build := &cbpb.Build{
+		Id:     "some-build-id",
+		Status: cbpb.Build_FAILURE,
+		Substitutions: map[string]string{
+			"REPO_FULL_NAME": "somename/somerepo",
+			"_MILESTONE":     "4",
+			"_TYPE":          "Bug",
+			"_ONCALL":        "octocat",
+		},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	got := fg.bodies[create]
+	delete(got, "body") // Carries the marker.
+	want := map[string]interface{}{
+		"title":     "failed",
+		"labels":    []interface{}{"ci"},
+		"milestone": float64(4),
+		"type":      "Bug",
+		"assignees": []interface{}{"octocat"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected issue payload (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseExtraFields(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		wantErr bool
+	}{
+		{name: "valid", v: map[interface{}]interface{}{"milestone": "4"}},
+		{name: "not a map", v: "milestone", wantErr: true},
+		{name: "empty", v: map[interface{}]interface{}{}, wantErr: true},
+		{name: "core field", v: map[interface{}]interface{}{"title": "t"}, wantErr: true},
+		{name: "not a template string", v: map[interface{}]interface{}{"milestone": 4}, wantErr: true},
+		{name: "bad template", v: map[interface{}]interface{}{"milestone": "{{"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseExtraFields(tc.v); (err != nil) != tc.wantErr {
+				t.Errorf("parseExtraFields(%v) = %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+		})
+	}
+}
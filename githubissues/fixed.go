@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// marksDuplicates returns true iff failure issues are marked with the duplicateMarker of their trigger and branch.
+func (g *githubissuesNotifier) marksDuplicates() bool {
+	return g.dedupeFailures || g.closeFixedIssues || g.reopenClosedIssues
+}
+
+// fixedComment returns the comment that closes a failure issue fixed by the build.
+func fixedComment(build *cbpb.Build) string {
+	c := fmt.Sprintf("Fixed by Build [`%s`](%s)", build.Id, build.LogUrl)
+	if build.LogUrl == "" {
+		c = fmt.Sprintf("Fixed by Build `%s`", build.Id)
+	}
+	if sha := build.Substitutions["COMMIT_SHA"]; sha != "" {
+		c += " at " + sha
+	}
+	return c + "."
+}
+
+// openFailureIssues returns the open failure issues of the build's trigger and branch: the one recorded when it was
+// created, if it's still open, or else those that an issue search finds by their marker.
+func (g *githubissuesNotifier) openFailureIssues(ctx context.Context, repo, marker string) ([]*issue, error) {
+	if number, ok := g.failureIssues.get(ctx, marker); ok {
+		var iss issue
+		err := g.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", g.repoURL(repo), number), nil, &iss)
+		switch {
+		case err != nil:
+			log.Warningf("failed to get failure issue #%d in %q, searching for it instead: %v", number, repo, err)
+		case iss.State == "open":
+			return []*issue{&iss}, nil
+		}
+	}
+	return g.findOpenIssues(ctx, repo, marker)
+}
+
+// closeFixedFailures closes the open failure issues of the successful build's trigger and branch with a comment saying
+// that the build fixed them. It returns true iff there were any, or false, so the success is notified as usual, if
+// there are none or they can't be looked up. Failing to comment on or close one fails the notification.
+func (g *githubissuesNotifier) closeFixedFailures(ctx context.Context, build *cbpb.Build, repo string) (bool, error) {
+	marker := duplicateMarker(repo, build)
+	if marker == "" {
+		return false, nil
+	}
+	open, err := g.openFailureIssues(ctx, repo, marker)
+	if err != nil {
+		log.Warningf("failed to look up open failure issues of Build %q's trigger and branch in %q: %v", build.Id, repo, err)
+		return false, nil
+	}
+	if len(open) == 0 {
+		return false, nil
+	}
+	body, err := json.Marshal(map[string]string{"body": fixedComment(build)})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode comment: %w", err)
+	}
+	view := &notifiers.TemplateView{Build: &notifiers.BuildView{Build: build}, NotifierName: g.name}
+	view.MaskSecretRefs(g.sg)
+	for _, iss := range open {
+		if err := checkAPIURL(g.apiURL, iss.URL); err != nil {
+			log.Warningf("not closing failure issue #%d in %q: %v", iss.Number, repo, err)
+			continue
+		}
+		if err := g.doRequest(ctx, http.MethodPost, iss.URL+"/comments", body, nil); err != nil {
+			return true, fmt.Errorf("failed to comment fix on failure issue #%d: %w", iss.Number, err)
+		}
+		if err := g.closeIssue(ctx, iss, view); err != nil {
+			return true, fmt.Errorf("failed to close failure issue #%d: %w", iss.Number, err)
+		}
+		log.Infof("closed failure issue #%d in %q: fixed by Build %q", iss.Number, repo, build.Id)
+	}
+	return true, nil
+}
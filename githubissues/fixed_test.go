@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFixedComment(t *testing.T) {
+	for _, tc := range []struct {
+		build *cbpb.Build
+		want  string
+	}{{
+		build: &cbpb.Build{Id: "b1", LogUrl: "https://console.cloud.google.com/b1", Substitutions: map[string]string{"COMMIT_SHA": "abc123"}},
+		want:  "Fixed by Build [`b1`](https://console.cloud.google.com/b1) at abc123.",
+	}, {
+		build: &cbpb.Build{Id: "b1"},
+		want:  "Fixed by Build `b1`.",
+	}} {
+		if got := fixedComment(tc.build); got != tc.want {
+			t.Errorf("fixedComment(%v) = %q, want %q", tc.build, got, tc.want)
+		}
+	}
+}
+
+func TestCloseFixedIssues(t *testing.T) {
+	const (
+		search     = "GET /search/issues"
+		create     = "POST /repos/somename/somerepo/issues"
+		getCreated = "GET /repos/somename/somerepo/issues/7"
+		comment7   = "POST /repos/somename/somerepo/issues/7/comments"
+		close7     = "PATCH /repos/somename/somerepo/issues/7"
+		comment3   = "POST /repos/somename/somerepo/issues/3/comments"
+		close3     = "PATCH /repos/somename/somerepo/issues/3"
+		lookup     = "GET /repos/somename/somerepo/commits/main"
+	)
+	marker := duplicateMarker("somename/somerepo", &cbpb.Build{BuildTriggerId: "1234", Substitutions: map[string]string{"BRANCH_NAME": "main"}})
+	openIssue := fmt.Sprintf(`{"items": [{"number": 3, "url": "https://api.github.com/repos/somename/somerepo/issues/3", "body": "failed\n\n<!-- %s -->"}]}`, marker)
+	created := func(state string) fakeResponse {
+		return fakeResponse{http.StatusOK, fmt.Sprintf(`{"number": 7, "url": "https://api.github.com/repos/somename/somerepo/issues/7", "state": %q}`, state)}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		failFirst   bool
+		responses   map[string]fakeResponse
+		wantCalls   []string
+		wantComment string
+		wantErr     bool
+	}{{
+		name:        "closes the recorded failure issue",
+		failFirst:   true,
+		responses:   map[string]fakeResponse{getCreated: created("open")},
+		wantCalls:   []string{getCreated, comment7, close7},
+		wantComment: comment7,
+	}, {
+		name:        "searches if the recorded failure issue was closed",
+		failFirst:   true,
+		responses:   map[string]fakeResponse{getCreated: created("closed"), search: {http.StatusOK, openIssue}},
+		wantCalls:   []string{getCreated, search, comment3, close3},
+		wantComment: comment3,
+	}, {
+		name:        "closes failure issues found by search",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls:   []string{search, comment3, close3},
+		wantComment: comment3,
+	}, {
+		name:      "notifies the success as usual without a failure issue",
+		wantCalls: []string{search, lookup, create, close7},
+	}, {
+		name:      "notifies the success as usual if the search fails",
+		responses: map[string]fakeResponse{search: {http.StatusUnprocessableEntity, `{}`}},
+		wantCalls: []string{search, lookup, create, close7},
+	}, {
+		name:        "failed comment isn't retried as an issue",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}, comment3: {http.StatusBadGateway, `{}`}},
+		wantCalls:   []string{search, comment3},
+		wantComment: comment3,
+		wantErr:     true,
+	}, {
+		name:        "failed close isn't retried as an issue",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}, close3: {http.StatusForbidden, `{}`}},
+		wantCalls:   []string{search, comment3, close3},
+		wantComment: comment3,
+		wantErr:     true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"closeFixedIssues": true}, issuePayload, fg)
+			build := func(id string, status cbpb.Build_Status) *cbpb.Build {
+				return &cbpb.Build{
+					Id:             id,
+					Status:         status,
+					BuildTriggerId: "1234",
+					Substitutions:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main", "COMMIT_SHA": "abc123"},
+				}
+			}
+			if tc.failFirst {
+				if err := n.SendNotification(context.Background(), build("failed-build", cbpb.Build_FAILURE)); err != nil {
+					t.Fatalf("SendNotification failed: %v", err)
+				}
+			}
+			fg.mu.Lock()
+			fg.calls, fg.responses = nil, tc.responses
+			fg.mu.Unlock()
+
+			if err := n.SendNotification(context.Background(), build("fixing-build", cbpb.Build_SUCCESS)); (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+			if tc.wantComment == "" {
+				return
+			}
+			if got, want := fg.bodies[tc.wantComment]["body"], "Fixed by Build `fixing-build` at abc123."; got != want {
+				t.Errorf("comment = %v, want %q", got, want)
+			}
+		})
+	}
+}
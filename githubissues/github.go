@@ -0,0 +1,436 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// maxResponseBytes caps how much of a GitHub API response body is read, so a misbehaving server or proxy can't exhaust
+// the notifier's memory. It comfortably fits a page of issue search results.
+var maxResponseBytes int64 = 10 << 20
+
+// issue is the subset of a GitHub issue resource that the notifier uses.
+type issue struct {
+	Number  int    `json:"number"`
+	NodeID  string `json:"node_id"`
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	// StateReason is why a closed issue was closed, e.g. "completed" or "not_planned".
+	StateReason string  `json:"state_reason"`
+	Body        string  `json:"body"`
+	Labels      []label `json:"labels"`
+}
+
+type label struct {
+	Name string `json:"name"`
+}
+
+// hasLabel returns true iff the issue carries the named label.
+func (i *issue) hasLabel(name string) bool {
+	for _, l := range i.Labels {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// statusError is returned for GitHub API responses with a non-2xx status code.
+type statusError struct {
+	method string
+	url    string
+	code   int
+	status string
+	// rateLimited is true iff the response said the token ran out of rate limit.
+	rateLimited bool
+	// retryAfter is how long the response asked to wait before retrying, or 0 if it didn't say.
+	retryAfter time.Duration
+	// invalid lists the fields that a 422 (Unprocessable Entity) response rejected.
+	invalid []invalidField
+}
+
+// invalidField is an entry of the `errors` of a GitHub API validation failure, e.g. a label that doesn't exist.
+type invalidField struct {
+	Resource string      `json:"resource"`
+	Field    string      `json:"field"`
+	Code     string      `json:"code"`
+	Value    interface{} `json:"value"`
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("got a non-OK response status %q (%d) from %s %q", e.status, e.code, e.method, e.url)
+}
+
+// setHeaders sets the headers common to all GitHub API requests, authenticating with the given token.
+func (g *githubissuesNotifier) setHeaders(req *http.Request, token string) {
+	req.Header.Set("Accept", g.acceptHeader)
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	req.Header.Set("User-Agent", "GCB-Notifier/0.1 (http)")
+}
+
+// doRequest sends a GitHub API request with the given body (which may be nil) and decodes a successful response into out
+// (if non-nil). Responses with a non-2xx status are returned as a *statusError. Failed requests are retried according to
+// the notifier's retry policy.
+func (g *githubissuesNotifier) doRequest(ctx context.Context, method, url string, body []byte, out interface{}) error {
+	return g.retry.do(ctx, method, url, func() error {
+		return g.doRequestOnce(ctx, method, url, body, out)
+	})
+}
+
+// doRequestOnce makes a single attempt at a doRequest request.
+func (g *githubissuesNotifier) doRequestOnce(ctx context.Context, method, url string, body []byte, out interface{}) error {
+	ctx, cancel := g.requestContext(ctx)
+	defer cancel()
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return fmt.Errorf("failed to create a new HTTP request: %w", err)
+	}
+	token, err := g.token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a GitHub token: %w", err)
+	}
+	g.setHeaders(req, token)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized && g.app != nil {
+		g.app.invalidate(token)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		se := &statusError{
+			method:      method,
+			url:         url,
+			code:        resp.StatusCode,
+			status:      resp.Status,
+			rateLimited: resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "",
+			retryAfter:  retryAfter(resp.Header, time.Now()),
+		}
+		if resp.StatusCode == http.StatusUnprocessableEntity {
+			var v struct {
+				Errors []invalidField `json:"errors"`
+			}
+			// The details are best-effort: a body that doesn't decode just leaves them empty.
+			if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&v); err == nil {
+				se.invalid = v.Errors
+			}
+		}
+		return se
+	}
+	if out == nil {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s %q: %w", method, url, err)
+	}
+	if int64(len(data)) > maxResponseBytes {
+		log.Warningf("response from %s %q exceeds %d bytes, not decoding it", method, url, maxResponseBytes)
+		return fmt.Errorf("response from %s %q exceeds %d bytes", method, url, maxResponseBytes)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response from %s %q: %w", method, url, err)
+	}
+	return nil
+}
+
+// createIssue creates an issue in the given repo from the rendered issue JSON payload, whose body embeds the given
+// marker. If GitHub rejects the payload's labels as missing from the repo, they're created or dropped, per
+// createMissingLabels, and the create is retried once.
+func (g *githubissuesNotifier) createIssue(ctx context.Context, repo string, payload []byte) (*issue, error) {
+	return g.createMarkedIssue(ctx, repo, payload, "")
+}
+
+// createMarkedIssue is createIssue for a payload whose body embeds the given marker, by which a create whose response
+// was lost is recognized before it's retried. An empty marker skips that lookup.
+func (g *githubissuesNotifier) createMarkedIssue(ctx context.Context, repo string, payload []byte, marker string) (*issue, error) {
+	iss, err := g.postIssue(ctx, repo, payload, marker)
+	missing := missingLabels(err)
+	if len(missing) == 0 {
+		return iss, err
+	}
+	log.Warningf("GitHub rejected labels %q missing from %q: %v", missing, repo, err)
+	if payload, err = g.fixMissingLabels(ctx, repo, payload, missing); err != nil {
+		return nil, err
+	}
+	return g.postIssue(ctx, repo, payload, marker)
+}
+
+// postIssue makes the create request of createMarkedIssue. Before a create whose response was lost is retried, the
+// issue is looked up by its marker, if any, so the create isn't repeated. If GitHub rejects the token, the create is
+// retried with the backup tokens.
+func (g *githubissuesNotifier) postIssue(ctx context.Context, repo string, payload []byte, marker string) (*issue, error) {
+	iss := new(issue)
+	createURL := fmt.Sprintf("%s/issues", g.repoURL(repo))
+	err := g.withTokenFailover(ctx, fmt.Sprintf("creating an issue in %q", repo), func(ctx context.Context) error {
+		var last error
+		return g.retry.do(ctx, http.MethodPost, createURL, func() error {
+			if marker != "" && responseLost(last) {
+				found, err := g.findIssues(ctx, repo, marker, false)
+				if err != nil {
+					log.Warningf("failed to look up issue by marker %q before retrying its creation: %v", marker, err)
+				} else if len(found) > 0 {
+					log.Infof("found issue #%d in %q created by an earlier attempt, not creating it again", found[0].Number, repo)
+					*iss = *found[0]
+					return nil
+				}
+			}
+			if err := g.createPacer.wait(ctx, repo); err != nil {
+				return fmt.Errorf("gave up waiting to create an issue in %q: %w", repo, err)
+			}
+			last = g.doRequestOnce(withRequestTimeout(ctx, g.timeouts.create), http.MethodPost, createURL, payload, iss)
+			return last
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return iss, nil
+}
+
+// closeIssue closes the given issue via its API URL, rendering the close template over the given view. If GitHub
+// rejects the token, the close is retried with the backup tokens.
+func (g *githubissuesNotifier) closeIssue(ctx context.Context, iss *issue, view *notifiers.TemplateView) error {
+	if err := checkAPIURL(g.apiURL, iss.URL); err != nil {
+		return err
+	}
+	payload, err := g.closePayload(view)
+	if err != nil {
+		return err
+	}
+	return g.withTokenFailover(ctx, fmt.Sprintf("closing issue #%d", iss.Number), func(ctx context.Context) error {
+		return g.doRequest(withRequestTimeout(ctx, g.timeouts.close), http.MethodPatch, iss.URL, payload, nil)
+	})
+}
+
+// parseAPIURL parses the githubApiUrl delivery config field, the base URL of the GitHub REST API, which defaults to
+// defaultGitHubAPIURL. GitHub Enterprise Server serves it under /api/v3 of the instance's URL.
+func parseAPIURL(delivery map[string]interface{}) (string, error) {
+	v, ok := delivery[githubApiUrlField]
+	if !ok {
+		return defaultGitHubAPIURL, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected delivery config field %q to be a string, got %T", githubApiUrlField, v)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse delivery config field %q: %w", githubApiUrlField, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("expected delivery config field %q to be an http(s) URL without credentials, query, or fragment, got %q", githubApiUrlField, s)
+	}
+	return strings.TrimRight(s, "/"), nil
+}
+
+// repoURL returns the API URL of the repo, e.g. https://api.github.com/repos/owner/name.
+func (g *githubissuesNotifier) repoURL(repo string) string {
+	return g.apiURL + "/repos/" + repo
+}
+
+// checkAPIURL returns an error unless the URL, taken from a GitHub API response, has the scheme and host of the API
+// base URL. Requests to it carry the token, so a misconfigured proxy or a spoofed response mustn't be able to send them
+// elsewhere.
+func checkAPIURL(apiURL, raw string) error {
+	api, err := url.Parse(apiURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse GitHub API URL %q: %w", apiURL, err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse API URL %q: %w", raw, err)
+	}
+	if !strings.EqualFold(u.Scheme, api.Scheme) || !strings.EqualFold(u.Host, api.Host) {
+		return fmt.Errorf("API URL %q is not on the GitHub API host %q", raw, api.Host)
+	}
+	return nil
+}
+
+// closePayload returns the JSON body of the close PATCH: the rendered close template (if any) merged with
+// `"state": "closed"`, which always wins.
+func (g *githubissuesNotifier) closePayload(view *notifiers.TemplateView) ([]byte, error) {
+	fields := map[string]interface{}{}
+	if g.closeTmpl != nil {
+		var buf bytes.Buffer
+		if err := notifiers.ExecuteTemplate(g.closeTmpl, &buf, view); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+			return nil, fmt.Errorf("expected close template to render a JSON object, got %q: %w", buf.String(), err)
+		}
+		if fields == nil {
+			return nil, fmt.Errorf("expected close template to render a JSON object, got %q", buf.String())
+		}
+	}
+	fields["state"] = "closed"
+	return json.Marshal(fields)
+}
+
+// commentPayload returns the JSON body of a request commenting the body of the rendered issue.
+func commentPayload(rendered []byte) ([]byte, error) {
+	var ri renderedIssue
+	if err := json.Unmarshal(rendered, &ri); err != nil {
+		return nil, fmt.Errorf("failed to decode rendered template as an issue title and body: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{"body": ri.Body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode comment: %w", err)
+	}
+	return body, nil
+}
+
+// autoClose closes the issue that was just created for a build whose status the auto-close policy closes, unless
+// auto-close is disabled for the repo or the issue carries the configured do-not-close label.
+func (g *githubissuesNotifier) autoClose(ctx context.Context, repo string, iss *issue, view *notifiers.TemplateView) error {
+	if iss.URL == "" {
+		return fmt.Errorf("issue #%d in %q has no API URL to close it with", iss.Number, repo)
+	}
+	if err := checkAPIURL(g.apiURL, iss.URL); err != nil {
+		return fmt.Errorf("not closing issue #%d in %q: %w", iss.Number, repo, err)
+	}
+	if g.autoClosing.disabled(repo) {
+		log.V(2).Infof("auto-close is disabled for repo %q, leaving issue #%d open", repo, iss.Number)
+		return nil
+	}
+	// The labels come from the create (or lookup) response, so they're the issue's current labels.
+	if g.doNotCloseLabel != "" && iss.hasLabel(g.doNotCloseLabel) {
+		log.Infof("not auto-closing issue #%d in %q: it carries the %q label", iss.Number, repo, g.doNotCloseLabel)
+		return nil
+	}
+	if delay := g.autoClosing.closeDelay(repo); delay > 0 {
+		log.V(2).Infof("delaying auto-close of issue #%d in %q by %v", iss.Number, repo, delay)
+		sleep := g.sleep
+		if sleep == nil {
+			sleep = sleepCtx
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return fmt.Errorf("gave up waiting to close issue #%d in %q: %w", iss.Number, repo, err)
+		}
+	}
+	if err := g.swapLabels(ctx, iss); err != nil {
+		log.Warningf("failed to update labels of issue #%d in %q before closing it: %v", iss.Number, repo, err)
+	}
+	if err := g.closeIssue(ctx, iss, view); err != nil {
+		return fmt.Errorf("failed to close issue #%d in %q: %w", iss.Number, repo, err)
+	}
+	log.Infof("auto-closed issue #%d in %q", iss.Number, repo)
+	return nil
+}
+
+// swapLabels adds the configured labelsOnClose to the issue and removes those of removeLabelsOnClose that it carries,
+// using one request per change. To spare the rate limit, labels the issue already has (or lacks) are not re-sent.
+func (g *githubissuesNotifier) swapLabels(ctx context.Context, iss *issue) error {
+	var add []string
+	for _, l := range g.labelsOnClose {
+		if !iss.hasLabel(l) {
+			add = append(add, l)
+		}
+	}
+	if len(add) > 0 {
+		body, err := json.Marshal(map[string][]string{"labels": add})
+		if err != nil {
+			return fmt.Errorf("failed to encode labels: %w", err)
+		}
+		if err := g.doRequest(ctx, http.MethodPost, iss.URL+"/labels", body, nil); err != nil {
+			return fmt.Errorf("failed to add labels %q: %w", add, err)
+		}
+	}
+	for _, l := range g.removeLabelsOnClose {
+		if !iss.hasLabel(l) {
+			continue
+		}
+		if err := g.doRequest(ctx, http.MethodDelete, iss.URL+"/labels/"+url.PathEscape(l), nil, nil); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to remove label %q: %w", l, err)
+		}
+	}
+	return nil
+}
+
+// autoCloseEnv returns the name of the environment variable that disables auto-close for the given repo, e.g.
+// DISABLE_AUTO_CLOSE__MY_ORG_MY_REPO for "my-org/my-repo".
+func autoCloseEnv(repo string) string {
+	return "DISABLE_AUTO_CLOSE__" + envSuffix(repo)
+}
+
+// envSuffix upper-cases s and replaces every character that is not a letter or digit with an underscore, so it can be
+// used in an environment variable name.
+func envSuffix(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, s)
+}
+
+// autoCloseDelayEnv returns the name of the environment variable that delays auto-close for the given repo, e.g.
+// AUTO_CLOSE_DELAY__MY_ORG_MY_REPO for "my-org/my-repo".
+func autoCloseDelayEnv(repo string) string {
+	return "AUTO_CLOSE_DELAY__" + envSuffix(repo)
+}
+
+// autoCloseDelay returns how long to wait before auto-closing an issue in the given repo: the number of seconds in the
+// repo's AUTO_CLOSE_DELAY__ environment variable, or 0 if it is unset or invalid.
+func autoCloseDelay(repo string) time.Duration {
+	name := autoCloseDelayEnv(repo)
+	val, ok := notifiers.GetEnv(name)
+	if !ok || val == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(val)
+	if err != nil || secs < 0 {
+		log.Warningf("ignoring env var %q with value %q that is not a non-negative number of seconds", name, val)
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// autoCloseDisabled returns true iff the repo's DISABLE_AUTO_CLOSE__ environment variable is set to a true value.
+func autoCloseDisabled(repo string) bool {
+	name := autoCloseEnv(repo)
+	val, ok := notifiers.GetEnv(name)
+	if !ok {
+		return false
+	}
+	disabled, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Warningf("ignoring env var %q with non-boolean value %q", name, val)
+		return false
+	}
+	return disabled
+}
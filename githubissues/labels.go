@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// parseLabelTemplates parses the labels delivery config field, a list of labels that may be templates, e.g.
+// `branch:{{.Build.Substitutions.BRANCH_NAME}}`.
+func parseLabelTemplates(v interface{}) ([]*template.Template, error) {
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected delivery config field %q to be a list of labels, got %v", labelsField, v)
+	}
+	var tmpls []*template.Template
+	for i, e := range l {
+		s, _ := e.(string)
+		if strings.TrimSpace(s) == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to list non-empty labels, got %v", labelsField, e)
+		}
+		tmpl, err := template.New(fmt.Sprintf("%s.%d", labelsField, i)).Funcs(notifiers.TemplateFuncs()).Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse label %q: %w", s, err)
+		}
+		tmpls = append(tmpls, tmpl)
+	}
+	return tmpls, nil
+}
+
+// renderLabels renders the configured labels over the view, dropping those that render blank, e.g. for a substitution
+// that the build doesn't set.
+func (g *githubissuesNotifier) renderLabels(view *notifiers.TemplateView) ([]string, error) {
+	var labels []string
+	for _, tmpl := range g.labels {
+		var buf bytes.Buffer
+		if err := notifiers.ExecuteTemplate(tmpl, &buf, view); err != nil {
+			return nil, err
+		}
+		if l := strings.TrimSpace(buf.String()); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels, nil
+}
+
+// parseBranchLabelRules parses the branchLabelRules delivery config field, a map of regular expressions matching
+// BRANCH_NAME to labels.
+func parseBranchLabelRules(v interface{}) ([]patternRule, error) {
+	return parsePatternRules(branchLabelRulesField, "label", v)
+}
+
+// branchLabels returns the labels of the rules matching the branch, without duplicates. Builds without a branch get
+// none.
+func branchLabels(rules []patternRule, branch string) []string {
+	if branch == "" {
+		return nil
+	}
+	var labels []string
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if r.pattern.MatchString(branch) && !seen[r.value] {
+			seen[r.value] = true
+			labels = append(labels, r.value)
+		}
+	}
+	return labels
+}
+
+// mergeLabels adds the labels missing from the `labels` of the rendered issue.
+func mergeLabels(rendered []byte, labels []string) ([]byte, error) {
+	return mergeListField(rendered, "labels", labels)
+}
+
+// parseStatusLabels parses the statusLabels delivery config field, a map of build status names (e.g. "TIMEOUT") to a
+// label or a list of labels.
+func parseStatusLabels(v interface{}) (map[cbpb.Build_Status][]string, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty map of build statuses to labels, got %v", statusLabelsField, v)
+	}
+	labels := map[cbpb.Build_Status][]string{}
+	for k, l := range m {
+		name, _ := k.(string)
+		status, ok := cbpb.Build_Status_value[name]
+		if !ok {
+			return nil, fmt.Errorf("expected delivery config field %q to have build status keys like %q, got %v", statusLabelsField, "FAILURE", k)
+		}
+		var ls []string
+		switch l := l.(type) {
+		case string:
+			ls = []string{l}
+		case []interface{}:
+			for _, e := range l {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected delivery config field %q status %q to map to labels, got element %v", statusLabelsField, name, e)
+				}
+				ls = append(ls, s)
+			}
+		default:
+			return nil, fmt.Errorf("expected delivery config field %q status %q to map to a label or a list of labels, got %v", statusLabelsField, name, l)
+		}
+		for _, s := range ls {
+			if s == "" {
+				return nil, fmt.Errorf("expected delivery config field %q status %q to map to non-empty labels", statusLabelsField, name)
+			}
+		}
+		labels[cbpb.Build_Status(status)] = ls
+	}
+	return labels, nil
+}
+
+// missingLabels returns the labels that err, from an issue create, rejected as not existing in the repo.
+func missingLabels(err error) []string {
+	var se *statusError
+	if !errors.As(err, &se) || se.code != http.StatusUnprocessableEntity {
+		return nil
+	}
+	var labels []string
+	for _, f := range se.invalid {
+		if name, ok := f.Value.(string); ok && f.Resource == "Label" && name != "" {
+			labels = append(labels, name)
+		}
+	}
+	return labels
+}
+
+// fixMissingLabels makes the rendered issue's missing labels acceptable to GitHub: if createMissingLabels is set, it
+// creates them in the repo, and it drops those it didn't create from the rendered issue.
+func (g *githubissuesNotifier) fixMissingLabels(ctx context.Context, repo string, rendered []byte, missing []string) ([]byte, error) {
+	var drop []string
+	for _, l := range missing {
+		if g.createMissingLabels {
+			err := g.createLabel(ctx, repo, l)
+			if err == nil {
+				log.Infof("created missing label %q in %q", l, repo)
+				continue
+			}
+			log.Warningf("failed to create missing label %q in %q, dropping it from the issue: %v", l, repo, err)
+		}
+		drop = append(drop, l)
+	}
+	if len(drop) == 0 {
+		return rendered, nil
+	}
+	return removeListValues(rendered, "labels", drop)
+}
+
+// createLabel creates the named label, with GitHub's default color, in the repo. A label that another request created
+// in the meantime counts as created.
+func (g *githubissuesNotifier) createLabel(ctx context.Context, repo, name string) error {
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return fmt.Errorf("failed to encode label: %w", err)
+	}
+	err = g.doRequest(ctx, http.MethodPost, fmt.Sprintf("%s/labels", g.repoURL(repo)), body, nil)
+	var se *statusError
+	if errors.As(err, &se) && se.code == http.StatusUnprocessableEntity {
+		for _, f := range se.invalid {
+			if f.Code == "already_exists" {
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// removeListValues removes the values from the list field of the rendered issue with the given key.
+func removeListValues(rendered []byte, key string, values []string) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to remove %s from, got %q", key, rendered)
+	}
+	list, _ := fields[key].([]interface{})
+	drop := map[string]bool{}
+	for _, v := range values {
+		drop[v] = true
+	}
+	kept := []interface{}{}
+	for _, e := range list {
+		if s, ok := e.(string); !ok || !drop[s] {
+			kept = append(kept, e)
+		}
+	}
+	fields[key] = kept
+	return json.Marshal(fields)
+}
@@ -0,0 +1,516 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseBranchLabelRules(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		wantErr bool
+	}{{
+		name: "valid",
+		v:    map[interface{}]interface{}{"^release/": "release", "^hotfix/": "urgent"},
+	}, {
+		name:    "not a map",
+		v:       []interface{}{"^release/"},
+		wantErr: true,
+	}, {
+		name:    "empty",
+		v:       map[interface{}]interface{}{},
+		wantErr: true,
+	}, {
+		name:    "bad pattern",
+		v:       map[interface{}]interface{}{"release/(": "release"},
+		wantErr: true,
+	}, {
+		name:    "non-string label",
+		v:       map[interface{}]interface{}{"^release/": 1},
+		wantErr: true,
+	}, {
+		name:    "empty label",
+		v:       map[interface{}]interface{}{"^release/": ""},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseBranchLabelRules(tc.v)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("parseBranchLabelRules(%v) got error %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBranchLabels(t *testing.T) {
+	rules, err := parseBranchLabelRules(map[interface{}]interface{}{
+		"^release/":      "release",
+		"^hotfix/":       "urgent",
+		"^hotfix/sec-":   "security",
+		"^release/v1\\.": "release",
+		".*":             "ci",
+	})
+	if err != nil {
+		t.Fatalf("parseBranchLabelRules failed: %v", err)
+	}
+	for _, tc := range []struct {
+		branch string
+		want   []string
+	}{
+		{"main", []string{"ci"}},
+		{"release/v1.2", []string{"ci", "release"}},
+		{"hotfix/sec-123", []string{"ci", "urgent", "security"}},
+		{"", nil},
+	} {
+		t.Run(tc.branch, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, branchLabels(rules, tc.branch)); diff != "" {
+				t.Errorf("branchLabels(%q) returned unexpected labels (-want +got):\n%s", tc.branch, diff)
+			}
+		})
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		rendered string
+		want     []interface{}
+		wantErr  bool
+	}{{
+		name:     "no labels",
+		rendered: `{"title": "t"}`,
+		want:     []interface{}{"release", "urgent"},
+	}, {
+		name:     "existing labels",
+		rendered: `{"title": "t", "labels": ["bug", "urgent"]}`,
+		want:     []interface{}{"bug", "urgent", "release"},
+	}, {
+		name:     "null labels",
+		rendered: `{"title": "t", "labels": null}`,
+		want:     []interface{}{"release", "urgent"},
+	}, {
+		name:     "labels not a list",
+		rendered: `{"title": "t", "labels": "bug"}`,
+		wantErr:  true,
+	}, {
+		name:     "not an object",
+		rendered: `["t"]`,
+		wantErr:  true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := mergeLabels([]byte(tc.rendered), []string{"release", "urgent"})
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("mergeLabels(%s) = %s, want an error", tc.rendered, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeLabels(%s) failed: %v", tc.rendered, err)
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(got, &fields); err != nil {
+				t.Fatalf("mergeLabels returned invalid JSON %s: %v", got, err)
+			}
+			if diff := cmp.Diff(tc.want, fields["labels"]); diff != "" {
+				t.Errorf("mergeLabels(%s) returned unexpected labels (-want +got):\n%s", tc.rendered, diff)
+			}
+			if fields["title"] != "t" {
+				t.Errorf("mergeLabels(%s) lost the title: %s", tc.rendered, got)
+			}
+		})
+	}
+}
+
+func TestSendNotificationBranchLabels(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{
+		"branchLabelRules": map[interface{}]interface{}{"^release/": "release", "^hotfix/": "urgent"},
+	}, `{"title": "failed", "labels": ["cloud-build"]}`, fg)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "release/v2"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if diff := cmp.Diff([]interface{}{"cloud-build", "release"}, fg.bodies[create]["labels"]); diff != "" {
+		t.Errorf("created issue with unexpected labels (-want +got):\n%s", diff)
+	}
+}
+
+func TestSendNotificationCapsLabelsAndAssignees(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "release/v2"},
+		Steps:         []*cbpb.BuildStep{{Id: "deploy", Status: cbpb.Build_FAILURE}},
+	}
+	many := func(prefix string, n int) (quoted []string, want []interface{}) {
+		for i := 0; i < n; i++ {
+			quoted = append(quoted, fmt.Sprintf("%q", fmt.Sprintf("%s%d", prefix, i)))
+			want = append(want, fmt.Sprintf("%s%d", prefix, i))
+		}
+		return quoted, want
+	}
+	labels, wantLabels := many("label", githubMaxLabels+1)
+	assignees, wantAssignees := many("user", githubMaxAssignees+1)
+
+	for _, tc := range []struct {
+		name          string
+		delivery      map[string]interface{}
+		tmpl          string
+		wantLabels    interface{}
+		wantAssignees interface{}
+		wantDropped   []string
+	}{{
+		name:          "GitHub limits",
+		tmpl:          fmt.Sprintf(`{"title": "failed", "labels": [%s], "assignees": [%s]}`, strings.Join(labels, ","), strings.Join(assignees, ",")),
+		wantLabels:    wantLabels[:githubMaxLabels],
+		wantAssignees: wantAssignees[:githubMaxAssignees],
+		wantDropped:   []string{"dropping labels [label100]", "dropping assignees [user10]"},
+	}, {
+		name: "derived labels over the configured cap",
+		delivery: map[string]interface{}{
+			maxLabelsField:     3,
+			maxAssigneesField:  1,
+			"statusLabels":     map[interface{}]interface{}{"FAILURE": []interface{}{"failure", "flaky"}},
+			"branchLabelRules": map[interface{}]interface{}{"^release/": "release"},
+			"ownershipRules":   map[interface{}]interface{}{"^deploy$": "octocat"},
+		},
+		tmpl:          `{"title": "failed", "labels": ["cloud-build"], "assignees": ["hubot"]}`,
+		wantLabels:    []interface{}{"cloud-build", "failure", "flaky"},
+		wantAssignees: []interface{}{"hubot"},
+		wantDropped:   []string{"dropping labels [release]", "dropping assignees [octocat]"},
+	}, {
+		name:          "under the caps",
+		delivery:      map[string]interface{}{maxLabelsField: 2},
+		tmpl:          `{"title": "failed", "labels": ["cloud-build", "ci"]}`,
+		wantLabels:    []interface{}{"cloud-build", "ci"},
+		wantAssignees: nil,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, tc.delivery, tc.tmpl, fg)
+			logs := captureLogs(t, "0", func() {
+				if err := n.SendNotification(context.Background(), build); err != nil {
+					t.Fatalf("SendNotification failed: %v", err)
+				}
+			})
+			if diff := cmp.Diff(tc.wantLabels, fg.bodies[create]["labels"]); diff != "" {
+				t.Errorf("created issue with unexpected labels (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAssignees, fg.bodies[create]["assignees"]); diff != "" {
+				t.Errorf("created issue with unexpected assignees (-want +got):\n%s", diff)
+			}
+			for _, want := range tc.wantDropped {
+				if !strings.Contains(logs, want) {
+					t.Errorf("logs don't contain %q:\n%s", want, logs)
+				}
+			}
+			if len(tc.wantDropped) == 0 && strings.Contains(logs, "dropping") {
+				t.Errorf("logs unexpectedly report dropped values:\n%s", logs)
+			}
+		})
+	}
+}
+
+func TestParseListCap(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "unset", want: githubMaxLabels},
+		{name: "lowered", v: 5, want: 5},
+		{name: "at the limit", v: githubMaxLabels, want: githubMaxLabels},
+		{name: "over the limit", v: githubMaxLabels + 1, wantErr: true},
+		{name: "zero", v: 0, wantErr: true},
+		{name: "not an integer", v: "5", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			delivery := map[string]interface{}{}
+			if tc.v != nil {
+				delivery[maxLabelsField] = tc.v
+			}
+			got, err := parseListCap(delivery, maxLabelsField, githubMaxLabels)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseListCap(%v) got error %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseListCap(%v) = %d, want %d", tc.v, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseStatusLabels(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		want    map[cbpb.Build_Status][]string
+		wantErr bool
+	}{{
+		name: "labels and lists",
+		v:    map[interface{}]interface{}{"FAILURE": "failure", "TIMEOUT": []interface{}{"timeout", "flaky"}},
+		want: map[cbpb.Build_Status][]string{
+			cbpb.Build_FAILURE: {"failure"},
+			cbpb.Build_TIMEOUT: {"timeout", "flaky"},
+		},
+	}, {
+		name:    "unknown status",
+		v:       map[interface{}]interface{}{"BROKEN": "broken"},
+		wantErr: true,
+	}, {
+		name:    "lower case status",
+		v:       map[interface{}]interface{}{"failure": "failure"},
+		wantErr: true,
+	}, {
+		name:    "empty label",
+		v:       map[interface{}]interface{}{"FAILURE": ""},
+		wantErr: true,
+	}, {
+		name:    "non-string label",
+		v:       map[interface{}]interface{}{"FAILURE": []interface{}{1}},
+		wantErr: true,
+	}, {
+		name:    "not a map",
+		v:       "FAILURE",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseStatusLabels(tc.v)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseStatusLabels(%v) got error %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); !tc.wantErr && diff != "" {
+				t.Errorf("parseStatusLabels(%v) returned unexpected labels (-want +got):\n%s", tc.v, diff)
+			}
+		})
+	}
+}
+
+func TestSendNotificationStatusLabels(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	for _, tc := range []struct {
+		status cbpb.Build_Status
+		want   []interface{}
+	}{
+		{cbpb.Build_FAILURE, []interface{}{"cloud-build", "failure"}},
+		{cbpb.Build_TIMEOUT, []interface{}{"cloud-build", "timeout", "flaky"}},
+		{cbpb.Build_INTERNAL_ERROR, []interface{}{"cloud-build", "infra"}},
+		// No statusLabels entry, so only the template's labels.
+		{cbpb.Build_CANCELLED, []interface{}{"cloud-build"}},
+	} {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{
+				"statusLabels": map[interface{}]interface{}{
+					"FAILURE":        "failure",
+					"TIMEOUT":        []interface{}{"timeout", "flaky", "cloud-build"},
+					"INTERNAL_ERROR": "infra",
+				},
+			}, `{"title": "failed", "labels": ["cloud-build"]}`, fg)
+			filter, err := notifiers.MakeCELPredicate(`build.status != Build.Status.SUCCESS`)
+			if err != nil {
+				t.Fatalf("MakeCELPredicate failed: %v", err)
+			}
+			n.filter = filter
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        tc.status,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, fg.bodies[create]["labels"]); diff != "" {
+				t.Errorf("created issue with unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateMissingLabels(t *testing.T) {
+	const (
+		create      = "POST /repos/somename/somerepo/issues"
+		createLabel = "POST /repos/somename/somerepo/labels"
+		missing     = `{"message": "Validation Failed", "errors": [{"value": "flaky", "resource": "Label", "field": "name", "code": "invalid"}]}`
+	)
+	for _, tc := range []struct {
+		name                string
+		createMissingLabels bool
+		// labelResponse is the response to label creation.
+		labelResponse fakeResponse
+		wantCalls     []string
+		wantLabels    []interface{}
+	}{{
+		name:       "dropped by default",
+		wantCalls:  []string{create, create},
+		wantLabels: []interface{}{"ci"},
+	}, {
+		name:                "created",
+		createMissingLabels: true,
+		labelResponse:       fakeResponse{http.StatusCreated, `{"name": "flaky"}`},
+		wantCalls:           []string{create, createLabel, create},
+		wantLabels:          []interface{}{"ci", "flaky"},
+	}, {
+		name:                "created concurrently",
+		createMissingLabels: true,
+		labelResponse:       fakeResponse{http.StatusUnprocessableEntity, `{"errors": [{"resource": "Label", "field": "name", "code": "already_exists"}]}`},
+		wantCalls:           []string{create, createLabel, create},
+		wantLabels:          []interface{}{"ci", "flaky"},
+	}, {
+		name:                "dropped if creation fails",
+		createMissingLabels: true,
+		labelResponse:       fakeResponse{http.StatusForbidden, `{}`},
+		wantCalls:           []string{create, createLabel, create},
+		wantLabels:          []interface{}{"ci"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var gotCalls []string
+			var gotBody map[string]interface{}
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				call := r.Method + " " + r.URL.Path
+				gotCalls = append(gotCalls, call)
+				switch {
+				case call == createLabel:
+					w.WriteHeader(tc.labelResponse.code)
+					fmt.Fprint(w, tc.labelResponse.body)
+				case len(gotCalls) == 1:
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					fmt.Fprint(w, missing)
+				default:
+					gotBody = nil
+					if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+						t.Errorf("failed to decode request body: %v", err)
+					}
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, createdIssue)
+				}
+			})
+			n := newTestNotifier(t, map[string]interface{}{"createMissingLabels": tc.createMissingLabels}, issuePayload, h)
+
+			iss, err := n.createIssue(context.Background(), "somename/somerepo", []byte(`{"title": "t", "labels": ["ci", "flaky"]}`))
+			if err != nil {
+				t.Fatalf("createIssue failed: %v", err)
+			}
+			if iss.Number != 7 {
+				t.Errorf("createIssue returned issue #%d, want #7", iss.Number)
+			}
+			if diff := cmp.Diff(tc.wantCalls, gotCalls); diff != "" {
+				t.Errorf("unexpected calls (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLabels, gotBody["labels"]); diff != "" {
+				t.Errorf("unexpected labels of the retried create (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateMissingLabelsRetriesOnce(t *testing.T) {
+	var mu sync.Mutex
+	creates := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		creates++
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"errors": [{"value": "flaky", "resource": "Label", "field": "name", "code": "invalid"}]}`)
+	})
+	n := newTestNotifier(t, nil, issuePayload, h)
+
+	if _, err := n.createIssue(context.Background(), "somename/somerepo", []byte(`{"title": "t", "labels": ["flaky"]}`)); err == nil {
+		t.Fatal("createIssue unexpectedly succeeded")
+	}
+	if creates != 2 {
+		t.Errorf("got %d creates, want 2", creates)
+	}
+}
+
+func TestLabelTemplates(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	for _, tc := range []struct {
+		name   string
+		labels []interface{}
+		tmpl   string
+		subst  map[string]string
+		want   interface{}
+	}{{
+		name:   "static and templated labels",
+		labels: []interface{}{"ci-failure", "branch:{{.Build.Substitutions.BRANCH_NAME}}"},
+		tmpl:   `{"title": "failed"}`,
+		subst:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+		want:   []interface{}{"ci-failure", "branch:main"},
+	}, {
+		name:   "added to the template's labels without duplicates",
+		labels: []interface{}{"ci-failure", "cloud-build"},
+		tmpl:   `{"title": "failed", "labels": ["cloud-build"]}`,
+		subst:  map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+		want:   []interface{}{"cloud-build", "ci-failure"},
+	}, {
+		name:   "blank labels are dropped",
+		labels: []interface{}{"ci-failure", "{{with .Build.Substitutions._TEAM}}team:{{.}}{{end}}"},
+		tmpl:   `{"title": "failed"}`,
+		subst:  map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+		want:   []interface{}{"ci-failure"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"labels": tc.labels}, tc.tmpl, fg)
+			build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, Substitutions: tc.subst}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, fg.bodies[create]["labels"]); diff != "" {
+				t.Errorf("created issue with unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseLabelTemplatesErrors(t *testing.T) {
+	for _, v := range []interface{}{
+		"ci-failure",
+		[]interface{}{""},
+		[]interface{}{1},
+		[]interface{}{"{{.Build"},
+	} {
+		if _, err := parseLabelTemplates(v); err == nil {
+			t.Errorf("parseLabelTemplates(%v) succeeded, want error", v)
+		}
+	}
+}
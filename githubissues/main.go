@@ -17,11 +17,12 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
 	log "github.com/golang/glog"
@@ -29,9 +30,83 @@ import (
 	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
 )
 
+// Values of the `target` delivery config field, i.e. what the notifier creates for a build.
 const (
-	githubTokenSecretName = "githubToken"
-	githubApiEndpoint     = "https://api.github.com/repos"
+	targetIssue        = "issue"
+	targetCheckRun     = "checkRun"
+	targetCommitStatus = "commitStatus"
+)
+
+const (
+	githubTokenSecretName         = "githubToken"
+	successSuppressionWindowField = "successSuppressionWindow"
+	acceptHeaderField             = "acceptHeader"
+	doNotCloseLabelField          = "doNotCloseLabel"
+	closeTemplateField            = "closeTemplate"
+	recordSuccessField            = "recordSuccessAsClosedIssue"
+	successTemplateField          = "successTemplate"
+	notifyOnTransitionOnlyField   = "notifyOnTransitionOnly"
+	overwriteSubstitutionsField   = "overwriteSubstitutions"
+	committerSourceField          = "committerSource"
+	targetField                   = "target"
+	checkRunNameField             = "checkRunName"
+	checkRunAnnotationsField      = "checkRunAnnotations"
+	autoCloseField                = "autoClose"
+	projectField                  = "project"
+	skipCommitterLookupField      = "skipCommitterLookup"
+	committerCacheTTLField        = "committerCacheTTL"
+	logTailLinesField             = "logTailLines"
+	statusContextField            = "statusContext"
+	maxAttemptsField              = "maxAttempts"
+	labelsOnCloseField            = "labelsOnClose"
+	removeLabelsOnCloseField      = "removeLabelsOnClose"
+	branchLabelRulesField         = "branchLabelRules"
+	ownershipRulesField           = "ownershipRules"
+	statusLabelsField             = "statusLabels"
+	retryBackoffField             = "retryBackoff"
+	maxRetryWaitField             = "maxRetryWait"
+	retryBudgetField              = "retryBudget"
+	idempotentCreateField         = "idempotentCreate"
+	firstFailureOnlyField         = "firstFailureOnly"
+	maxEventAgeSecondsField       = "maxEventAgeSeconds"
+	commentStatusesField          = "commentStatuses"
+	trackingIssueKeyField         = "trackingIssueKey"
+	fallbackRepoField             = "fallbackRepo"
+	dedupeWindowField             = "dedupeWindow"
+	backupTokensField             = "backupTokens"
+	extraFieldsField              = "extraFields"
+	branchesField                 = "branches"
+	suppressCommittersField       = "suppressCommitters"
+	createMissingLabelsField      = "createMissingLabels"
+	sanitizeMarkdownField         = "sanitizeMarkdown"
+	perRepoMinIntervalField       = "perRepoMinInterval"
+	skipSubstitutionField         = "skipSubstitution"
+	requestTimeoutField           = "requestTimeout"
+	createTimeoutField            = "createTimeout"
+	lookupTimeoutField            = "lookupTimeout"
+	closeTimeoutField             = "closeTimeout"
+	logPayloadOnErrorField        = "logPayloadOnError"
+	maxLabelsField                = "maxLabels"
+	maxAssigneesField             = "maxAssignees"
+	onEmptyRenderField            = "onEmptyRender"
+	emptyTitleField               = "emptyTitle"
+	emptyBodyField                = "emptyBody"
+	recoveryTemplateField         = "recoveryTemplate"
+	closeOnRecoveryField          = "closeOnRecovery"
+	triggerTemplatesField         = "triggerTemplates"
+	dedupeFailuresField           = "dedupeFailures"
+	closeFixedIssuesField         = "closeFixedIssues"
+	reopenClosedIssuesField       = "reopenClosedIssues"
+	labelsField                   = "labels"
+	assignCommitterField          = "assignCommitter"
+	fallbackAssigneesField        = "fallbackAssignees"
+	githubAppField                = "githubApp"
+	githubApiUrlField             = "githubApiUrl"
+	titleTemplateField            = "titleTemplate"
+	milestoneField                = "milestone"
+	modeField                     = "mode"
+	defaultAcceptHeader           = "application/vnd.github.v3+json"
+	defaultGitHubAPIURL           = "https://api.github.com"
 )
 
 func main() {
@@ -41,13 +116,141 @@ func main() {
 }
 
 type githubissuesNotifier struct {
-	filter      notifiers.EventFilter
-	tmpl        *template.Template
+	filter notifiers.EventFilter
+	tmpl   *template.Template
+	// titleTmpl, if non-nil, renders the title of every notification in place of the one tmpl renders.
+	titleTmpl   *template.Template
 	githubToken string
-	githubRepo  string
+	// app, if non-nil, authenticates requests as a GitHub App installation instead of with githubToken.
+	app *appTokenSource
+	// apiURL is the base URL of the GitHub REST API, e.g. https://ghe.example.com/api/v3 for GitHub Enterprise Server.
+	apiURL     string
+	githubRepo string
+	// githubRepos, if githubRepo is a list, are the repos that every build is notified to instead of its own.
+	githubRepos []string
+	// backupTokens are failed over to, in order, when GitHub rejects githubToken for issue creation or closing.
+	backupTokens []string
+	// fallbackRepo is notified for builds whose repo can't be determined. It is "" if not configured.
+	fallbackRepo string
+	// acceptHeader is the `Accept` media type sent with every GitHub API request.
+	acceptHeader string
+	// httpClient is used for all GitHub API calls. SetUp defaults it to a tracing client if unset.
+	httpClient *http.Client
+	// state holds dedupe and suppression state. SetUp defaults it to the configured store if unset.
+	state notifiers.StateStore
+	// artifacts makes the artifact URLs of templates' `ArtifactURL` calls.
+	artifacts *notifiers.ArtifactLinker
+	// successCooldown suppresses repeated success notifications for the same repo and branch.
+	successCooldown *cooldown
+	// doNotCloseLabel protects issues carrying it from being auto-closed.
+	doNotCloseLabel string
+	// labelsOnClose are added to, and removeLabelsOnClose removed from, issues as they're auto-closed.
+	labelsOnClose       []string
+	removeLabelsOnClose []string
+	// labels are templates of labels added to every created issue. They're nil if not configured.
+	labels []*template.Template
+	// branchLabelRules add labels to issues by the build's BRANCH_NAME.
+	branchLabelRules []patternRule
+	// statusLabels add labels to issues by the build's status.
+	statusLabels map[cbpb.Build_Status][]string
+	// maxLabels and maxAssignees cap the labels and assignees of created issues.
+	maxLabels    int
+	maxAssignees int
+	// createMissingLabels creates labels that issue creation fails on for not existing, instead of dropping them.
+	createMissingLabels bool
+	// ownershipRules assign failure issues to the owner of the failed build step. They're nil if not configured.
+	ownershipRules []patternRule
+	// triggerTemplates replace tmpl for builds of the trigger they're keyed by, by ID or name. They're nil if not
+	// configured.
+	triggerTemplates map[string]*template.Template
+	// assignCommitter assigns failure issues to the build's committer, if ownershipRules don't assign them.
+	assignCommitter bool
+	// fallbackAssignees are assigned failure issues whose owner isn't known. They're nil if not configured.
+	fallbackAssignees []string
+	// extraFields render fields added to the issue-creation payload, by name. They're nil if not configured.
+	extraFields map[string]*template.Template
+	// sanitizer escapes Markdown in user-controlled values before templates render them. It is nil if not configured.
+	sanitizer *markdownSanitizer
+	// emptyRender handles templates that render a blank title or body.
+	emptyRender *emptyRenderPolicy
+	// closeTmpl renders extra JSON fields for the close PATCH. It is nil if not configured.
+	closeTmpl *template.Template
+	// recordSuccess makes successful builds create an issue that is closed right away, as an audit record.
+	recordSuccess bool
+	// successTmpl renders the issue recorded for a successful build. It is nil if not configured, in which case tmpl is used.
+	successTmpl *template.Template
+	// recovery renders its own template for the first success of a failing branch. It is nil if not configured.
+	recovery *recoveryPolicy
+	// firstFailureOnly suppresses failure issues for branches that already have an open one.
+	firstFailureOnly bool
+	// dedupeFailures comments failures on the open failure issue of their trigger and branch, instead of creating another.
+	dedupeFailures bool
+	// closeFixedIssues closes the open failure issue of a successful build's trigger and branch, instead of creating an
+	// issue for the success.
+	closeFixedIssues bool
+	// reopenClosedIssues reopens the closed failure issue of a failed build's trigger and branch, commenting the failure
+	// on it, instead of creating another.
+	reopenClosedIssues bool
+	// failureIssues remembers the failure issue created for each trigger and branch, by their duplicateMarker.
+	failureIssues idCache
+	// dedupeWindow, if positive, limits the issues that marker searches find to those updated within it.
+	dedupeWindow time.Duration
+	// skipSubstitution lets builds opt out of notifications. It is nil if not configured.
+	skipSubstitution *skipSubstitution
+	// branches are glob patterns, at least one of which BRANCH_NAME must match. They're nil if not configured.
+	branches []string
+	// maxEventAge, if positive, skips events for builds that finished (or, if unfinished, were created) longer ago.
+	maxEventAge time.Duration
+	// transitions is non-nil iff notifications are only sent when a build's status changes.
+	transitions *statusTracker
+	// overwriteSubstitutions allows enrichment to overwrite substitutions that are already set on the build.
+	overwriteSubstitutions bool
+	// suppressCommitters skip builds whose committer matches one of them. They're nil if not configured.
+	suppressCommitters []loginGlob
+	// committerSources are the committerSourceFields tried, in order, to find the committer.
+	committerSources []string
+	// skipCommitterLookup disables the committer lookup, e.g. to save rate limit when templates don't mention them.
+	skipCommitterLookup bool
+	// committers caches looked-up committers. It is nil if caching is disabled.
+	committers *committerCache
+	// target is what the notifier creates for a build; one of the target* constants.
+	target       string
+	checkRunName string
+	checkRuns    idCache
+	// openLog, if checkRunAnnotations is enabled, opens the build's log to annotate its check run from (see
+	// notifiers.BuildLogs.Open).
+	openLog       func(context.Context, *cbpb.Build) (io.ReadCloser, bool, error)
+	statusContext string
+	// enrichers run on every build before it's sent; see Enrichers.
+	enrichers []notifiers.Enricher
+	// commentStatuses are the build statuses that comment on the tracking issue of the build's commit or branch, per
+	// trackingKey, instead of creating an issue. They're nil if not configured.
+	commentStatuses map[cbpb.Build_Status]bool
+	trackingKey     string
+	trackingIssues  idCache
+	// mode is whether builds triggered by a pull request are commented on it instead of getting an issue.
+	mode string
+	// autoClosing decides which created issues are auto-closed, and when. It is nil if not configured.
+	autoClosing *autoClosePolicy
+	// project, if non-nil, is the Projects (v2) project that created issues are added to.
+	project *project
+	// milestone, if non-nil, is the milestone that created issues are added to.
+	milestone *milestone
+	// createPacer spaces out issue creations per repo, per perRepoMinInterval.
+	createPacer *repoPacer
+	// logPayloadOnError appends the sent payload to the errors of failed sends.
+	logPayloadOnError bool
+	// timeouts limit how long each attempt at a GitHub API request may take.
+	timeouts requestTimeouts
+	// retry is the policy for retrying failed GitHub API requests.
+	retry retryPolicy
+	// sleep waits out auto-close delays. It defaults to sleepCtx if nil.
+	sleep func(context.Context, time.Duration) error
 
-	br       notifiers.BindingResolver
-	tmplView *notifiers.TemplateView
+	name string
+	br   notifiers.BindingResolver
+	// sg resolves the secrets that templates render with `{{.Secret "<substitution>"}}`.
+	sg notifiers.SecretGetter
 }
 
 type githubissuesMessage struct {
@@ -56,109 +259,991 @@ type githubissuesMessage struct {
 }
 
 func (g *githubissuesNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, issueTemplate string, sg notifiers.SecretGetter, br notifiers.BindingResolver) error {
+	g.name = notifiers.NotifierName(cfg, g)
+
 	prd, err := notifiers.MakeCELPredicate(cfg.Spec.Notification.Filter)
 	if err != nil {
 		return fmt.Errorf("failed to make a CEL predicate: %w", err)
 	}
 	g.filter = prd
 	g.br = br
+	g.sg = sg
+	if g.httpClient == nil {
+		g.httpClient = notifiers.NewTracingHTTPClient()
+	}
 
-	repo, ok := cfg.Spec.Notification.Delivery["githubRepo"].(string)
-	if !ok {
-		return fmt.Errorf("expected delivery config %v to have string field `githubRepo`", cfg.Spec.Notification.Delivery)
+	switch repo := cfg.Spec.Notification.Delivery["githubRepo"].(type) {
+	case string:
+		g.githubRepo = repo
+	case []interface{}:
+		if g.githubRepos, err = parseRepos(repo); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("expected delivery config %v to have string or list field `githubRepo`", cfg.Spec.Notification.Delivery)
+	}
+
+	if fr, ok := cfg.Spec.Notification.Delivery[fallbackRepoField]; ok {
+		frs, _ := fr.(string)
+		if !validRepo(frs) {
+			return fmt.Errorf("expected delivery config field %q to be an `owner/repo` name, got %v", fallbackRepoField, fr)
+		}
+		g.fallbackRepo = frs
 	}
-	g.githubRepo = repo
 
-	tmpl, err := template.New("issue_template").Parse(issueTemplate)
+	window, err := getDurationField(cfg.Spec.Notification.Delivery, successSuppressionWindowField)
 	if err != nil {
-		return fmt.Errorf("failed to parse issue body template: %w", err)
+		return err
 	}
-	g.tmpl = tmpl
+	if g.state == nil {
+		if g.state, err = notifiers.GetStateStore(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	g.successCooldown = newCooldown(g.state, window)
+
+	if g.artifacts, err = notifiers.GetArtifactLinker(ctx, cfg); err != nil {
+		return err
+	}
+	g.checkRuns = idCache{store: g.state, prefix: "checkrun/"}
+	g.trackingIssues = idCache{store: g.state, prefix: "tracking/"}
+	g.failureIssues = idCache{store: g.state, prefix: "failure/"}
+
+	if g.timeouts, err = parseRequestTimeouts(cfg.Spec.Notification.Delivery); err != nil {
+		return err
+	}
+	if g.logPayloadOnError, err = getBoolField(cfg.Spec.Notification.Delivery, logPayloadOnErrorField); err != nil {
+		return err
+	}
+
+	minInterval, err := getDurationField(cfg.Spec.Notification.Delivery, perRepoMinIntervalField)
+	if err != nil {
+		return err
+	}
+	g.createPacer = newRepoPacer(minInterval)
+
+	g.dedupeWindow = defaultDedupeWindow
+	if _, ok := cfg.Spec.Notification.Delivery[dedupeWindowField]; ok {
+		if g.dedupeWindow, err = getDurationField(cfg.Spec.Notification.Delivery, dedupeWindowField); err != nil {
+			return err
+		}
+	}
+
+	if s, ok := cfg.Spec.Notification.Delivery[skipSubstitutionField]; ok {
+		if g.skipSubstitution, err = parseSkipSubstitution(s); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := cfg.Spec.Notification.Delivery[branchesField]; ok {
+		if g.branches, err = parseBranches(cfg.Spec.Notification.Delivery); err != nil {
+			return err
+		}
+	}
+
+	maxAge, err := getIntField(cfg.Spec.Notification.Delivery, maxEventAgeSecondsField)
+	if err != nil {
+		return err
+	}
+	if maxAge < 0 {
+		return fmt.Errorf("expected delivery config field %q to be non-negative, got %d", maxEventAgeSecondsField, maxAge)
+	}
+	g.maxEventAge = time.Duration(maxAge) * time.Second
 
-	wuRef, err := notifiers.GetSecretRef(cfg.Spec.Notification.Delivery, githubTokenSecretName)
+	g.acceptHeader = defaultAcceptHeader
+	if a, ok := cfg.Spec.Notification.Delivery[acceptHeaderField]; ok {
+		as, ok := a.(string)
+		if !ok || strings.TrimSpace(as) == "" {
+			return fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", acceptHeaderField, a)
+		}
+		g.acceptHeader = as
+	}
+
+	if l, ok := cfg.Spec.Notification.Delivery[doNotCloseLabelField]; ok {
+		ls, ok := l.(string)
+		if !ok || ls == "" {
+			return fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", doNotCloseLabelField, l)
+		}
+		g.doNotCloseLabel = ls
+	}
+
+	g.labelsOnClose, err = getStringListField(cfg.Spec.Notification.Delivery, labelsOnCloseField)
 	if err != nil {
-		return fmt.Errorf("failed to get Secret ref from delivery config (%v) field %q: %w", cfg.Spec.Notification.Delivery, githubTokenSecretName, err)
+		return err
 	}
-	wuResource, err := notifiers.FindSecretResourceName(cfg.Spec.Secrets, wuRef)
+	g.removeLabelsOnClose, err = getStringListField(cfg.Spec.Notification.Delivery, removeLabelsOnCloseField)
 	if err != nil {
-		return fmt.Errorf("failed to find Secret for ref %q: %w", wuRef, err)
+		return err
+	}
+	if t, ok := cfg.Spec.Notification.Delivery[titleTemplateField]; ok {
+		if g.titleTmpl, err = parseTitleTemplate(t); err != nil {
+			return err
+		}
+	}
+	if a, ok := cfg.Spec.Notification.Delivery[autoCloseField]; ok {
+		if g.autoClosing, err = parseAutoClosePolicy(a); err != nil {
+			return err
+		}
+	}
+	if p, ok := cfg.Spec.Notification.Delivery[projectField]; ok {
+		if g.project, err = parseProject(p); err != nil {
+			return err
+		}
 	}
-	wu, err := sg.GetSecret(ctx, wuResource)
+	if m, ok := cfg.Spec.Notification.Delivery[milestoneField]; ok {
+		if g.milestone, err = parseMilestone(m); err != nil {
+			return err
+		}
+	}
+	if l, ok := cfg.Spec.Notification.Delivery[labelsField]; ok {
+		if g.labels, err = parseLabelTemplates(l); err != nil {
+			return err
+		}
+	}
+	if r, ok := cfg.Spec.Notification.Delivery[branchLabelRulesField]; ok {
+		g.branchLabelRules, err = parseBranchLabelRules(r)
+		if err != nil {
+			return err
+		}
+	}
+	if l, ok := cfg.Spec.Notification.Delivery[statusLabelsField]; ok {
+		g.statusLabels, err = parseStatusLabels(l)
+		if err != nil {
+			return err
+		}
+	}
+	if g.maxLabels, err = parseListCap(cfg.Spec.Notification.Delivery, maxLabelsField, githubMaxLabels); err != nil {
+		return err
+	}
+	if g.maxAssignees, err = parseListCap(cfg.Spec.Notification.Delivery, maxAssigneesField, githubMaxAssignees); err != nil {
+		return err
+	}
+	g.createMissingLabels, err = getBoolField(cfg.Spec.Notification.Delivery, createMissingLabelsField)
+	if err != nil {
+		return err
+	}
+	if r, ok := cfg.Spec.Notification.Delivery[ownershipRulesField]; ok {
+		g.ownershipRules, err = parseOwnershipRules(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tt, ok := cfg.Spec.Notification.Delivery[triggerTemplatesField]; ok {
+		if g.triggerTemplates, err = parseTriggerTemplates(tt); err != nil {
+			return err
+		}
+	}
+	if g.assignCommitter, err = getBoolField(cfg.Spec.Notification.Delivery, assignCommitterField); err != nil {
+		return err
+	}
+	if g.fallbackAssignees, err = getStringListField(cfg.Spec.Notification.Delivery, fallbackAssigneesField); err != nil {
+		return err
+	}
+	if g.fallbackAssignees != nil && g.ownershipRules == nil && !g.assignCommitter {
+		return fmt.Errorf("delivery config field %q requires %q to be true or %q to be set", fallbackAssigneesField, assignCommitterField, ownershipRulesField)
+	}
+
+	if ef, ok := cfg.Spec.Notification.Delivery[extraFieldsField]; ok {
+		if g.extraFields, err = parseExtraFields(ef); err != nil {
+			return err
+		}
+	}
+	if sm, ok := cfg.Spec.Notification.Delivery[sanitizeMarkdownField]; ok {
+		if g.sanitizer, err = parseSanitizeMarkdown(sm); err != nil {
+			return err
+		}
+	}
+	if g.emptyRender, err = parseEmptyRenderPolicy(cfg.Spec.Notification.Delivery); err != nil {
+		return err
+	}
+	if c, ok := cfg.Spec.Notification.Delivery[closeTemplateField]; ok {
+		cs, ok := c.(string)
+		if !ok {
+			return fmt.Errorf("expected delivery config field %q to be a string, got %v", closeTemplateField, c)
+		}
+		g.closeTmpl, err = template.New("close_template").Funcs(notifiers.TemplateFuncs()).Parse(cs)
+		if err != nil {
+			return fmt.Errorf("failed to parse close template: %w", err)
+		}
+	}
+
+	g.recordSuccess, err = getBoolField(cfg.Spec.Notification.Delivery, recordSuccessField)
 	if err != nil {
-		return fmt.Errorf("failed to get token secret: %w", err)
+		return err
+	}
+	if st, ok := cfg.Spec.Notification.Delivery[successTemplateField]; ok {
+		sts, ok := st.(string)
+		if !ok || sts == "" {
+			return fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", successTemplateField, st)
+		}
+		if !g.recordSuccess {
+			return fmt.Errorf("delivery config field %q requires %q to be true", successTemplateField, recordSuccessField)
+		}
+		g.successTmpl, err = template.New("success_template").Funcs(notifiers.TemplateFuncs()).Parse(sts)
+		if err != nil {
+			return fmt.Errorf("failed to parse success template: %w", err)
+		}
+	}
+
+	transitionOnly, err := getBoolField(cfg.Spec.Notification.Delivery, notifyOnTransitionOnlyField)
+	if err != nil {
+		return err
+	}
+	if transitionOnly {
+		g.transitions = newStatusTracker(g.state)
+	}
+
+	g.firstFailureOnly, err = getBoolField(cfg.Spec.Notification.Delivery, firstFailureOnlyField)
+	if err != nil {
+		return err
+	}
+
+	g.dedupeFailures, err = getBoolField(cfg.Spec.Notification.Delivery, dedupeFailuresField)
+	if err != nil {
+		return err
+	}
+	g.closeFixedIssues, err = getBoolField(cfg.Spec.Notification.Delivery, closeFixedIssuesField)
+	if err != nil {
+		return err
+	}
+	g.reopenClosedIssues, err = getBoolField(cfg.Spec.Notification.Delivery, reopenClosedIssuesField)
+	if err != nil {
+		return err
+	}
+	if g.dedupeFailures && g.firstFailureOnly {
+		return fmt.Errorf("delivery config fields %q and %q can't both be true", dedupeFailuresField, firstFailureOnlyField)
+	}
+
+	g.overwriteSubstitutions, err = getBoolField(cfg.Spec.Notification.Delivery, overwriteSubstitutionsField)
+	if err != nil {
+		return err
+	}
+
+	g.committerSources = defaultCommitterSources
+	if _, ok := cfg.Spec.Notification.Delivery[committerSourceField]; ok {
+		g.committerSources, err = getStringListField(cfg.Spec.Notification.Delivery, committerSourceField)
+		if err != nil {
+			return err
+		}
+		if len(g.committerSources) == 0 {
+			return fmt.Errorf("expected delivery config field %q to be a non-empty list", committerSourceField)
+		}
+		for _, src := range g.committerSources {
+			if _, ok := committerSourceFields[src]; !ok {
+				return fmt.Errorf("expected delivery config field %q to contain only %s, got %q", committerSourceField, strings.Join(supportedCommitterSources(), ", "), src)
+			}
+		}
+	}
+
+	if _, ok := cfg.Spec.Notification.Delivery[suppressCommittersField]; ok {
+		if g.suppressCommitters, err = parseSuppressCommitters(cfg.Spec.Notification.Delivery); err != nil {
+			return err
+		}
+	}
+	if g.skipCommitterLookup, err = getBoolField(cfg.Spec.Notification.Delivery, skipCommitterLookupField); err != nil {
+		return err
+	}
+	if g.skipCommitterLookup {
+		if g.suppressCommitters != nil || g.assignCommitter {
+			return fmt.Errorf("delivery config field %q can't be true with %q or %q, which need the committer", skipCommitterLookupField, suppressCommittersField, assignCommitterField)
+		}
+	}
+	committerCacheTTL := defaultCommitterCacheTTL
+	if _, ok := cfg.Spec.Notification.Delivery[committerCacheTTLField]; ok {
+		if committerCacheTTL, err = getDurationField(cfg.Spec.Notification.Delivery, committerCacheTTLField); err != nil {
+			return err
+		}
+	}
+	if committerCacheTTL > 0 {
+		g.committers = &committerCache{ttl: committerCacheTTL}
+	}
+
+	g.target = targetIssue
+	if t, ok := cfg.Spec.Notification.Delivery[targetField]; ok {
+		switch t {
+		case targetIssue, targetCheckRun, targetCommitStatus:
+			g.target = t.(string)
+		default:
+			return fmt.Errorf("expected delivery config field %q to be one of %q, %q, or %q, got %v", targetField, targetIssue, targetCheckRun, targetCommitStatus, t)
+		}
+	}
+	g.checkRunName = defaultCheckRunName
+	if n, ok := cfg.Spec.Notification.Delivery[checkRunNameField]; ok {
+		ns, ok := n.(string)
+		if !ok || ns == "" {
+			return fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", checkRunNameField, n)
+		}
+		g.checkRunName = ns
+	}
+	annotate, err := getBoolField(cfg.Spec.Notification.Delivery, checkRunAnnotationsField)
+	if err != nil {
+		return err
+	}
+	if annotate {
+		if g.target != targetCheckRun {
+			return fmt.Errorf("delivery config field %q requires %q to be %q", checkRunAnnotationsField, targetField, targetCheckRun)
+		}
+		logs, err := notifiers.NewBuildLogs(ctx)
+		if err != nil {
+			return err
+		}
+		g.openLog = logs.Open
+	}
+	if l, ok := cfg.Spec.Notification.Delivery[logTailLinesField]; ok {
+		lines, ok := l.(int)
+		if !ok || lines < 1 {
+			return fmt.Errorf("expected delivery config field %q to be a positive integer, got %v", logTailLinesField, l)
+		}
+		lt, err := notifiers.NewLogTailEnricher(ctx, lines)
+		if err != nil {
+			return fmt.Errorf("failed to create log tail enricher: %w", err)
+		}
+		lt.FailedStep = true
+		g.enrichers = append(g.enrichers, lt)
+	}
+	g.statusContext = defaultStatusContext
+	if c, ok := cfg.Spec.Notification.Delivery[statusContextField]; ok {
+		cs, ok := c.(string)
+		if !ok || cs == "" {
+			return fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", statusContextField, c)
+		}
+		g.statusContext = cs
+	}
+
+	if g.mode, err = parseMode(cfg.Spec.Notification.Delivery); err != nil {
+		return err
+	}
+	if g.mode != modeIssue && g.target != targetIssue {
+		return fmt.Errorf("delivery config field %q requires %q to be %q", modeField, targetField, targetIssue)
+	}
+
+	if g.recovery, err = parseRecoveryPolicy(cfg.Spec.Notification.Delivery, g.state); err != nil {
+		return err
+	}
+	if g.recovery != nil && g.target != targetIssue {
+		return fmt.Errorf("delivery config field %q requires %q to be %q", recoveryTemplateField, targetField, targetIssue)
+	}
+	// Each of these closes the failure issues of a successful build, so together they'd close and comment on them twice.
+	if g.closeFixedIssues && g.firstFailureOnly {
+		return fmt.Errorf("delivery config fields %q and %q can't both be true", closeFixedIssuesField, firstFailureOnlyField)
+	}
+	if g.closeFixedIssues && g.recovery != nil && g.recovery.closeFailures {
+		return fmt.Errorf("delivery config fields %q and %q can't both be true", closeFixedIssuesField, closeOnRecoveryField)
+	}
+
+	if s, ok := cfg.Spec.Notification.Delivery[commentStatusesField]; ok {
+		if g.target != targetIssue {
+			return fmt.Errorf("delivery config field %q requires %q to be %q", commentStatusesField, targetField, targetIssue)
+		}
+		g.commentStatuses, err = parseCommentStatuses(s)
+		if err != nil {
+			return err
+		}
+	}
+	g.trackingKey = trackingKeyCommit
+	if k, ok := cfg.Spec.Notification.Delivery[trackingIssueKeyField]; ok {
+		switch k {
+		case trackingKeyCommit, trackingKeyBranch:
+			g.trackingKey = k.(string)
+		default:
+			return fmt.Errorf("expected delivery config field %q to be one of %q or %q, got %v", trackingIssueKeyField, trackingKeyCommit, trackingKeyBranch, k)
+		}
+	}
+
+	g.retry.maxAttempts = defaultMaxAttempts
+	if _, ok := cfg.Spec.Notification.Delivery[maxAttemptsField]; ok {
+		g.retry.maxAttempts, err = getIntField(cfg.Spec.Notification.Delivery, maxAttemptsField)
+		if err != nil {
+			return err
+		}
+		if g.retry.maxAttempts < 1 {
+			return fmt.Errorf("expected delivery config field %q to be at least 1, got %d", maxAttemptsField, g.retry.maxAttempts)
+		}
+	}
+	g.retry.backoff = defaultRetryBackoff
+	if _, ok := cfg.Spec.Notification.Delivery[retryBackoffField]; ok {
+		g.retry.backoff, err = getDurationField(cfg.Spec.Notification.Delivery, retryBackoffField)
+		if err != nil {
+			return err
+		}
+	}
+	g.retry.maxRetryWait = defaultMaxRetryWait
+	if _, ok := cfg.Spec.Notification.Delivery[maxRetryWaitField]; ok {
+		g.retry.maxRetryWait, err = getDurationField(cfg.Spec.Notification.Delivery, maxRetryWaitField)
+		if err != nil {
+			return err
+		}
+	}
+	if _, ok := cfg.Spec.Notification.Delivery[retryBudgetField]; ok {
+		g.retry.budget, err = getIntField(cfg.Spec.Notification.Delivery, retryBudgetField)
+		if err != nil {
+			return err
+		}
+		if g.retry.budget < 1 {
+			return fmt.Errorf("expected delivery config field %q to be at least 1, got %d", retryBudgetField, g.retry.budget)
+		}
+	}
+	g.retry.retryCreates, err = getBoolField(cfg.Spec.Notification.Delivery, idempotentCreateField)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("issue_template").Funcs(notifiers.TemplateFuncs()).Parse(issueTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse issue body template: %w", err)
+	}
+	g.tmpl = tmpl
+
+	if g.apiURL, err = parseAPIURL(cfg.Spec.Notification.Delivery); err != nil {
+		return err
+	}
+
+	if _, ok := cfg.Spec.Notification.Delivery[githubAppField]; ok {
+		if _, ok := cfg.Spec.Notification.Delivery[githubTokenSecretName]; ok {
+			return fmt.Errorf("delivery config fields %q and %q can't both be set", githubAppField, githubTokenSecretName)
+		}
+		if g.app, err = parseGitHubApp(ctx, cfg, sg, g.httpClient, g.apiURL); err != nil {
+			return err
+		}
+	} else {
+		refs, err := notifiers.GetSecretRefs(cfg.Spec.Notification.Delivery, githubTokenSecretName)
+		if err != nil {
+			return fmt.Errorf("failed to get Secret refs from delivery config: %w", err)
+		}
+		secrets, err := notifiers.GetSecrets(ctx, sg, cfg.Spec.Secrets, refs)
+		if err != nil {
+			return err
+		}
+		g.githubToken = secrets[githubTokenSecretName]
+	}
+	if g.backupTokens, err = getBackupTokens(ctx, cfg, sg); err != nil {
+		return err
 	}
-	g.githubToken = wu
 
 	return nil
 }
 
+// Enrichers implements notifiers.EnrichingNotifier.
+func (g *githubissuesNotifier) Enrichers() []notifiers.Enricher {
+	return g.enrichers
+}
+
 func (g *githubissuesNotifier) SendNotification(ctx context.Context, build *cbpb.Build) error {
 	if !g.filter.Apply(ctx, build) {
 		log.V(2).Infof("not sending response for event (build id = %s, status = %v)", build.Id, build.Status)
+		logSummary(build, "", skipped(notifiers.FilterReasonCEL))
 		return nil
 	}
 
+	if g.skipSubstitution != nil && g.skipSubstitution.skips(build) {
+		log.Infof("not sending response for event (build id = %s, status = %v): the build opted out with substitution %q", build.Id, build.Status, g.skipSubstitution.key)
+		logSummary(build, "", skipped(notifiers.FilterReasonOptOut))
+		return nil
+	}
+
+	if g.branches != nil && !g.branchAllowed(build) {
+		logSummary(build, "", skipped(notifiers.FilterReasonBranch))
+		return nil
+	}
+
+	if age, ok := buildAge(build, time.Now()); ok && g.maxEventAge > 0 && age > g.maxEventAge {
+		log.Infof("not sending response for event (build id = %s, status = %v): build is %v old, more than %q allows", build.Id, build.Status, age.Round(time.Second), maxEventAgeSecondsField)
+		logSummary(build, "", skipped(notifiers.FilterReasonStale))
+		return nil
+	}
+
+	if g.transitions != nil && !g.transitions.transitioned(ctx, build.Id, build.Status) {
+		log.V(2).Infof("not sending response for event (build id = %s, status = %v): status has not changed", build.Id, build.Status)
+		logSummary(build, "", skipped(notifiers.FilterReasonSuppressed))
+		return nil
+	}
+
+	if err := g.notify(ctx, build); err != nil {
+		return err
+	}
+	if g.transitions != nil {
+		g.transitions.record(ctx, build.Id, build.Status)
+	}
+	return nil
+}
+
+// notify sends the notification of the build to its repo, or to each of the configured repos.
+func (g *githubissuesNotifier) notify(ctx context.Context, build *cbpb.Build) error {
+	if len(g.githubRepos) > 0 {
+		return g.fanOut(ctx, build)
+	}
 	repo := GetGithubRepo(build)
+	if repo == "" && g.fallbackRepo != "" {
+		log.Infof("could not determine GitHub repository from Build %q, using fallback repo %q", build.Id, g.fallbackRepo)
+		repo = g.fallbackRepo
+	}
 	if repo == "" {
 		log.Warningf("could not determine GitHub repository from build, skipping notification")
+		logSummary(build, "", skipped(notifiers.FilterReasonNoRepo))
+		return nil
+	}
+	return g.notifyRepo(ctx, build, repo)
+}
+
+// notifyRepo sends the notification of the build to the repo, and logs its summary line.
+func (g *githubissuesNotifier) notifyRepo(ctx context.Context, build *cbpb.Build, repo string) (err error) {
+	var action, cooldownKey string
+	var recordOutcome bool
+	defer func() {
+		if err != nil {
+			action = actionError
+			if permanent(err) {
+				err = notifiers.Permanent(err)
+			}
+		} else {
+			// State that holds back later notifications is only recorded once this one was sent.
+			if cooldownKey != "" {
+				g.successCooldown.record(ctx, cooldownKey)
+			}
+			if recordOutcome {
+				g.recovery.record(ctx, build, repo)
+			}
+		}
+		logSummary(build, repo, action)
+	}()
+
+	ctx = g.retry.withBudget(ctx)
+	// Suppression by committer needs the committer first, before other suppression settings record the build.
+	if g.suppressCommitters != nil {
+		g.GetAndSetCommitterInfo(ctx, build, repo)
+		if glob, ok := g.suppressingCommitterGlob(build); ok {
+			log.Infof("suppressing notification for Build %q: committer %q matches %q", build.Id, build.Substitutions[committerLoginSubst], glob)
+			action = skipped(notifiers.FilterReasonSuppressed)
+			return nil
+		}
+	}
+	pr := g.pullRequest(build, repo)
+	if g.mode == modePRComment && pr == 0 {
+		log.Infof("not notifying Build %q: it has no pull request in %q to comment on", build.Id, repo)
+		action = skipped(notifiers.FilterReasonNoRef)
 		return nil
 	}
-	webhookURL := fmt.Sprintf("%s/%s/issues", githubApiEndpoint, repo)
+	// Builds commented on their pull request don't get, and so don't close or suppress, issues.
+	issues := g.target == targetIssue && pr == 0
+	recordOutcome = issues && g.recovery != nil
+	recovered := recordOutcome && g.recovery.recovered(ctx, build, repo)
+	// Recoveries that close the failure issues comment on them first, so firstFailureOnly leaves them to the recovery.
+	if issues && g.firstFailureOnly && !(recovered && g.recovery.closeFailures) {
+		if branch := build.Substitutions["BRANCH_NAME"]; branch == "" {
+			log.Warningf("Build %q has no BRANCH_NAME, so firstFailureOnly can't apply to it", build.Id)
+		} else if !g.firstFailure(ctx, build, repo, branch) {
+			action = skipped(notifiers.FilterReasonSuppressed)
+			return nil
+		}
+	}
+	if issues && g.closeFixedIssues && build.Status == cbpb.Build_SUCCESS {
+		closed, err := g.closeFixedFailures(ctx, build, repo)
+		if err != nil || closed {
+			action = actionComment
+			return err
+		}
+	}
+	if issues && build.Status == cbpb.Build_SUCCESS && !recovered {
+		key := repo + "@" + build.Substitutions["BRANCH_NAME"]
+		if !g.successCooldown.allow(ctx, key) {
+			log.Infof("suppressing success notification for Build %q: %q was notified within the last %v", build.Id, key, g.successCooldown.window)
+			action = skipped(notifiers.FilterReasonSuppressed)
+			return nil
+		}
+		cooldownKey = key
+	}
 
-	log.Infof("sending GitHub Issue webhook for Build %q (status: %q) to url %q", build.Id, build.Status, webhookURL)
+	log.Infof("sending GitHub %s in %q for Build %q (status: %q)", g.target, repo, build.Id, build.Status)
+
+	if g.suppressCommitters == nil {
+		g.GetAndSetCommitterInfo(ctx, build, repo)
+	}
+
+	action = g.target
+	if pr != 0 || (issues && g.commentStatuses[build.Status]) {
+		action = actionComment
+	}
 
 	bindings, err := g.br.Resolve(ctx, nil, build)
 	if err != nil {
 		log.Errorf("failed to resolve bindings :%v", err)
 	}
-	g.tmplView = &notifiers.TemplateView{
-		Build:  &notifiers.BuildView{Build: build},
-		Params: bindings,
+	view := &notifiers.TemplateView{
+		Build:          &notifiers.BuildView{Build: build},
+		Params:         bindings,
+		NotifierName:   g.name,
+		ArtifactLinker: g.artifacts,
 	}
 	logURL, err := notifiers.AddUTMParams(build.LogUrl, notifiers.HTTPMedium)
 	if err != nil {
 		return fmt.Errorf("failed to add UTM params: %w", err)
 	}
 	build.LogUrl = logURL
+	view.MaskSecretRefs(g.sg)
+	view = g.sanitizer.view(view)
 
-	payload := new(bytes.Buffer)
+	tmpl := g.issueTemplate(build)
+	switch {
+	case recovered:
+		log.Infof("Build %q recovered branch %q in %q", build.Id, build.Substitutions["BRANCH_NAME"], repo)
+		tmpl = g.recovery.tmpl
+	case g.recordsSuccess(build) && g.successTmpl != nil:
+		tmpl = g.successTmpl
+	}
 	var buf bytes.Buffer
-	if err := g.tmpl.Execute(&buf, g.tmplView); err != nil {
+	if err := notifiers.ExecuteTemplate(tmpl, &buf, view); err != nil {
 		return err
 	}
-	err = json.NewEncoder(payload).Encode(buf)
+
+	rendered, err := g.renderTitle(buf.Bytes(), view)
 	if err != nil {
-		return fmt.Errorf("failed to encode payload: %w", err)
+		return err
+	}
+	rendered = sanitizeRendered(rendered)
+	switch g.target {
+	case targetCheckRun:
+		return g.sendCheckRun(ctx, build, repo, rendered)
+	case targetCommitStatus:
+		return g.sendCommitStatus(ctx, build, repo, rendered)
+	}
+	rendered, ok := g.emptyRender.apply(build.Id, rendered)
+	if !ok {
+		notifiers.RecordFiltered(notifiers.FilterReasonEmpty)
+		return nil
+	}
+	if pr != 0 {
+		return g.commentOnPullRequest(ctx, build, repo, pr, rendered)
+	}
+	if recovered && g.recovery.closeFailures {
+		closed, err := g.closeRecoveredFailures(ctx, build, repo, rendered, view)
+		if err != nil || closed {
+			action = actionComment
+			return err
+		}
+	}
+	if g.dedupeFailures && failed(build.Status) && !g.commentStatuses[build.Status] {
+		commented, err := g.commentOnDuplicate(ctx, build, repo, rendered)
+		if err != nil || commented {
+			action = actionComment
+			return err
+		}
 	}
+	if g.reopenClosedIssues && failed(build.Status) && !g.commentStatuses[build.Status] {
+		reopened, err := g.reopenFailureIssue(ctx, build, repo, rendered)
+		if err != nil || reopened {
+			action = actionComment
+			return err
+		}
+	}
+	if g.commentStatuses[build.Status] {
+		return g.sendTrackingComment(ctx, build, repo, rendered)
+	}
+	return g.sendIssue(ctx, build, repo, rendered, view)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, strings.NewReader(buf.String()))
+// Summary actions other than the target and "skipped:<filter reason>".
+const (
+	actionComment = "comment"
+	actionError   = "error"
+)
+
+// skipped records that the event was filtered for the given reason and returns the summary action for it.
+func skipped(reason string) string {
+	notifiers.RecordFiltered(reason)
+	return "skipped:" + reason
+}
+
+// logSummary logs a one-line summary of the handling of a build event, in a fixed format that log-based metrics can
+// parse. The action is the target notified (issue, checkRun, commitStatus, or comment), skipped:<filter reason>, or
+// error. Empty values are logged as "-".
+func logSummary(build *cbpb.Build, repo, action string) {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	log.Infof("NOTIFY build=%s status=%s repo=%s action=%s", orDash(build.Id), build.Status, orDash(repo), orDash(action))
+}
+
+// sendIssue creates an issue from the rendered issue template and auto-closes it for successful builds, rendering the
+// close template over the given view.
+func (g *githubissuesNotifier) sendIssue(ctx context.Context, build *cbpb.Build, repo string, rendered []byte, view *notifiers.TemplateView) error {
+	if g.extraFields != nil {
+		var err error
+		if rendered, err = g.mergeExtraFields(rendered, view); err != nil {
+			return err
+		}
+	}
+	if branch := build.Substitutions["BRANCH_NAME"]; g.marksFailures() && branch != "" && failed(build.Status) {
+		var err error
+		rendered, err = embedMarker(rendered, branchFailureMarker(repo, branch))
+		if err != nil {
+			return err
+		}
+	}
+	duplicate := ""
+	if marker := duplicateMarker(repo, build); g.marksDuplicates() && marker != "" && failed(build.Status) {
+		var err error
+		if rendered, err = embedMarker(rendered, marker); err != nil {
+			return err
+		}
+		duplicate = marker
+	}
+	labels, err := g.renderLabels(view)
 	if err != nil {
-		return fmt.Errorf("failed to create a new HTTP request: %w", err)
+		return err
+	}
+	labels = append(labels, g.statusLabels[build.Status]...)
+	labels = append(labels, branchLabels(g.branchLabelRules, build.Substitutions["BRANCH_NAME"])...)
+	if len(labels) > 0 {
+		rendered, err = mergeLabels(rendered, labels)
+		if err != nil {
+			return err
+		}
+	}
+	if assignees := g.assignees(build); len(assignees) > 0 && failed(build.Status) {
+		if rendered, err = mergeListField(rendered, "assignees", assignees); err != nil {
+			return err
+		}
 	}
 
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", g.githubToken))
-	req.Header.Set("User-Agent", "GCB-Notifier/0.1 (http)")
+	if rendered, err = g.setMilestone(ctx, repo, rendered); err != nil {
+		return err
+	}
+	rendered, err = g.capListFields(build.Id, rendered)
+	if err != nil {
+		return err
+	}
+
+	// Only creates retried after being sent need the marker to recognize an issue that an earlier attempt created.
+	marker := ""
+	if g.retry.retryCreates {
+		if marker, err = renderedCreateMarker(repo, build.Id, rendered); err != nil {
+			return err
+		}
+	}
+	if rendered, err = embedMarker(rendered, marker); err != nil {
+		return err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	g.logPayload(build, "issue", rendered)
+	iss, err := g.createMarkedIssue(ctx, repo, rendered, marker)
 	if err != nil {
-		return fmt.Errorf("failed to make HTTP request: %w", err)
+		return fmt.Errorf("failed to create issue: %w%s", err, g.errorPayload("issue", rendered))
+	}
+	log.V(2).Infof("created issue #%d in %q", iss.Number, repo)
+	g.addToProject(ctx, repo, iss)
+	if duplicate != "" {
+		g.failureIssues.put(ctx, duplicate, int64(iss.Number))
+	}
+	if marker := g.reopenMarker(repo, build); g.reopenClosedIssues && marker != "" && marker != duplicate && failed(build.Status) {
+		g.failureIssues.put(ctx, marker, int64(iss.Number))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Warningf("got a non-OK response status %q (%d) from %q", resp.Status, resp.StatusCode, webhookURL)
+	switch {
+	case g.recordsSuccess(build):
+		if err := g.closeIssue(ctx, iss, view); err != nil {
+			log.Warningf("failed to close success record issue #%d in %q: %v", iss.Number, repo, err)
+			return nil
+		}
+		log.Infof("recorded success of Build %q as closed issue #%d in %q", build.Id, iss.Number, repo)
+	case g.autoClosing.closes(repo, build.Status):
+		if err := g.autoClose(ctx, repo, iss, view); err != nil {
+			log.Warningf("failed to auto-close issue: %v", err)
+		}
 	}
 
-	log.V(2).Infoln("send HTTP request successfully")
 	return nil
 }
 
+// recordsSuccess returns true iff the build is successful and the notifier records successes as closed issues.
+func (g *githubissuesNotifier) recordsSuccess(build *cbpb.Build) bool {
+	return g.recordSuccess && build.Status == cbpb.Build_SUCCESS
+}
+
+// GetGithubRepo returns the `owner/repo` full name of the GitHub repository the build ran against, or "" if it cannot be
+// determined. The REPO_FULL_NAME substitution takes precedence over the build's source, which takes precedence over the
+// repo source that Cloud Build resolved it to. Storage sources carry no repo.
 func GetGithubRepo(build *cbpb.Build) string {
 	if build.Substitutions != nil && build.Substitutions["REPO_FULL_NAME"] != "" {
 		// return repo full name if it's available
 		// e.g. "GoogleCloudPlatform/cloud-build-notifiers"
 		return build.Substitutions["REPO_FULL_NAME"]
 	}
+	if gs := build.GetSource().GetGitSource(); gs != nil {
+		return repoFromGitURL(gs.Url)
+	}
+	if rs := build.GetSource().GetRepoSource(); rs != nil {
+		return repoFromRepoName(rs.RepoName)
+	}
+	if rs := build.GetSourceProvenance().GetResolvedRepoSource(); rs != nil {
+		return repoFromRepoName(rs.RepoName)
+	}
 	return ""
 }
+
+// repoFromGitURL returns `owner/repo` from a git remote URL in any of the following forms, or "" if it has no owner and
+// repo path segments:
+//   - https://github.com/owner/repo(.git)
+//   - git@github.com:owner/repo(.git)
+//   - ssh://git@github.com/owner/repo(.git)
+func repoFromGitURL(u string) string {
+	var path string
+	if i := strings.Index(u, "://"); i >= 0 {
+		rest := u[i+len("://"):]
+		j := strings.Index(rest, "/")
+		if j < 0 {
+			return ""
+		}
+		path = rest[j+1:]
+	} else if i := strings.Index(u, ":"); i >= 0 {
+		// scp-like syntax, e.g. git@github.com:owner/repo.git.
+		path = u[i+1:]
+	} else {
+		return ""
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return ""
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+}
+
+// repoFromRepoName returns `owner/repo` from a Cloud Source Repositories repo name. Either the name is already of the form
+// `owner/repo` or it is a GitHub mirror named `github_owner_repo`. GitHub owners cannot contain underscores, so the first
+// underscore after the prefix separates the owner from the repo.
+func repoFromRepoName(name string) string {
+	if strings.Count(name, "/") == 1 && !strings.HasPrefix(name, "/") && !strings.HasSuffix(name, "/") {
+		return name
+	}
+	mirrored := strings.TrimPrefix(name, "github_")
+	if mirrored == name {
+		return ""
+	}
+	parts := strings.SplitN(mirrored, "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// redactedSecret replaces secret values in logged payloads.
+const redactedSecret = "[REDACTED]"
+
+// logPayload logs the payload about to be sent for the build at V(3), so that raising the log level shows exactly what
+// the templates produce. The GitHub tokens are redacted in case a template leaks them.
+func (g *githubissuesNotifier) logPayload(build *cbpb.Build, kind string, payload []byte) {
+	if !log.V(3) {
+		return
+	}
+	log.Infof("sending %s for Build %q: %s", kind, build.Id, g.redact(payload))
+}
+
+// errorPayload returns a suffix for the error of a failed send of the payload that shows the payload, with the GitHub
+// tokens redacted, if logPayloadOnError is set, and "" otherwise. Appending it to the error keeps the payload on the
+// same log line as the failure, without logging payloads of successful sends.
+func (g *githubissuesNotifier) errorPayload(kind string, payload []byte) string {
+	if !g.logPayloadOnError {
+		return ""
+	}
+	return fmt.Sprintf(" (sent %s: %s)", kind, g.redact(payload))
+}
+
+// redact returns the payload with the GitHub tokens replaced by redactedSecret.
+func (g *githubissuesNotifier) redact(payload []byte) string {
+	p := string(payload)
+	tokens := append([]string{g.githubToken}, g.backupTokens...)
+	if g.app != nil {
+		tokens = append(tokens, g.app.current())
+	}
+	for _, token := range tokens {
+		if token != "" {
+			p = strings.ReplaceAll(p, token, redactedSecret)
+		}
+	}
+	return p
+}
+
+// buildAge returns how long ago the build finished or, if it hasn't, was created, and false if it has neither time.
+func buildAge(build *cbpb.Build, now time.Time) (time.Duration, bool) {
+	t := build.GetFinishTime()
+	if t == nil {
+		t = build.GetCreateTime()
+	}
+	if t == nil {
+		return 0, false
+	}
+	return now.Sub(t.AsTime()), true
+}
+
+// getDurationField returns the optional non-negative duration string (e.g. "10m") in the given delivery config field,
+// or zero if it is not set.
+func getDurationField(delivery map[string]interface{}, field string) (time.Duration, error) {
+	v, ok := delivery[field]
+	if !ok {
+		return 0, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected delivery config field %q to be a duration string, got %v", field, v)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse delivery config field %q as a duration: %w", field, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("expected delivery config field %q to be non-negative, got %v", field, d)
+	}
+	return d, nil
+}
+
+// getIntField returns the optional integer in the given delivery config field, or 0 if it is not set.
+func getIntField(delivery map[string]interface{}, field string) (int, error) {
+	v, ok := delivery[field]
+	if !ok {
+		return 0, nil
+	}
+	i, ok := v.(int)
+	if !ok {
+		return 0, fmt.Errorf("expected delivery config field %q to be an integer, got %v", field, v)
+	}
+	return i, nil
+}
+
+// getBoolField returns the optional boolean in the given delivery config field, or false if it is not set.
+func getBoolField(delivery map[string]interface{}, field string) (bool, error) {
+	v, ok := delivery[field]
+	if !ok {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected delivery config field %q to be a boolean, got %v", field, v)
+	}
+	return b, nil
+}
+
+// getStringListField returns the optional list of strings in the given delivery config field, or nil if it is not set.
+func getStringListField(delivery map[string]interface{}, field string) ([]string, error) {
+	v, ok := delivery[field]
+	if !ok {
+		return nil, nil
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected delivery config field %q to be a list of strings, got %v", field, v)
+	}
+	var out []string
+	for _, e := range l {
+		s, ok := e.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected delivery config field %q to be a list of strings, got element %v", field, e)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
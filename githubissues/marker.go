@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+const (
+	// markerPrefix starts every marker the notifier embeds in issues.
+	markerPrefix = "cloud-build-notifiers:"
+	// defaultDedupeWindow is how recently an issue must have been updated for marker searches to find it, unless the
+	// `dedupeWindow` delivery config field overrides it.
+	defaultDedupeWindow = 30 * 24 * time.Hour
+)
+
+// newMarker returns the marker of the given kind for the key: markerPrefix, the kind, and a hash of the key. Markers are
+// derived from their key only, so any notifier instance computes the same one, and contain no characters that GitHub's
+// search splits words at, so a search for one matches it exactly.
+func newMarker(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return markerPrefix + kind + ":" + hex.EncodeToString(sum[:8])
+}
+
+// branchFailureMarker returns the marker embedded in failure issues for the repo's branch, so later builds can find
+// them.
+func branchFailureMarker(repo, branch string) string {
+	return newMarker("failure", repo+"@"+branch)
+}
+
+// createMarker returns the idempotency marker embedded in the issue created for the build with the given title, so a
+// create whose response was lost can be recognized before it's retried.
+func createMarker(repo, buildID, title string) string {
+	return newMarker("create", repo+"@"+buildID+"\n"+title)
+}
+
+// renderedCreateMarker returns the createMarker of the build's rendered issue.
+func renderedCreateMarker(repo, buildID string, rendered []byte) (string, error) {
+	var ri renderedIssue
+	if err := json.Unmarshal(rendered, &ri); err != nil {
+		return "", fmt.Errorf("failed to decode rendered template as an issue title and body: %w", err)
+	}
+	return createMarker(repo, buildID, ri.Title), nil
+}
+
+// embedMarker appends the marker as an HTML comment, which GitHub doesn't display, to the body of the rendered issue.
+// An empty marker leaves the body as is.
+func embedMarker(rendered []byte, marker string) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to embed a marker in, got %q", rendered)
+	}
+	if marker != "" {
+		body, _ := fields["body"].(string)
+		fields["body"] = strings.TrimRight(body, "\n") + "\n\n<!-- " + marker + " -->"
+	}
+	return json.Marshal(fields)
+}
+
+// markerQuery returns the issue search query for the repo's issues whose body contains the marker, optionally only the
+// open ones, and, unless since is zero, only those updated since then.
+func markerQuery(repo, marker string, openOnly bool, since time.Time) string {
+	q := fmt.Sprintf(`repo:%s is:issue in:body "%s"`, repo, marker)
+	if openOnly {
+		q += " is:open"
+	}
+	if !since.IsZero() {
+		q += " updated:>=" + since.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return q
+}
+
+// findIssues returns the repo's issues, optionally only the open ones, whose body contains the marker. Only issues
+// updated within the dedupe window count. GitHub's search index lags behind writes, so issues created moments ago may
+// be missing.
+func (g *githubissuesNotifier) findIssues(ctx context.Context, repo, marker string, openOnly bool) ([]*issue, error) {
+	var res struct {
+		Items []*issue `json:"items"`
+	}
+	var since time.Time
+	if g.dedupeWindow > 0 {
+		since = time.Now().Add(-g.dedupeWindow)
+	}
+	q := markerQuery(repo, marker, openOnly, since)
+	if err := g.doRequest(ctx, http.MethodGet, g.apiURL+"/search/issues?q="+url.QueryEscape(q), nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	// Search matches words, not exact strings, so double-check.
+	var found []*issue
+	for _, iss := range res.Items {
+		if strings.Contains(iss.Body, marker) {
+			found = append(found, iss)
+		}
+	}
+	return found, nil
+}
+
+// findOpenIssues returns the repo's open issues whose body contains the marker.
+func (g *githubissuesNotifier) findOpenIssues(ctx context.Context, repo, marker string) ([]*issue, error) {
+	return g.findIssues(ctx, repo, marker, true)
+}
+
+// failed returns true iff the build status is a failure that firstFailureOnly tracks.
+func failed(s cbpb.Build_Status) bool {
+	return s == cbpb.Build_FAILURE || s == cbpb.Build_INTERNAL_ERROR || s == cbpb.Build_TIMEOUT
+}
+
+// firstFailure tracks the failing state of the build's branch via its open failure issue: it returns false if the build
+// failed and its branch already has an open failure issue. For successful builds, it closes the branch's open failure
+// issues, so the next failure is reported again. Lookup failures are logged and let the notification through.
+func (g *githubissuesNotifier) firstFailure(ctx context.Context, build *cbpb.Build, repo, branch string) bool {
+	if !failed(build.Status) && build.Status != cbpb.Build_SUCCESS {
+		return true
+	}
+	open, err := g.findOpenIssues(ctx, repo, branchFailureMarker(repo, branch))
+	if err != nil {
+		log.Warningf("failed to look up open failure issues for %q in %q: %v", branch, repo, err)
+		return true
+	}
+	if failed(build.Status) {
+		if len(open) > 0 {
+			log.Infof("suppressing failure notification for Build %q: branch %q already has open failure issue #%d in %q", build.Id, branch, open[0].Number, repo)
+			return false
+		}
+		return true
+	}
+	view := &notifiers.TemplateView{Build: &notifiers.BuildView{Build: build}, NotifierName: g.name}
+	view.MaskSecretRefs(g.sg)
+	for _, iss := range open {
+		if err := g.closeIssue(ctx, iss, view); err != nil {
+			log.Warningf("failed to close failure issue #%d in %q: %v", iss.Number, repo, err)
+			continue
+		}
+		log.Infof("closed failure issue #%d in %q: Build %q of branch %q succeeded", iss.Number, repo, build.Id, branch)
+	}
+	return true
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBranchFailureMarker(t *testing.T) {
+	m := branchFailureMarker("somename/somerepo", "main")
+	if m != branchFailureMarker("somename/somerepo", "main") {
+		t.Errorf("branchFailureMarker is not deterministic")
+	}
+	if !strings.HasPrefix(m, "cloud-build-notifiers:failure:") {
+		t.Errorf("branchFailureMarker() = %q, want the cloud-build-notifiers:failure: prefix", m)
+	}
+	for _, other := range []string{branchFailureMarker("somename/somerepo", "dev"), branchFailureMarker("somename/other", "main")} {
+		if m == other {
+			t.Errorf("branchFailureMarker() = %q for different repos or branches", m)
+		}
+	}
+}
+
+func TestEmbedMarker(t *testing.T) {
+	got, err := embedMarker([]byte(`{"title": "t", "body": "b\n", "labels": ["ci"]}`), "some-marker")
+	if err != nil {
+		t.Fatalf("embedMarker failed: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(got, &fields); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"title": "t", "body": "b\n\n<!-- some-marker -->", "labels": []interface{}{"ci"}}
+	if diff := cmp.Diff(want, fields); diff != "" {
+		t.Errorf("unexpected issue (-want +got):\n%s", diff)
+	}
+
+	if _, err := embedMarker([]byte(`"not an object"`), "some-marker"); err == nil {
+		t.Error("embedMarker succeeded for a non-object, want error")
+	}
+}
+
+func TestCreateMarker(t *testing.T) {
+	m := createMarker("somename/somerepo", "some-build-id", "failed")
+	if got := createMarker("somename/somerepo", "some-build-id", "failed"); got != m {
+		t.Errorf("createMarker isn't deterministic: got %q and %q", m, got)
+	}
+	if !regexp.MustCompile(`^cloud-build-notifiers:create:[0-9a-f]{16}$`).MatchString(m) {
+		t.Errorf("createMarker returned %q, want cloud-build-notifiers:create:<16 hex digits>", m)
+	}
+	for _, other := range []string{
+		createMarker("somename/otherrepo", "some-build-id", "failed"),
+		createMarker("somename/somerepo", "other-build-id", "failed"),
+		createMarker("somename/somerepo", "some-build-id", "timed out"),
+		branchFailureMarker("somename/somerepo", "some-build-id"),
+	} {
+		if other == m {
+			t.Errorf("createMarker returned %q for different inputs", m)
+		}
+	}
+}
+
+func TestRenderedCreateMarker(t *testing.T) {
+	got, err := renderedCreateMarker("somename/somerepo", "some-build-id", []byte(`{"title": "failed", "body": "b"}`))
+	if err != nil {
+		t.Fatalf("renderedCreateMarker failed: %v", err)
+	}
+	if want := createMarker("somename/somerepo", "some-build-id", "failed"); got != want {
+		t.Errorf("renderedCreateMarker = %q, want %q", got, want)
+	}
+	if _, err := renderedCreateMarker("somename/somerepo", "some-build-id", []byte(`not json`)); err == nil {
+		t.Error("renderedCreateMarker succeeded for a non-JSON rendered issue, want an error")
+	}
+}
+
+func TestMarkerQuery(t *testing.T) {
+	const marker = "cloud-build-notifiers:create:0123456789abcdef"
+	if got, want := markerQuery("somename/somerepo", marker, false, time.Time{}), `repo:somename/somerepo is:issue in:body "`+marker+`"`; got != want {
+		t.Errorf("markerQuery(openOnly=false) = %q, want %q", got, want)
+	}
+	if got, want := markerQuery("somename/somerepo", marker, true, time.Time{}), `repo:somename/somerepo is:issue in:body "`+marker+`" is:open`; got != want {
+		t.Errorf("markerQuery(openOnly=true) = %q, want %q", got, want)
+	}
+	since := time.Date(2022, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	if got, want := markerQuery("somename/somerepo", marker, true, since), `repo:somename/somerepo is:issue in:body "`+marker+`" is:open updated:>=2022-01-01T11:00:00Z`; got != want {
+		t.Errorf("markerQuery(since) = %q, want %q", got, want)
+	}
+}
+
+// searchByUpdate serves issue searches like GitHub does for the `updated:>=` qualifier: it returns the given issues
+// that were updated since the qualifier's time, or all of them if the query has none. Other requests go to next.
+type searchByUpdate struct {
+	t      *testing.T
+	issues []map[string]interface{}
+	next   http.Handler
+}
+
+func (s *searchByUpdate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/search/issues" {
+		s.next.ServeHTTP(w, r)
+		return
+	}
+	var since time.Time
+	for _, term := range strings.Fields(r.URL.Query().Get("q")) {
+		if v := strings.TrimPrefix(term, "updated:>="); v != term {
+			var err error
+			if since, err = time.Parse(time.RFC3339, v); err != nil {
+				s.t.Errorf("failed to parse search qualifier %q: %v", term, err)
+			}
+		}
+	}
+	items := []map[string]interface{}{}
+	for _, iss := range s.issues {
+		updated, _ := time.Parse(time.RFC3339, iss["updated_at"].(string))
+		if !updated.Before(since) {
+			items = append(items, iss)
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+}
+
+func TestDedupeWindow(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	marker := branchFailureMarker("somename/somerepo", "main")
+	openIssue := func(age time.Duration) map[string]interface{} {
+		return map[string]interface{}{
+			"number":     3,
+			"url":        "https://api.github.com/repos/somename/somerepo/issues/3",
+			"body":       "failed\n\n<!-- " + marker + " -->",
+			"updated_at": time.Now().Add(-age).UTC().Format(time.RFC3339),
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		delivery   map[string]interface{}
+		age        time.Duration
+		wantCreate bool
+	}{{
+		name: "recent issue suppresses",
+		age:  24 * time.Hour,
+	}, {
+		name:       "old issue doesn't suppress",
+		age:        365 * 24 * time.Hour,
+		wantCreate: true,
+	}, {
+		name:       "issue older than configured window doesn't suppress",
+		delivery:   map[string]interface{}{"dedupeWindow": "1h"},
+		age:        2 * time.Hour,
+		wantCreate: true,
+	}, {
+		name:     "zero window matches any age",
+		delivery: map[string]interface{}{"dedupeWindow": "0s"},
+		age:      365 * 24 * time.Hour,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			delivery := map[string]interface{}{"firstFailureOnly": true}
+			for k, v := range tc.delivery {
+				delivery[k] = v
+			}
+			n := newTestNotifier(t, delivery, issuePayload, &searchByUpdate{t: t, issues: []map[string]interface{}{openIssue(tc.age)}, next: fg})
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			_, created := fg.bodies[create]
+			if created != tc.wantCreate {
+				t.Errorf("created an issue: %t, want %t (calls: %v)", created, tc.wantCreate, fg.gotCalls())
+			}
+		})
+	}
+}
+
+func TestFirstFailureOnly(t *testing.T) {
+	const (
+		search      = "GET /search/issues"
+		create      = "POST /repos/somename/somerepo/issues"
+		close       = "PATCH /repos/somename/somerepo/issues/7"
+		closeFailed = "PATCH /repos/somename/somerepo/issues/3"
+		lookup      = "GET /repos/somename/somerepo/commits/main"
+	)
+	marker := branchFailureMarker("somename/somerepo", "main")
+	openIssue := fmt.Sprintf(`{"items": [{"number": 3, "url": "https://api.github.com/repos/somename/somerepo/issues/3", "body": "failed\n\n<!-- %s -->"}]}`, marker)
+
+	for _, tc := range []struct {
+		name       string
+		status     cbpb.Build_Status
+		branch     string
+		responses  map[string]fakeResponse
+		wantCalls  []string
+		wantMarker bool
+	}{{
+		name:       "first failure creates a marked issue",
+		status:     cbpb.Build_FAILURE,
+		branch:     "main",
+		wantCalls:  []string{search, lookup, create},
+		wantMarker: true,
+	}, {
+		name:      "repeated failure is suppressed",
+		status:    cbpb.Build_FAILURE,
+		branch:    "main",
+		responses: map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls: []string{search},
+	}, {
+		name:   "search hits without the marker don't count",
+		status: cbpb.Build_FAILURE,
+		branch: "main",
+		responses: map[string]fakeResponse{
+			search: {http.StatusOK, `{"items": [{"number": 3, "url": "https://api.github.com/repos/somename/somerepo/issues/3", "body": "unrelated"}]}`},
+		},
+		wantCalls:  []string{search, lookup, create},
+		wantMarker: true,
+	}, {
+		name:      "success closes the open failure issue",
+		status:    cbpb.Build_SUCCESS,
+		branch:    "main",
+		responses: map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls: []string{search, closeFailed, lookup, create, close},
+	}, {
+		name:       "failed search lets the failure through",
+		status:     cbpb.Build_FAILURE,
+		branch:     "main",
+		responses:  map[string]fakeResponse{search: {http.StatusUnprocessableEntity, `{}`}},
+		wantCalls:  []string{search, lookup, create},
+		wantMarker: true,
+	}, {
+		name:      "builds without a branch are not tracked",
+		status:    cbpb.Build_FAILURE,
+		wantCalls: []string{create},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: tc.responses}
+			n := newTestNotifier(t, map[string]interface{}{"firstFailureOnly": true}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        tc.status,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": tc.branch},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+			body, _ := fg.bodies[create]["body"].(string)
+			if got := strings.Contains(body, marker); got != tc.wantMarker {
+				t.Errorf("created issue body %q contains the marker: %t, want %t", body, got, tc.wantMarker)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/golang/glog"
+)
+
+// milestone is the milestone that created issues are added to, configured by its number or by its title. It is safe
+// for concurrent use.
+type milestone struct {
+	number int
+	title  string
+
+	mu sync.Mutex
+	// numbers caches the number of the titled milestone in each repo.
+	numbers map[string]int
+}
+
+// parseMilestone parses the milestone delivery config field: a milestone number, or the title of an open milestone.
+func parseMilestone(v interface{}) (*milestone, error) {
+	switch v := v.(type) {
+	case int:
+		if v > 0 {
+			return &milestone{number: v}, nil
+		}
+	case string:
+		if strings.TrimSpace(v) != "" {
+			return &milestone{title: v}, nil
+		}
+	}
+	return nil, fmt.Errorf("expected delivery config field %q to be a positive milestone number or a milestone title, got %v", milestoneField, v)
+}
+
+// lookup returns the number of the milestone in the repo, looking titled milestones up among the repo's open milestones
+// and caching what it finds. It returns 0 if no open milestone has the title.
+func (m *milestone) lookup(ctx context.Context, g *githubissuesNotifier, repo string) (int, error) {
+	if m.number != 0 {
+		return m.number, nil
+	}
+	m.mu.Lock()
+	n, ok := m.numbers[repo]
+	m.mu.Unlock()
+	if ok {
+		return n, nil
+	}
+
+	var milestones []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if err := g.doRequest(ctx, http.MethodGet, g.repoURL(repo)+"/milestones?state=open&per_page=100", nil, &milestones); err != nil {
+		return 0, err
+	}
+	for _, ms := range milestones {
+		if ms.Title == m.title {
+			m.mu.Lock()
+			if m.numbers == nil {
+				m.numbers = map[string]int{}
+			}
+			m.numbers[repo] = ms.Number
+			m.mu.Unlock()
+			return ms.Number, nil
+		}
+	}
+	return 0, nil
+}
+
+// setMilestone sets the configured milestone, if any, on the rendered issue, unless it already sets one. Issues are
+// still created, without a milestone, if it can't be resolved.
+func (g *githubissuesNotifier) setMilestone(ctx context.Context, repo string, rendered []byte) ([]byte, error) {
+	if g.milestone == nil {
+		return rendered, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to set the milestone of, got %q", rendered)
+	}
+	if _, ok := fields["milestone"]; ok {
+		return rendered, nil
+	}
+	n, err := g.milestone.lookup(ctx, g, repo)
+	if err != nil {
+		log.Warningf("failed to look up milestone %q in %q, creating the issue without it: %v", g.milestone.title, repo, err)
+		return rendered, nil
+	}
+	if n == 0 {
+		log.Warningf("found no open milestone %q in %q, creating the issue without it", g.milestone.title, repo)
+		return rendered, nil
+	}
+	fields["milestone"] = n
+	return json.Marshal(fields)
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMilestone(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/issues"
+		lookup = "GET /repos/somename/somerepo/milestones"
+	)
+	milestones := fakeResponse{code: http.StatusOK, body: `[{"number": 3, "title": "Sprint 41"}, {"number": 5, "title": "Sprint 42"}]`}
+	for _, tc := range []struct {
+		name          string
+		milestone     interface{}
+		issueTemplate string
+		responses     map[string]fakeResponse
+		wantMilestone interface{}
+		wantCalls     []string
+	}{{
+		name:          "number",
+		milestone:     4,
+		issueTemplate: issuePayload,
+		wantMilestone: float64(4),
+		wantCalls:     []string{create, create},
+	}, {
+		name:          "title is looked up once",
+		milestone:     "Sprint 42",
+		issueTemplate: issuePayload,
+		responses:     map[string]fakeResponse{lookup: milestones},
+		wantMilestone: float64(5),
+		wantCalls:     []string{lookup, create, create},
+	}, {
+		name:          "unknown title",
+		milestone:     "Sprint 43",
+		issueTemplate: issuePayload,
+		responses:     map[string]fakeResponse{lookup: milestones},
+		wantCalls:     []string{lookup, create, lookup, create},
+	}, {
+		name:          "failed lookup",
+		milestone:     "Sprint 42",
+		issueTemplate: issuePayload,
+		responses:     map[string]fakeResponse{lookup: {code: http.StatusNotFound, body: `{"message": "Not Found"}`}},
+		wantCalls:     []string{lookup, create, lookup, create},
+	}, {
+		name:          "template sets the milestone",
+		milestone:     "Sprint 42",
+		issueTemplate: `{"title": "t", "body": "b", "milestone": 9}`,
+		responses:     map[string]fakeResponse{lookup: milestones},
+		wantMilestone: float64(9),
+		wantCalls:     []string{create, create},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: tc.responses}
+			n := newTestNotifier(t, map[string]interface{}{"milestone": tc.milestone}, tc.issueTemplate, fg)
+
+			for _, id := range []string{"some-build-id", "other-build-id"} {
+				build := &cbpb.Build{
+					Id:            id,
+					Status:        cbpb.Build_FAILURE,
+					Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+				}
+				if err := n.SendNotification(context.Background(), build); err != nil {
+					t.Fatalf("SendNotification failed: %v", err)
+				}
+				if got := fg.bodies[create]["milestone"]; got != tc.wantMilestone {
+					t.Errorf("got milestone %v, want %v", got, tc.wantMilestone)
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseMilestoneErrors(t *testing.T) {
+	for _, v := range []interface{}{0, -1, "", " ", 1.5, []interface{}{"Sprint 42"}} {
+		if _, err := parseMilestone(v); err == nil {
+			t.Errorf("parseMilestone(%v) succeeded, want an error", v)
+		}
+	}
+}
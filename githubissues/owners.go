@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+// parseOwnershipRules parses the ownershipRules delivery config field, a map of regular expressions matching build
+// steps to GitHub usernames.
+func parseOwnershipRules(v interface{}) ([]patternRule, error) {
+	return parsePatternRules(ownershipRulesField, "GitHub username", v)
+}
+
+// stepOwner returns the username of the first rule matching the step's ID, name (its builder image), or directory, or ""
+// if none does.
+func stepOwner(rules []patternRule, step *cbpb.BuildStep) string {
+	if step == nil {
+		return ""
+	}
+	for _, r := range rules {
+		for _, s := range []string{step.GetId(), step.GetName(), step.GetDir()} {
+			if s != "" && r.pattern.MatchString(s) {
+				return r.value
+			}
+		}
+	}
+	return ""
+}
+
+// owner returns who to assign the build's issue to: the owner of its failed step by the ownership rules, falling back
+// to its committer (see GetAndSetCommitterInfo). It returns "" if neither is known.
+func (g *githubissuesNotifier) owner(build *cbpb.Build) string {
+	if o := stepOwner(g.ownershipRules, (&notifiers.BuildView{Build: build}).FailedStep()); o != "" {
+		return o
+	}
+	return build.Substitutions[committerLoginSubst]
+}
+
+// assignees returns who to assign the failed build's issue to, if ownershipRules or assignCommitter are configured:
+// its owner (see owner) or, failing that, the fallbackAssignees. Bot accounts, which GitHub doesn't let issues be
+// assigned to, don't count as owners.
+func (g *githubissuesNotifier) assignees(build *cbpb.Build) []string {
+	if g.ownershipRules == nil && !g.assignCommitter {
+		return nil
+	}
+	if o := g.owner(build); o != "" && !strings.HasSuffix(o, "[bot]") {
+		return []string{o}
+	}
+	return g.fallbackAssignees
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStepOwner(t *testing.T) {
+	rules, err := parseOwnershipRules(map[interface{}]interface{}{
+		"^services/payments":    "payments-oncall",
+		"^web-":                 "frontend-lead",
+		"golang":                "go-owner",
+		"^services/payments/db": "dba",
+	})
+	if err != nil {
+		t.Fatalf("parseOwnershipRules failed: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		step *cbpb.BuildStep
+		want string
+	}{{
+		name: "step ID",
+		step: &cbpb.BuildStep{Id: "web-test", Name: "node"},
+		want: "frontend-lead",
+	}, {
+		name: "directory",
+		step: &cbpb.BuildStep{Id: "test", Name: "node", Dir: "services/payments/api"},
+		want: "payments-oncall",
+	}, {
+		name: "first rule in pattern order wins",
+		step: &cbpb.BuildStep{Id: "migrate", Dir: "services/payments/db"},
+		want: "payments-oncall",
+	}, {
+		name: "builder image",
+		step: &cbpb.BuildStep{Id: "unit", Name: "golang:1.20"},
+		want: "go-owner",
+	}, {
+		name: "no match",
+		step: &cbpb.BuildStep{Id: "lint", Name: "python", Dir: "tools"},
+	}, {
+		name: "no failed step",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := stepOwner(rules, tc.step); got != tc.want {
+				t.Errorf("stepOwner(%v) = %q, want %q", tc.step, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseOwnershipRulesErrors(t *testing.T) {
+	for _, v := range []interface{}{
+		"payments-oncall",
+		map[interface{}]interface{}{},
+		map[interface{}]interface{}{"(": "someone"},
+		map[interface{}]interface{}{"^web-": ""},
+		map[interface{}]interface{}{"^web-": []interface{}{"a", "b"}},
+	} {
+		if _, err := parseOwnershipRules(v); err == nil {
+			t.Errorf("parseOwnershipRules(%v) succeeded, want an error", v)
+		}
+	}
+}
+
+func TestSendNotificationOwnership(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/issues"
+		lookup = "GET /repos/somename/somerepo/commits/main"
+	)
+	rules := map[interface{}]interface{}{"^deploy$": "release-captain"}
+
+	for _, tc := range []struct {
+		name          string
+		delivery      map[string]interface{}
+		steps         []*cbpb.BuildStep
+		lookup        *fakeResponse
+		wantAssignees interface{}
+	}{{
+		name:     "owner of the failed step",
+		delivery: map[string]interface{}{"ownershipRules": rules},
+		steps: []*cbpb.BuildStep{
+			{Id: "test", Status: cbpb.Build_SUCCESS},
+			{Id: "deploy", Status: cbpb.Build_FAILURE},
+		},
+		wantAssignees: []interface{}{"release-captain"},
+	}, {
+		name:     "committer when no rule matches",
+		delivery: map[string]interface{}{"ownershipRules": rules},
+		steps: []*cbpb.BuildStep{
+			{Id: "test", Status: cbpb.Build_FAILURE},
+			{Id: "deploy", Status: cbpb.Build_CANCELLED},
+		},
+		wantAssignees: []interface{}{"author"},
+	}, {
+		name:          "committer when no step failed",
+		delivery:      map[string]interface{}{"ownershipRules": rules},
+		wantAssignees: []interface{}{"author"},
+	}, {
+		name:  "unset without rules",
+		steps: []*cbpb.BuildStep{{Id: "deploy", Status: cbpb.Build_FAILURE}},
+	}, {
+		name:          "committer with assignCommitter",
+		delivery:      map[string]interface{}{"assignCommitter": true},
+		steps:         []*cbpb.BuildStep{{Id: "deploy", Status: cbpb.Build_FAILURE}},
+		wantAssignees: []interface{}{"author"},
+	}, {
+		name:          "fallback for an unknown committer",
+		delivery:      map[string]interface{}{"assignCommitter": true, "fallbackAssignees": []interface{}{"oncall", "lead"}},
+		lookup:        &fakeResponse{http.StatusNotFound, `{}`},
+		wantAssignees: []interface{}{"oncall", "lead"},
+	}, {
+		name:          "fallback for a bot committer",
+		delivery:      map[string]interface{}{"assignCommitter": true, "fallbackAssignees": []interface{}{"oncall"}},
+		lookup:        &fakeResponse{http.StatusOK, `{"author": {"login": "dependabot[bot]"}}`},
+		wantAssignees: []interface{}{"oncall"},
+	}, {
+		name:          "fallback when neither rules nor the committer assign",
+		delivery:      map[string]interface{}{"ownershipRules": rules, "fallbackAssignees": []interface{}{"oncall"}},
+		steps:         []*cbpb.BuildStep{{Id: "test", Status: cbpb.Build_FAILURE}},
+		lookup:        &fakeResponse{http.StatusNotFound, `{}`},
+		wantAssignees: []interface{}{"oncall"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := fakeResponse{http.StatusOK, `{"author": {"login": "author"}}`}
+			if tc.lookup != nil {
+				resp = *tc.lookup
+			}
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{lookup: resp}}
+			n := newTestNotifier(t, tc.delivery, `{"title": "failed"}`, fg)
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Steps:         tc.steps,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantAssignees, fg.bodies[create]["assignees"]); diff != "" {
+				t.Errorf("created issue with unexpected assignees (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFallbackAssigneesRequiresAssignment(t *testing.T) {
+	cfg := &notifiers.Config{Spec: &notifiers.Spec{
+		Notification: &notifiers.Notification{
+			Filter: `build.status == Build.Status.FAILURE`,
+			Delivery: map[string]interface{}{
+				"githubToken":       map[interface{}]interface{}{"secretRef": "mytoken"},
+				"githubRepo":        "somename/somerepo",
+				"fallbackAssignees": []interface{}{"oncall"},
+			},
+		},
+		Secrets: []*notifiers.Secret{{LocalName: "mytoken", ResourceName: "mysekrit"}},
+	}}
+	if err := new(githubissuesNotifier).SetUp(context.Background(), cfg, issuePayload, new(fakeSecretGetter), new(fakeBindingResolver)); err == nil {
+		t.Error("SetUp with fallbackAssignees but neither assignCommitter nor ownershipRules succeeded, want error")
+	}
+}
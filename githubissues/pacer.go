@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+// repoPacer spaces out calls per repo by at least an interval, while calls for different repos proceed independently.
+// A zero interval doesn't pace at all. It is safe for concurrent use.
+type repoPacer struct {
+	interval time.Duration
+	// now and sleep default to time.Now and sleepCtx if nil.
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+
+	mu sync.Mutex
+	// next is the earliest time of each repo's next call.
+	next map[string]time.Time
+}
+
+func newRepoPacer(interval time.Duration) *repoPacer {
+	return &repoPacer{interval: interval}
+}
+
+// wait reserves the repo's next call slot and waits until it comes, or returns ctx's error if ctx is done first. The
+// slot stays reserved either way.
+func (p *repoPacer) wait(ctx context.Context, repo string) error {
+	if p == nil || p.interval <= 0 {
+		return nil
+	}
+	now, sleep := time.Now, sleepCtx
+	if p.now != nil {
+		now = p.now
+	}
+	if p.sleep != nil {
+		sleep = p.sleep
+	}
+
+	p.mu.Lock()
+	t := now()
+	slot := t
+	if next, ok := p.next[repo]; ok && next.After(t) {
+		slot = next
+	}
+	if p.next == nil {
+		p.next = map[string]time.Time{}
+	}
+	p.next[repo] = slot.Add(p.interval)
+	// Forget repos whose slots have passed, so the map stays as small as the set of recently busy repos.
+	for r, next := range p.next {
+		if !next.After(t) {
+			delete(p.next, r)
+		}
+	}
+	p.mu.Unlock()
+
+	delay := slot.Sub(t)
+	if delay <= 0 {
+		return nil
+	}
+	log.V(2).Infof("pacing creation in %q by %v", repo, delay)
+	return sleep(ctx, delay)
+}
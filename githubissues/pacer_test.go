@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeClock is a clock whose sleeps advance it instantly, recording how long each was.
+type fakeClock struct {
+	mu     sync.Mutex
+	t      time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func (c *fakeClock) sleep(_ context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	return nil
+}
+
+func TestRepoPacer(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := newRepoPacer(time.Second)
+	p.now, p.sleep = clock.now, clock.sleep
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		repo    string
+		advance time.Duration
+	}{
+		// Bursts in one repo queue up behind each other...
+		{repo: "org/a"},
+		{repo: "org/a"},
+		{repo: "org/a"},
+		// ...without holding up other repos.
+		{repo: "org/b"},
+		// Once the queue has drained, calls go through right away.
+		{repo: "org/b", advance: 5 * time.Second},
+		{repo: "org/a", advance: 500 * time.Millisecond},
+		{repo: "org/a"},
+	} {
+		clock.advance(step.advance)
+		if err := p.wait(ctx, step.repo); err != nil {
+			t.Fatalf("wait(%q) failed: %v", step.repo, err)
+		}
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, time.Second}
+	if diff := cmp.Diff(want, clock.sleeps); diff != "" {
+		t.Errorf("unexpected waits (-want +got):\n%s", diff)
+	}
+}
+
+func TestRepoPacerConcurrent(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := newRepoPacer(time.Second)
+	p.now, p.sleep = clock.now, clock.sleep
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.wait(context.Background(), "org/a"); err != nil {
+				t.Errorf("wait failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each concurrent caller gets its own slot, so no two calls share one.
+	got := map[time.Duration]bool{}
+	for _, d := range clock.sleeps {
+		got[d] = true
+	}
+	for i := 1; i < 5; i++ {
+		if !got[time.Duration(i)*time.Second] {
+			t.Errorf("no caller waited %ds, got waits %v", i, clock.sleeps)
+		}
+	}
+}
+
+func TestRepoPacerDeadline(t *testing.T) {
+	p := newRepoPacer(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := p.wait(ctx, "org/a"); err != nil {
+		t.Fatalf("first wait failed: %v", err)
+	}
+	if err := p.wait(ctx, "org/a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second wait got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPerRepoMinInterval(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{"perRepoMinInterval": "2s"}, issuePayload, fg)
+	n.createPacer.now, n.createPacer.sleep = clock.now, clock.sleep
+
+	for _, repo := range []string{"somename/somerepo", "somename/somerepo", "somename/other"} {
+		if _, err := n.createIssue(context.Background(), repo, []byte(`{"title": "t"}`)); err != nil {
+			t.Fatalf("createIssue(%q) failed: %v", repo, err)
+		}
+	}
+	if diff := cmp.Diff([]time.Duration{2 * time.Second}, clock.sleeps); diff != "" {
+		t.Errorf("unexpected waits (-want +got):\n%s", diff)
+	}
+}
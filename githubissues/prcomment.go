@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	log "github.com/golang/glog"
+)
+
+// Values of the `mode` delivery config field, i.e. whether builds triggered by a pull request are commented on it
+// instead of getting an issue.
+const (
+	modeIssue     = "issue"
+	modePRComment = "pr-comment"
+	modeAuto      = "auto"
+)
+
+// prNumberSubst is the substitution that Cloud Build sets to the number of the pull request that triggered the build.
+const prNumberSubst = "_PR_NUMBER"
+
+// parseMode parses the mode delivery config field, which defaults to modeIssue.
+func parseMode(delivery map[string]interface{}) (string, error) {
+	m, ok := delivery[modeField]
+	if !ok {
+		return modeIssue, nil
+	}
+	switch m {
+	case modeIssue, modePRComment, modeAuto:
+		return m.(string), nil
+	}
+	return "", fmt.Errorf("expected delivery config field %q to be one of %q, %q, or %q, got %v", modeField, modeIssue, modePRComment, modeAuto, m)
+}
+
+// pullRequest returns the number of the pull request in the repo to comment the build's notification on, or 0 if it
+// should get an issue instead: if the mode is modeIssue, the build wasn't triggered by a pull request, or the repo isn't
+// the build's own, e.g. a fallbackRepo.
+func (g *githubissuesNotifier) pullRequest(build *cbpb.Build, repo string) int {
+	if g.mode == modeIssue || repo != GetGithubRepo(build) {
+		return 0
+	}
+	s := build.Substitutions[prNumberSubst]
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		log.Warningf("Build %q has an invalid %s %q, not commenting on its pull request", build.Id, prNumberSubst, s)
+		return 0
+	}
+	return n
+}
+
+// commentOnPullRequest comments the rendered issue body on the pull request.
+func (g *githubissuesNotifier) commentOnPullRequest(ctx context.Context, build *cbpb.Build, repo string, pr int, rendered []byte) error {
+	body, err := commentPayload(rendered)
+	if err != nil {
+		return err
+	}
+	g.logPayload(build, "comment", body)
+	if err := g.doRequest(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/comments", g.repoURL(repo), pr), body, nil); err != nil {
+		return fmt.Errorf("failed to comment on pull request #%d: %w%s", pr, err, g.errorPayload("comment", body))
+	}
+	log.V(2).Infof("commented on pull request #%d in %q for Build %q", pr, repo, build.Id)
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPRCommentMode(t *testing.T) {
+	const (
+		create  = "POST /repos/somename/somerepo/issues"
+		comment = "POST /repos/somename/somerepo/issues/42/comments"
+	)
+	for _, tc := range []struct {
+		name      string
+		mode      string
+		subst     map[string]string
+		wantCalls []string
+	}{{
+		name:      "issue mode ignores pull requests",
+		mode:      "issue",
+		subst:     map[string]string{"_PR_NUMBER": "42"},
+		wantCalls: []string{create},
+	}, {
+		name:      "pr-comment mode comments on the pull request",
+		mode:      "pr-comment",
+		subst:     map[string]string{"_PR_NUMBER": "42"},
+		wantCalls: []string{comment},
+	}, {
+		name: "pr-comment mode skips builds without a pull request",
+		mode: "pr-comment",
+	}, {
+		name:      "auto mode comments on the pull request",
+		mode:      "auto",
+		subst:     map[string]string{"_PR_NUMBER": "42"},
+		wantCalls: []string{comment},
+	}, {
+		name:      "auto mode opens issues for builds without a pull request",
+		mode:      "auto",
+		wantCalls: []string{create},
+	}, {
+		name:      "auto mode opens issues for invalid pull request numbers",
+		mode:      "auto",
+		subst:     map[string]string{"_PR_NUMBER": "abc"},
+		wantCalls: []string{create},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"mode": tc.mode}, issuePayload, fg)
+
+			subst := map[string]string{"REPO_FULL_NAME": "somename/somerepo"}
+			for k, v := range tc.subst {
+				subst[k] = v
+			}
+			build := &cbpb.Build{Id: "some-build-id", ProjectId: "my-project-id", Status: cbpb.Build_FAILURE, Substitutions: subst}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+			if body, ok := fg.bodies[comment]; ok {
+				if got, _ := body["body"].(string); !strings.HasPrefix(got, "Cloud Build my-project-id") {
+					t.Errorf("got comment body %q, want the rendered issue body", got)
+				}
+			}
+		})
+	}
+}
+
+func TestPRCommentModeFailedComment(t *testing.T) {
+	const comment = "POST /repos/somename/somerepo/issues/42/comments"
+	for _, tc := range []struct {
+		name          string
+		code          int
+		wantPermanent bool
+	}{{
+		name: "server error is redelivered",
+		code: http.StatusBadGateway,
+	}, {
+		name:          "rejected comment is dropped",
+		code:          http.StatusForbidden,
+		wantPermanent: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{comment: {tc.code, `{}`}}}
+			n := newTestNotifier(t, map[string]interface{}{"mode": "pr-comment"}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "_PR_NUMBER": "42"},
+			}
+			err := n.SendNotification(context.Background(), build)
+			if err == nil {
+				t.Fatal("SendNotification succeeded despite the failed comment, want an error")
+			}
+			if got := notifiers.IsPermanent(err); got != tc.wantPermanent {
+				t.Errorf("SendNotification got error %v, want permanent: %t", err, tc.wantPermanent)
+			}
+			if diff := cmp.Diff([]string{comment}, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPRCommentModeFallbackRepo(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{"mode": "auto", "fallbackRepo": "somename/somerepo"}, issuePayload, fg)
+	n.githubRepo = ""
+
+	build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, Substitutions: map[string]string{"_PR_NUMBER": "42"}}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"POST /repos/somename/somerepo/issues"}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseModeErrors(t *testing.T) {
+	for _, v := range []interface{}{"", "comment", 1} {
+		if _, err := parseMode(map[string]interface{}{modeField: v}); err == nil {
+			t.Errorf("parseMode(%v) succeeded, want an error", v)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/golang/glog"
+)
+
+// defaultProjectStatusField is the single-select field of a project that the project's `status` is set in by default.
+const defaultProjectStatusField = "Status"
+
+// project is the GitHub Projects (v2) project that created issues are added to, optionally with a status. It is safe
+// for concurrent use.
+type project struct {
+	// id is the project's GraphQL node ID, e.g. PVT_kwDOAB....
+	id string
+	// statusField and status are the names of the single-select field and its option that added items are set to. status
+	// is "" if added items are left at the project's default.
+	statusField string
+	status      string
+
+	mu sync.Mutex
+	// fieldID and optionID cache the IDs of statusField and status once they're resolved.
+	fieldID  string
+	optionID string
+}
+
+// parseProject parses the project delivery config field: a map of the project's node `id` and, optionally, the
+// `status` option (of the `statusField` single-select field) to set its added items to.
+func parseProject(v interface{}) (*project, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected delivery config field %q to be a map of project settings, got %v", projectField, v)
+	}
+	p := &project{statusField: defaultProjectStatusField}
+	for k, s := range m {
+		str, ok := s.(string)
+		if !ok || strings.TrimSpace(str) == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", fmt.Sprintf("%s.%v", projectField, k), s)
+		}
+		switch k {
+		case "id":
+			p.id = str
+		case "statusField":
+			p.statusField = str
+		case "status":
+			p.status = str
+		default:
+			return nil, fmt.Errorf("unknown delivery config field %q, expected one of %q, %q, or %q", fmt.Sprintf("%s.%v", projectField, k), "id", "statusField", "status")
+		}
+	}
+	if p.id == "" {
+		return nil, fmt.Errorf("expected delivery config field %q to have the project's node %q", projectField, "id")
+	}
+	return p, nil
+}
+
+// graphQLURL returns the URL of the GitHub GraphQL API, which GitHub Enterprise Server serves at /api/graphql rather
+// than under the REST API's /api/v3.
+func (g *githubissuesNotifier) graphQLURL() string {
+	return strings.TrimSuffix(g.apiURL, "/v3") + "/graphql"
+}
+
+// graphQL runs the GraphQL query or mutation with the variables and decodes its data into out. Errors that GitHub
+// reports in the response body are returned as well.
+func (g *githubissuesNotifier) graphQL(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("failed to encode GraphQL request: %w", err)
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := g.doRequest(ctx, http.MethodPost, g.graphQLURL(), body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("GraphQL request failed: %s", strings.Join(msgs, "; "))
+	}
+	if len(resp.Data) == 0 || string(resp.Data) == "null" {
+		return errors.New("GraphQL response has no data")
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+const (
+	addProjectItemMutation = `mutation($project: ID!, $content: ID!) {
+  addProjectV2ItemById(input: {projectId: $project, contentId: $content}) { item { id } }
+}`
+	projectFieldQuery = `query($project: ID!, $field: String!) {
+  node(id: $project) {
+    ... on ProjectV2 {
+      field(name: $field) {
+        ... on ProjectV2SingleSelectField { id options { id name } }
+      }
+    }
+  }
+}`
+	setProjectItemStatusMutation = `mutation($project: ID!, $item: ID!, $field: ID!, $option: String!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: {singleSelectOptionId: $option}}) {
+    projectV2Item { id }
+  }
+}`
+)
+
+// statusIDs returns the IDs of the project's status field and option, looking them up on first use.
+func (p *project) statusIDs(ctx context.Context, g *githubissuesNotifier) (fieldID, optionID string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.optionID != "" {
+		return p.fieldID, p.optionID, nil
+	}
+	var data struct {
+		Node *struct {
+			Field *struct {
+				ID      string `json:"id"`
+				Options []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"options"`
+			} `json:"field"`
+		} `json:"node"`
+	}
+	if err := g.graphQL(ctx, projectFieldQuery, map[string]interface{}{"project": p.id, "field": p.statusField}, &data); err != nil {
+		return "", "", fmt.Errorf("failed to look up field %q of project %q: %w", p.statusField, p.id, err)
+	}
+	if data.Node == nil || data.Node.Field == nil || data.Node.Field.ID == "" {
+		return "", "", fmt.Errorf("project %q has no single-select field %q", p.id, p.statusField)
+	}
+	for _, o := range data.Node.Field.Options {
+		if o.Name == p.status {
+			p.fieldID, p.optionID = data.Node.Field.ID, o.ID
+			return p.fieldID, p.optionID, nil
+		}
+	}
+	return "", "", fmt.Errorf("field %q of project %q has no option %q", p.statusField, p.id, p.status)
+}
+
+// addToProject adds the issue to the configured project, if any, and sets its status there. The issue is already
+// created, so failures are logged rather than failing the notification, which redelivery would duplicate.
+func (g *githubissuesNotifier) addToProject(ctx context.Context, repo string, iss *issue) {
+	if g.project == nil {
+		return
+	}
+	if iss.NodeID == "" {
+		log.Warningf("issue #%d in %q has no node ID to add it to project %q with", iss.Number, repo, g.project.id)
+		return
+	}
+	var added struct {
+		AddProjectV2ItemByID struct {
+			Item struct {
+				ID string `json:"id"`
+			} `json:"item"`
+		} `json:"addProjectV2ItemById"`
+	}
+	if err := g.graphQL(ctx, addProjectItemMutation, map[string]interface{}{"project": g.project.id, "content": iss.NodeID}, &added); err != nil {
+		log.Warningf("failed to add issue #%d in %q to project %q: %v", iss.Number, repo, g.project.id, err)
+		return
+	}
+	item := added.AddProjectV2ItemByID.Item.ID
+	log.V(2).Infof("added issue #%d in %q to project %q as item %q", iss.Number, repo, g.project.id, item)
+	if g.project.status == "" {
+		return
+	}
+
+	fieldID, optionID, err := g.project.statusIDs(ctx, g)
+	if err != nil {
+		log.Warningf("not setting the status of issue #%d in %q in its project: %v", iss.Number, repo, err)
+		return
+	}
+	vars := map[string]interface{}{"project": g.project.id, "item": item, "field": fieldID, "option": optionID}
+	if err := g.graphQL(ctx, setProjectItemStatusMutation, vars, new(json.RawMessage)); err != nil {
+		log.Warningf("failed to set the status of issue #%d in %q in project %q to %q: %v", iss.Number, repo, g.project.id, g.project.status, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeGraphQL serves GitHub GraphQL requests from canned responses for each operation, and the REST API from fakeGitHub.
+type fakeGraphQL struct {
+	fakeGitHub
+	// ops maps the operations (addProjectV2ItemById, field, or updateProjectV2ItemFieldValue) to response bodies.
+	ops map[string]string
+
+	gmu  sync.Mutex
+	got  []string                 // The operations requested, in order.
+	vars []map[string]interface{} // The variables of each request.
+}
+
+func (f *fakeGraphQL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/graphql" {
+		f.fakeGitHub.ServeHTTP(w, r)
+		return
+	}
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Errorf("failed to decode GraphQL request: %v", err)
+	}
+	for _, op := range []string{"addProjectV2ItemById", "updateProjectV2ItemFieldValue", "field"} {
+		if strings.Contains(req.Query, op+"(") {
+			f.gmu.Lock()
+			f.got = append(f.got, op)
+			f.vars = append(f.vars, req.Variables)
+			f.gmu.Unlock()
+			fmt.Fprint(w, f.ops[op])
+			return
+		}
+	}
+	f.t.Errorf("unexpected GraphQL query %q", req.Query)
+}
+
+func TestAddToProject(t *testing.T) {
+	const (
+		added     = `{"data": {"addProjectV2ItemById": {"item": {"id": "PVTI_1"}}}}`
+		fieldData = `{"data": {"node": {"field": {"id": "PVTSSF_1", "options": [{"id": "opt-todo", "name": "Todo"}, {"id": "opt-triage", "name": "Triage"}]}}}}`
+		updated   = `{"data": {"updateProjectV2ItemFieldValue": {"projectV2Item": {"id": "PVTI_1"}}}}`
+	)
+	for _, tc := range []struct {
+		name    string
+		project map[interface{}]interface{}
+		ops     map[string]string
+		wantOps []string
+	}{{
+		name:    "added without a status",
+		project: map[interface{}]interface{}{"id": "PVT_1"},
+		ops:     map[string]string{"addProjectV2ItemById": added},
+		wantOps: []string{"addProjectV2ItemById", "addProjectV2ItemById"},
+	}, {
+		name:    "added with a status looked up once",
+		project: map[interface{}]interface{}{"id": "PVT_1", "status": "Triage"},
+		ops:     map[string]string{"addProjectV2ItemById": added, "field": fieldData, "updateProjectV2ItemFieldValue": updated},
+		wantOps: []string{
+			"addProjectV2ItemById", "field", "updateProjectV2ItemFieldValue",
+			"addProjectV2ItemById", "updateProjectV2ItemFieldValue",
+		},
+	}, {
+		name:    "unknown status",
+		project: map[interface{}]interface{}{"id": "PVT_1", "status": "Blocked"},
+		ops:     map[string]string{"addProjectV2ItemById": added, "field": fieldData},
+		wantOps: []string{"addProjectV2ItemById", "field", "addProjectV2ItemById", "field"},
+	}, {
+		name:    "GraphQL errors",
+		project: map[interface{}]interface{}{"id": "PVT_1", "status": "Triage"},
+		ops:     map[string]string{"addProjectV2ItemById": `{"data": null, "errors": [{"message": "Resource not accessible"}]}`},
+		wantOps: []string{"addProjectV2ItemById", "addProjectV2ItemById"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGraphQL{fakeGitHub: fakeGitHub{t: t, issue: `{"number": 7, "node_id": "I_7"}`}, ops: tc.ops}
+			n := newTestNotifier(t, map[string]interface{}{"project": tc.project}, issuePayload, fg)
+
+			for _, id := range []string{"some-build-id", "other-build-id"} {
+				build := &cbpb.Build{Id: id, Status: cbpb.Build_FAILURE, Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"}}
+				if err := n.SendNotification(context.Background(), build); err != nil {
+					t.Fatalf("SendNotification failed: %v", err)
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantOps, fg.got); diff != "" {
+				t.Errorf("unexpected GraphQL operations (-want +got):\n%s", diff)
+			}
+			if got := fg.vars[0]; got["project"] != "PVT_1" || got["content"] != "I_7" {
+				t.Errorf("got addProjectV2ItemById variables %v, want project PVT_1 and content I_7", got)
+			}
+			for i, op := range fg.got {
+				if op == "updateProjectV2ItemFieldValue" {
+					want := map[string]interface{}{"project": "PVT_1", "item": "PVTI_1", "field": "PVTSSF_1", "option": "opt-triage"}
+					if diff := cmp.Diff(want, fg.vars[i]); diff != "" {
+						t.Errorf("unexpected updateProjectV2ItemFieldValue variables (-want +got):\n%s", diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	for api, want := range map[string]string{
+		"https://api.github.com":         "https://api.github.com/graphql",
+		"https://ghe.example.com/api/v3": "https://ghe.example.com/api/graphql",
+	} {
+		n := &githubissuesNotifier{apiURL: api}
+		if got := n.graphQLURL(); got != want {
+			t.Errorf("graphQLURL() with API URL %q = %q, want %q", api, got, want)
+		}
+	}
+}
+
+func TestParseProjectErrors(t *testing.T) {
+	for _, v := range []interface{}{
+		"PVT_1",
+		map[interface{}]interface{}{},
+		map[interface{}]interface{}{"status": "Triage"},
+		map[interface{}]interface{}{"id": ""},
+		map[interface{}]interface{}{"id": 1},
+		map[interface{}]interface{}{"id": "PVT_1", "column": "Triage"},
+	} {
+		if _, err := parseProject(v); err == nil {
+			t.Errorf("parseProject(%v) succeeded, want an error", v)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// branchStateRetention is how long the last outcome of a branch's builds is remembered. A branch that isn't built for
+// longer is first seen again by its next build.
+const branchStateRetention = 30 * 24 * time.Hour
+
+// Branch outcomes recorded by recoveryPolicy.
+const (
+	branchFailing = "failing"
+	branchPassing = "passing"
+)
+
+// recoveryPolicy renders a separate template for the first successful build of a branch whose previous build failed.
+type recoveryPolicy struct {
+	store notifiers.StateStore
+	tmpl  *template.Template
+	// closeFailures comments the rendered recovery on the branch's open failure issues and closes them, instead of
+	// creating an issue for the recovery.
+	closeFailures bool
+}
+
+// parseRecoveryPolicy returns the recovery policy of the `recoveryTemplate` and `closeOnRecovery` delivery config
+// fields, or nil if `recoveryTemplate` is not set.
+func parseRecoveryPolicy(delivery map[string]interface{}, store notifiers.StateStore) (*recoveryPolicy, error) {
+	closeFailures, err := getBoolField(delivery, closeOnRecoveryField)
+	if err != nil {
+		return nil, err
+	}
+	rt, ok := delivery[recoveryTemplateField]
+	if !ok {
+		if closeFailures {
+			return nil, fmt.Errorf("delivery config field %q requires %q to be set", closeOnRecoveryField, recoveryTemplateField)
+		}
+		return nil, nil
+	}
+	rts, ok := rt.(string)
+	if !ok || rts == "" {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty string, got %v", recoveryTemplateField, rt)
+	}
+	tmpl, err := template.New("recovery_template").Funcs(notifiers.TemplateFuncs()).Parse(rts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recovery template: %w", err)
+	}
+	return &recoveryPolicy{store: store, tmpl: tmpl, closeFailures: closeFailures}, nil
+}
+
+// branchOutcome returns the outcome that the build records for its branch, or "" if it records none.
+func branchOutcome(build *cbpb.Build) string {
+	switch {
+	case build.Substitutions["BRANCH_NAME"] == "":
+		return ""
+	case failed(build.Status):
+		return branchFailing
+	case build.Status == cbpb.Build_SUCCESS:
+		return branchPassing
+	}
+	return ""
+}
+
+// recovered returns true iff the build succeeded and the last outcome recorded for its repo and branch is a failure.
+// Builds of branches without a recorded outcome (first seen, or not built within branchStateRetention) don't count as
+// recoveries, nor do builds whose branch's outcome can't be looked up.
+func (r *recoveryPolicy) recovered(ctx context.Context, build *cbpb.Build, repo string) bool {
+	if branchOutcome(build) != branchPassing {
+		return false
+	}
+	branch := build.Substitutions["BRANCH_NAME"]
+	prev, ok, err := r.store.Get(ctx, "branch/"+repo+"@"+branch)
+	if err != nil {
+		log.Warningf("failed to look up the last outcome of branch %q in %q, not treating Build %q as a recovery: %v", branch, repo, build.Id, err)
+		return false
+	}
+	return ok && prev == branchFailing
+}
+
+// record records the outcome of the build for its repo and branch. It's called once the build has been notified, so
+// that a recovery whose notification failed is still a recovery when it's redelivered.
+func (r *recoveryPolicy) record(ctx context.Context, build *cbpb.Build, repo string) {
+	outcome := branchOutcome(build)
+	if outcome == "" {
+		return
+	}
+	branch := build.Substitutions["BRANCH_NAME"]
+	if err := r.store.Put(ctx, "branch/"+repo+"@"+branch, outcome, branchStateRetention); err != nil {
+		log.Warningf("failed to record the outcome of Build %q for branch %q in %q: %v", build.Id, branch, repo, err)
+	}
+}
+
+// marksFailures returns true iff failure issues are marked with their branch's failure marker, so later builds of the
+// branch can find them.
+func (g *githubissuesNotifier) marksFailures() bool {
+	return g.firstFailureOnly || (g.recovery != nil && g.recovery.closeFailures)
+}
+
+// closeRecoveredFailures comments the body of the rendered recovery on the open failure issues of the build's branch and
+// closes them. It returns false if there are none, or they can't be looked up, so the recovery is sent as an issue.
+// Failing to comment on or close one fails the notification.
+func (g *githubissuesNotifier) closeRecoveredFailures(ctx context.Context, build *cbpb.Build, repo string, rendered []byte, view *notifiers.TemplateView) (bool, error) {
+	branch := build.Substitutions["BRANCH_NAME"]
+	open, err := g.findOpenIssues(ctx, repo, branchFailureMarker(repo, branch))
+	if err != nil {
+		log.Warningf("failed to look up open failure issues for %q in %q, creating a recovery issue instead: %v", branch, repo, err)
+		return false, nil
+	}
+	if len(open) == 0 {
+		return false, nil
+	}
+	body, err := commentPayload(rendered)
+	if err != nil {
+		return false, err
+	}
+	g.logPayload(build, "comment", body)
+	for _, iss := range open {
+		if err := checkAPIURL(g.apiURL, iss.URL); err != nil {
+			log.Warningf("not closing failure issue #%d in %q: %v", iss.Number, repo, err)
+			continue
+		}
+		if err := g.doRequest(ctx, http.MethodPost, iss.URL+"/comments", body, nil); err != nil {
+			return true, fmt.Errorf("failed to comment recovery on failure issue #%d: %w%s", iss.Number, err, g.errorPayload("comment", body))
+		}
+		if err := g.closeIssue(ctx, iss, view); err != nil {
+			return true, fmt.Errorf("failed to close failure issue #%d: %w", iss.Number, err)
+		}
+		log.Infof("closed failure issue #%d in %q: Build %q of branch %q recovered", iss.Number, repo, build.Id, branch)
+	}
+	return true, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+const recoveryPayload = `{"title": "Recovered: {{.Build.Substitutions.BRANCH_NAME}}", "body": "{{.Build.Id}} is green again"}`
+
+func TestRecoveryTemplate(t *testing.T) {
+	type event struct {
+		branch string
+		status cbpb.Build_Status
+	}
+	for _, tc := range []struct {
+		name       string
+		events     []event
+		wantTitles []string
+	}{{
+		name:       "first-seen success isn't a recovery",
+		events:     []event{{"main", cbpb.Build_SUCCESS}},
+		wantTitles: []string{"Cloud Build [some-project]: SUCCESS"},
+	}, {
+		name:   "success after a failure is a recovery",
+		events: []event{{"main", cbpb.Build_FAILURE}, {"main", cbpb.Build_SUCCESS}, {"main", cbpb.Build_SUCCESS}},
+		wantTitles: []string{
+			"Cloud Build [some-project]: FAILURE",
+			"Recovered: main",
+			"Cloud Build [some-project]: SUCCESS",
+		},
+	}, {
+		name:   "branches are tracked separately",
+		events: []event{{"main", cbpb.Build_FAILURE}, {"dev", cbpb.Build_SUCCESS}, {"main", cbpb.Build_SUCCESS}},
+		wantTitles: []string{
+			"Cloud Build [some-project]: FAILURE",
+			"Cloud Build [some-project]: SUCCESS",
+			"Recovered: main",
+		},
+	}, {
+		name:   "builds without a branch aren't tracked",
+		events: []event{{"", cbpb.Build_FAILURE}, {"", cbpb.Build_SUCCESS}},
+		wantTitles: []string{
+			"Cloud Build [some-project]: FAILURE",
+			"Cloud Build [some-project]: SUCCESS",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"recoveryTemplate": recoveryPayload}, issuePayload, fg)
+
+			var gotTitles []string
+			for i, e := range tc.events {
+				build := &cbpb.Build{
+					Id:            fmt.Sprintf("build-%d", i),
+					ProjectId:     "some-project",
+					Status:        e.status,
+					Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": e.branch},
+				}
+				if err := n.SendNotification(context.Background(), build); err != nil {
+					t.Fatalf("SendNotification failed: %v", err)
+				}
+				title, _ := fg.bodies["POST /repos/somename/somerepo/issues"]["title"].(string)
+				gotTitles = append(gotTitles, title)
+			}
+			if diff := cmp.Diff(tc.wantTitles, gotTitles); diff != "" {
+				t.Errorf("unexpected issue titles (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCloseOnRecovery(t *testing.T) {
+	const (
+		search  = "GET /search/issues"
+		create  = "POST /repos/somename/somerepo/issues"
+		close   = "PATCH /repos/somename/somerepo/issues/7"
+		comment = "POST /repos/somename/somerepo/issues/3/comments"
+		closeF  = "PATCH /repos/somename/somerepo/issues/3"
+		lookup  = "GET /repos/somename/somerepo/commits/main"
+	)
+	marker := branchFailureMarker("somename/somerepo", "main")
+	openIssue := fmt.Sprintf(`{"items": [{"number": 3, "url": "https://api.github.com/repos/somename/somerepo/issues/3", "body": "failed\n\n<!-- %s -->"}]}`, marker)
+
+	for _, tc := range []struct {
+		name        string
+		delivery    map[string]interface{}
+		responses   map[string]fakeResponse
+		wantCalls   []string
+		wantComment bool
+		wantErr     bool
+	}{{
+		name:        "comments on and closes the open failure issue",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls:   []string{lookup, search, comment, closeF},
+		wantComment: true,
+	}, {
+		name:        "takes over closing from firstFailureOnly",
+		delivery:    map[string]interface{}{"firstFailureOnly": true},
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}},
+		wantCalls:   []string{lookup, search, comment, closeF},
+		wantComment: true,
+	}, {
+		name:      "creates the recovery issue without an open failure issue",
+		wantCalls: []string{lookup, search, create, close},
+	}, {
+		name:      "creates the recovery issue if the lookup fails",
+		responses: map[string]fakeResponse{search: {http.StatusUnprocessableEntity, `{}`}},
+		wantCalls: []string{lookup, search, create, close},
+	}, {
+		name:        "failed comment isn't retried as an issue",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}, comment: {http.StatusBadGateway, `{}`}},
+		wantCalls:   []string{lookup, search, comment},
+		wantComment: true,
+		wantErr:     true,
+	}, {
+		name:        "failed close isn't retried as an issue",
+		responses:   map[string]fakeResponse{search: {http.StatusOK, openIssue}, closeF: {http.StatusForbidden, `{}`}},
+		wantCalls:   []string{lookup, search, comment, closeF},
+		wantComment: true,
+		wantErr:     true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			delivery := map[string]interface{}{"recoveryTemplate": recoveryPayload, "closeOnRecovery": true}
+			for k, v := range tc.delivery {
+				delivery[k] = v
+			}
+			n := newTestNotifier(t, delivery, issuePayload, fg)
+			n.sleep = func(context.Context, time.Duration) error { return nil }
+
+			if err := n.recovery.store.Put(context.Background(), "branch/somename/somerepo@main", branchFailing, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			fg.responses = tc.responses
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_SUCCESS,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+			}
+			if err := n.SendNotification(context.Background(), build); (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+			if got, want := fg.bodies[comment]["body"], "some-build-id is green again"; tc.wantComment && got != want {
+				t.Errorf("recovery comment body = %v, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRecoveryRedeliveryAfterFailure(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{"recoveryTemplate": recoveryPayload, "skipCommitterLookup": true}, issuePayload, fg)
+
+	build := func(status cbpb.Build_Status) *cbpb.Build {
+		return &cbpb.Build{
+			Id:            "build-" + status.String(),
+			Status:        status,
+			Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+		}
+	}
+	if err := n.SendNotification(context.Background(), build(cbpb.Build_FAILURE)); err != nil {
+		t.Fatalf("SendNotification of the failure failed: %v", err)
+	}
+	fg.responses = map[string]fakeResponse{create: {http.StatusBadGateway, `{}`}}
+	if err := n.SendNotification(context.Background(), build(cbpb.Build_SUCCESS)); err == nil {
+		t.Fatal("SendNotification of the recovery succeeded despite the failed create, want an error")
+	}
+
+	// The redelivered recovery is still a recovery.
+	fg.responses = nil
+	if err := n.SendNotification(context.Background(), build(cbpb.Build_SUCCESS)); err != nil {
+		t.Fatalf("SendNotification of the redelivered recovery failed: %v", err)
+	}
+	if got, want := fg.bodies[create]["title"], "Recovered: main"; got != want {
+		t.Errorf("got issue title %v, want %q", got, want)
+	}
+}
+
+func TestCloseOnRecoveryMarksFailures(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{"recoveryTemplate": recoveryPayload, "closeOnRecovery": true}, issuePayload, fg)
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	body, _ := fg.bodies["POST /repos/somename/somerepo/issues"]["body"].(string)
+	if marker := branchFailureMarker("somename/somerepo", "main"); !strings.Contains(body, marker) {
+		t.Errorf("failure issue body %q doesn't contain the branch failure marker %q", body, marker)
+	}
+}
+
+func TestParseRecoveryPolicyErrors(t *testing.T) {
+	for _, delivery := range []map[string]interface{}{
+		{"closeOnRecovery": true},
+		{"closeOnRecovery": "yes", "recoveryTemplate": recoveryPayload},
+		{"recoveryTemplate": ""},
+		{"recoveryTemplate": []interface{}{recoveryPayload}},
+		{"recoveryTemplate": "{{.Build"},
+	} {
+		if _, err := parseRecoveryPolicy(delivery, new(notifiers.MemoryStateStore)); err == nil {
+			t.Errorf("parseRecoveryPolicy(%v) succeeded, want error", delivery)
+		}
+	}
+}
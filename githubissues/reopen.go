@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	log "github.com/golang/glog"
+)
+
+// reopenPayload is the JSON body of the PATCH that reopens an issue.
+var reopenPayload = []byte(`{"state": "open"}`)
+
+// latestFailureIssue returns the most recent failure issue of a trigger and branch, open or closed: the one recorded
+// when it was created, or else the newest that an issue search finds by its marker. It returns nil if there is none.
+func (g *githubissuesNotifier) latestFailureIssue(ctx context.Context, repo, marker string) (*issue, error) {
+	if number, ok := g.failureIssues.get(ctx, marker); ok {
+		var iss issue
+		err := g.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", g.repoURL(repo), number), nil, &iss)
+		if err == nil {
+			return &iss, nil
+		}
+		log.Warningf("failed to get failure issue #%d in %q, searching for it instead: %v", number, repo, err)
+	}
+	found, err := g.findIssues(ctx, repo, marker, false)
+	if err != nil {
+		return nil, err
+	}
+	var latest *issue
+	for _, iss := range found {
+		if latest == nil || iss.Number > latest.Number {
+			latest = iss
+		}
+	}
+	return latest, nil
+}
+
+// reopenMarker returns the marker of the failure issues that reopenFailureIssue reopens for the build: its branch's
+// failure marker if successful builds close failure issues by it (see marksFailures), or else its duplicateMarker,
+// which closeFixedIssues closes them by. It returns "" if the build has no such marker.
+func (g *githubissuesNotifier) reopenMarker(repo string, build *cbpb.Build) string {
+	if g.marksFailures() {
+		if branch := build.Substitutions["BRANCH_NAME"]; branch != "" {
+			return branchFailureMarker(repo, branch)
+		}
+		return ""
+	}
+	return duplicateMarker(repo, build)
+}
+
+// reopenFailureIssue reopens the closed failure issue of the failed build's trigger and branch and comments the body of
+// the rendered issue on it, instead of creating another issue, so a recurring failure keeps its discussion. The issue is
+// found by the same marker that closed it (see reopenMarker). It returns false, so the issue is created, if the latest
+// failure issue is still open, was closed as not planned, or can't be looked up. Failing to reopen or comment on it
+// fails the notification.
+func (g *githubissuesNotifier) reopenFailureIssue(ctx context.Context, build *cbpb.Build, repo string, rendered []byte) (bool, error) {
+	marker := g.reopenMarker(repo, build)
+	if marker == "" {
+		return false, nil
+	}
+	iss, err := g.latestFailureIssue(ctx, repo, marker)
+	if err != nil {
+		log.Warningf("failed to look up failure issues of Build %q's trigger and branch in %q, creating an issue: %v", build.Id, repo, err)
+		return false, nil
+	}
+	if iss == nil || iss.State != "closed" {
+		return false, nil
+	}
+	if iss.StateReason == "not_planned" {
+		log.Infof("not reopening failure issue #%d in %q, which was closed as not planned, creating an issue", iss.Number, repo)
+		return false, nil
+	}
+	if err := checkAPIURL(g.apiURL, iss.URL); err != nil {
+		log.Warningf("not reopening failure issue #%d in %q, creating an issue: %v", iss.Number, repo, err)
+		return false, nil
+	}
+	body, err := commentPayload(rendered)
+	if err != nil {
+		return false, err
+	}
+	err = g.withTokenFailover(ctx, fmt.Sprintf("reopening issue #%d", iss.Number), func(ctx context.Context) error {
+		return g.doRequest(ctx, http.MethodPatch, iss.URL, reopenPayload, nil)
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to reopen failure issue #%d: %w", iss.Number, err)
+	}
+	g.failureIssues.put(ctx, marker, int64(iss.Number))
+	log.Infof("reopened failure issue #%d in %q for Build %q", iss.Number, repo, build.Id)
+
+	g.logPayload(build, "comment", body)
+	if err := g.doRequest(ctx, http.MethodPost, iss.URL+"/comments", body, nil); err != nil {
+		return true, fmt.Errorf("failed to comment on reopened failure issue #%d: %w%s", iss.Number, err, g.errorPayload("comment", body))
+	}
+	return true, nil
+}
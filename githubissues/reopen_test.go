@@ -0,0 +1,221 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReopenClosedIssues(t *testing.T) {
+	const (
+		search  = "GET /search/issues"
+		create  = "POST /repos/somename/somerepo/issues"
+		reopen  = "PATCH /repos/somename/somerepo/issues/5"
+		comment = "POST /repos/somename/somerepo/issues/5/comments"
+		lookup  = "GET /repos/somename/somerepo/commits/main"
+	)
+	mainBuild := &cbpb.Build{BuildTriggerId: "1234", Substitutions: map[string]string{"BRANCH_NAME": "main"}}
+	marker := duplicateMarker("somename/somerepo", mainBuild)
+	failureIssue := func(number int, state, reason string) string {
+		return fmt.Sprintf(`{"number": %d, "url": "https://api.github.com/repos/somename/somerepo/issues/%d", "state": %q, "state_reason": %q, "body": "failed\n\n<!-- %s -->"}`,
+			number, number, state, reason, marker)
+	}
+	found := func(issues ...string) fakeResponse {
+		return fakeResponse{http.StatusOK, `{"items": [` + strings.Join(issues, ",") + `]}`}
+	}
+
+	for _, tc := range []struct {
+		name          string
+		status        cbpb.Build_Status
+		responses     map[string]fakeResponse
+		wantCalls     []string
+		wantReopen    bool
+		wantErr       bool
+		wantPermanent bool
+	}{{
+		name:      "first failure creates a marked issue",
+		status:    cbpb.Build_FAILURE,
+		wantCalls: []string{lookup, search, create},
+	}, {
+		name:       "recurring failure reopens the closed issue",
+		status:     cbpb.Build_FAILURE,
+		responses:  map[string]fakeResponse{search: found(failureIssue(5, "closed", "completed"))},
+		wantCalls:  []string{lookup, search, reopen, comment},
+		wantReopen: true,
+	}, {
+		name:       "newest closed issue is reopened",
+		status:     cbpb.Build_FAILURE,
+		responses:  map[string]fakeResponse{search: found(failureIssue(3, "closed", "completed"), failureIssue(5, "closed", "completed"))},
+		wantCalls:  []string{lookup, search, reopen, comment},
+		wantReopen: true,
+	}, {
+		name:      "open issue isn't reopened",
+		status:    cbpb.Build_FAILURE,
+		responses: map[string]fakeResponse{search: found(failureIssue(3, "closed", "completed"), failureIssue(5, "open", ""))},
+		wantCalls: []string{lookup, search, create},
+	}, {
+		name:      "issue closed as not planned isn't reopened",
+		status:    cbpb.Build_FAILURE,
+		responses: map[string]fakeResponse{search: found(failureIssue(5, "closed", "not_planned"))},
+		wantCalls: []string{lookup, search, create},
+	}, {
+		name:      "failed search creates an issue",
+		status:    cbpb.Build_FAILURE,
+		responses: map[string]fakeResponse{search: {http.StatusUnprocessableEntity, `{}`}},
+		wantCalls: []string{lookup, search, create},
+	}, {
+		name:   "failed reopen isn't retried as an issue",
+		status: cbpb.Build_FAILURE,
+		responses: map[string]fakeResponse{
+			search: found(failureIssue(5, "closed", "completed")),
+			reopen: {http.StatusForbidden, `{}`},
+		},
+		wantCalls:     []string{lookup, search, reopen},
+		wantErr:       true,
+		wantPermanent: true,
+	}, {
+		name:          "failed comment isn't retried as an issue",
+		status:        cbpb.Build_FAILURE,
+		responses:     map[string]fakeResponse{search: found(failureIssue(5, "closed", "completed")), comment: {http.StatusForbidden, `{}`}},
+		wantCalls:     []string{lookup, search, reopen, comment},
+		wantReopen:    true,
+		wantErr:       true,
+		wantPermanent: true,
+	}, {
+		name:       "comment failed by GitHub is returned for redelivery",
+		status:     cbpb.Build_FAILURE,
+		responses:  map[string]fakeResponse{search: found(failureIssue(5, "closed", "completed")), comment: {http.StatusBadGateway, `{}`}},
+		wantCalls:  []string{lookup, search, reopen, comment},
+		wantReopen: true,
+		wantErr:    true,
+	}, {
+		name:      "successes don't reopen issues",
+		status:    cbpb.Build_SUCCESS,
+		responses: map[string]fakeResponse{search: found(failureIssue(5, "closed", "completed"))},
+		wantCalls: []string{lookup, create, "PATCH /repos/somename/somerepo/issues/7"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: tc.responses}
+			n := newTestNotifier(t, map[string]interface{}{"reopenClosedIssues": true}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:             "some-build-id",
+				Status:         tc.status,
+				BuildTriggerId: "1234",
+				Substitutions:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+			}
+			err := n.SendNotification(context.Background(), build)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+			// GitHub's answer decides whether the failed reopen or comment is redelivered.
+			if err != nil && notifiers.IsPermanent(err) != tc.wantPermanent {
+				t.Errorf("SendNotification got error %v, want permanent: %t", err, tc.wantPermanent)
+			}
+
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+			}
+			if body, ok := fg.bodies[create]["body"].(string); ok && failed(tc.status) && !strings.Contains(body, marker) {
+				t.Errorf("created issue body %q lacks the marker %q", body, marker)
+			}
+			if !tc.wantReopen {
+				return
+			}
+			if got := fg.bodies[reopen]["state"]; got != "open" {
+				t.Errorf("got reopen state %v, want %q", got, "open")
+			}
+			if got := fg.bodies[comment]["body"]; !strings.HasPrefix(fmt.Sprint(got), "Cloud Build") {
+				t.Errorf("comment body = %v, want the rendered issue body", got)
+			}
+		})
+	}
+}
+
+func TestReopenRememberedIssue(t *testing.T) {
+	const (
+		get     = "GET /repos/somename/somerepo/issues/7"
+		reopen  = "PATCH /repos/somename/somerepo/issues/7"
+		comment = "POST /repos/somename/somerepo/issues/7/comments"
+	)
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, map[string]interface{}{"reopenClosedIssues": true, "skipCommitterLookup": true}, issuePayload, fg)
+	build := &cbpb.Build{
+		Id:             "some-build-id",
+		Status:         cbpb.Build_FAILURE,
+		BuildTriggerId: "1234",
+		Substitutions:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	// The created issue is remembered, so once it's closed the next failure reopens it without searching.
+	fg.calls = nil
+	fg.responses = map[string]fakeResponse{
+		get: {http.StatusOK, `{"number": 7, "url": "https://api.github.com/repos/somename/somerepo/issues/7", "state": "closed", "state_reason": "completed"}`},
+	}
+	build.Id = "other-build-id"
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{get, reopen, comment}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestReopenFirstFailureOnly(t *testing.T) {
+	const (
+		search  = "GET /search/issues"
+		reopen  = "PATCH /repos/somename/somerepo/issues/5"
+		comment = "POST /repos/somename/somerepo/issues/5/comments"
+	)
+	// The branch's failure issue was closed by a successful build of firstFailureOnly, which finds it by the branch's
+	// failure marker, so the issue lacks the trigger's duplicateMarker.
+	marker := branchFailureMarker("somename/somerepo", "main")
+	fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{
+		search: {http.StatusOK, fmt.Sprintf(`{"items": [{"number": 5, "url": "https://api.github.com/repos/somename/somerepo/issues/5", "state": "closed", "state_reason": "completed", "body": "failed\n\n<!-- %s -->"}]}`, marker)},
+	}}
+	// firstFailureOnly only searches for open issues, of which there are none.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/issues" && strings.Contains(r.URL.Query().Get("q"), "is:open") {
+			fmt.Fprint(w, `{"items": []}`)
+			return
+		}
+		fg.ServeHTTP(w, r)
+	})
+	n := newTestNotifier(t, map[string]interface{}{"reopenClosedIssues": true, "firstFailureOnly": true, "skipCommitterLookup": true}, issuePayload, h)
+
+	build := &cbpb.Build{
+		Id:             "some-build-id",
+		Status:         cbpb.Build_FAILURE,
+		BuildTriggerId: "1234",
+		Substitutions:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "BRANCH_NAME": "main"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{search, reopen, comment}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxRepos is the most repos that `githubRepo` may list.
+	maxRepos = 20
+	// maxRepoFanOut is the most repos that are notified of a build at once.
+	maxRepoFanOut = 4
+)
+
+// validRepo returns true iff the name has the `owner/repo` form.
+func validRepo(name string) bool {
+	parts := strings.Split(name, "/")
+	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
+}
+
+// parseRepos parses the `githubRepo` delivery config field when it's a list of repos.
+func parseRepos(l []interface{}) ([]string, error) {
+	if len(l) == 0 || len(l) > maxRepos {
+		return nil, fmt.Errorf("expected delivery config field `githubRepo` to list 1 to %d repos, got %d", maxRepos, len(l))
+	}
+	seen := map[string]bool{}
+	var repos []string
+	for _, e := range l {
+		name, _ := e.(string)
+		if !validRepo(name) {
+			return nil, fmt.Errorf("expected delivery config field `githubRepo` to list `owner/repo` names, got %v", e)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("delivery config field `githubRepo` lists %q more than once", name)
+		}
+		seen[name] = true
+		repos = append(repos, name)
+	}
+	return repos, nil
+}
+
+// repoErrors maps repos to the error notifying them.
+type repoErrors map[string]error
+
+func (e repoErrors) Error() string {
+	repos := make([]string, 0, len(e))
+	for r := range e {
+		repos = append(repos, r)
+	}
+	sort.Strings(repos)
+	msgs := make([]string, 0, len(repos))
+	for _, r := range repos {
+		msgs = append(msgs, fmt.Sprintf("repo %q: %v", r, e[r]))
+	}
+	return fmt.Sprintf("failed to notify %d repo(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// fanOut notifies each of the configured repos of the build, maxRepoFanOut at a time, each with its own copy of the
+// build. A failure for one repo doesn't stop the others; the failures are returned together as a repoErrors, which is
+// permanent iff all of them are. Repos that were notified are recorded in the state store, so a redelivered event only
+// notifies the repos that failed.
+func (g *githubissuesNotifier) fanOut(ctx context.Context, build *cbpb.Build) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = repoErrors{}
+		sem  = make(chan struct{}, maxRepoFanOut)
+	)
+	for _, repo := range g.githubRepos {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(repo string, build *cbpb.Build) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			key := fanOutKey(build, repo)
+			if _, ok, err := g.state.Get(ctx, key); err != nil {
+				log.Warningf("failed to check whether %q was notified of Build %q, notifying it: %v", repo, build.Id, err)
+			} else if ok {
+				log.V(2).Infof("%q was already notified of Build %q (status: %q), skipping it", repo, build.Id, build.Status)
+				return
+			}
+			if err := g.notifyRepo(ctx, build, repo); err != nil {
+				log.Warningf("failed to notify %q of Build %q: %v", repo, build.Id, err)
+				mu.Lock()
+				errs[repo] = err
+				mu.Unlock()
+				return
+			}
+			if err := g.state.Put(ctx, key, time.Now().UTC().Format(time.RFC3339), statusRetention); err != nil {
+				log.Warningf("failed to record that %q was notified of Build %q: %v", repo, build.Id, err)
+			}
+		}(repo, proto.Clone(build).(*cbpb.Build))
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		for _, err := range errs {
+			if !notifiers.IsPermanent(err) {
+				return errs
+			}
+		}
+		return notifiers.Permanent(errs)
+	}
+	return nil
+}
+
+// fanOutKey returns the state store key recording that the repo was notified of the build's current status.
+func fanOutKey(build *cbpb.Build, repo string) string {
+	return fmt.Sprintf("fanout/%s/%s/%s", build.Id, build.Status, repo)
+}
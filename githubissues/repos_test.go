@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFanOut(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	// Requests to repo-b fail without a response.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/repos/somename/repo-b/") {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+		fg.ServeHTTP(w, r)
+	})
+	n := newTestNotifier(t, map[string]interface{}{
+		"githubRepo": []interface{}{"somename/repo-a", "somename/repo-b", "somename/repo-c"},
+	}, issuePayload, h)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/library"},
+	}
+	var err error
+	logs := captureLogs(t, "0", func() {
+		err = n.SendNotification(context.Background(), build)
+	})
+
+	var re repoErrors
+	if !errors.As(err, &re) {
+		t.Fatalf("SendNotification() = %v, want a repoErrors", err)
+	}
+	if _, ok := re["somename/repo-b"]; !ok || len(re) != 1 {
+		t.Errorf("SendNotification() = %v, want an error for repo-b only", err)
+	}
+	calls := fg.gotCalls()
+	sort.Strings(calls)
+	if diff := cmp.Diff([]string{"POST /repos/somename/repo-a/issues", "POST /repos/somename/repo-c/issues"}, calls); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+	for repo, action := range map[string]string{"repo-a": "issue", "repo-b": "error", "repo-c": "issue"} {
+		if want := fmt.Sprintf("NOTIFY build=some-build-id status=FAILURE repo=somename/%s action=%s", repo, action); !strings.Contains(logs, want) {
+			t.Errorf("logs don't contain %q:\n%s", want, logs)
+		}
+	}
+	if got := build.Substitutions; len(got) != 1 {
+		t.Errorf("SendNotification modified the build's substitutions to %v", got)
+	}
+}
+
+func TestFanOutRedelivery(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	failB := true
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failB && strings.HasPrefix(r.URL.Path, "/repos/somename/repo-b/") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fg.ServeHTTP(w, r)
+	})
+	n := newTestNotifier(t, map[string]interface{}{
+		"githubRepo":  []interface{}{"somename/repo-a", "somename/repo-b"},
+		"maxAttempts": 1,
+	}, issuePayload, h)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/library"},
+	}
+	if err := n.SendNotification(context.Background(), build); err == nil {
+		t.Fatal("SendNotification succeeded although repo-b failed, want an error")
+	}
+
+	// The redelivered event only notifies repo-b, which failed before.
+	failB = false
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification of the redelivered event failed: %v", err)
+	}
+	calls := fg.gotCalls()
+	sort.Strings(calls)
+	if diff := cmp.Diff([]string{"POST /repos/somename/repo-a/issues", "POST /repos/somename/repo-b/issues"}, calls); diff != "" {
+		t.Errorf("unexpected GitHub API calls (-want +got):\n%s", diff)
+	}
+
+	// A later status of the same build notifies every repo again.
+	build.Status = cbpb.Build_SUCCESS
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification of the SUCCESS event failed: %v", err)
+	}
+	if got := len(fg.gotCalls()); got <= len(calls) {
+		t.Errorf("got %d GitHub API calls after the SUCCESS event, want more than %d", got, len(calls))
+	}
+}
+
+func TestParseRepos(t *testing.T) {
+	tooMany := make([]interface{}, maxRepos+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("somename/repo-%d", i)
+	}
+	for _, tc := range []struct {
+		name    string
+		l       []interface{}
+		wantErr bool
+	}{
+		{name: "valid", l: []interface{}{"somename/repo-a", "somename/repo-b"}},
+		{name: "empty", l: []interface{}{}, wantErr: true},
+		{name: "too many", l: tooMany, wantErr: true},
+		{name: "malformed", l: []interface{}{"repo-a"}, wantErr: true},
+		{name: "not a string", l: []interface{}{3}, wantErr: true},
+		{name: "duplicate", l: []interface{}{"somename/repo-a", "somename/repo-a"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseRepos(tc.l); (err != nil) != tc.wantErr {
+				t.Errorf("parseRepos(%v) = %v, want error: %t", tc.l, err, tc.wantErr)
+			}
+		})
+	}
+}
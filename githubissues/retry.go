@@ -0,0 +1,278 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+const (
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = time.Second
+	defaultMaxRetryWait = time.Minute
+)
+
+// retryPolicy decides whether and how failed GitHub API requests are retried.
+//
+// Only requests that are safe to repeat are retried: GET, HEAD, PUT, PATCH, and DELETE always are, but POST creates
+// something new each time it succeeds, so a POST whose response was lost would create a duplicate if retried. POSTs are
+// therefore only retried when retryCreates is set, i.e. when the operator has made creates idempotent, or when the
+// connection failed before the request was sent.
+// The zero value makes exactly one attempt.
+type retryPolicy struct {
+	// maxAttempts is the total number of attempts per request, including the first.
+	maxAttempts int
+	// backoff is the delay before the first retry, doubled before each further retry.
+	backoff time.Duration
+	// maxRetryWait is the longest that a response's Retry-After or X-RateLimit-Reset header is waited out for. Requests
+	// that are asked to wait longer aren't retried.
+	maxRetryWait time.Duration
+	// retryCreates allows POST requests to be retried.
+	retryCreates bool
+	// budget, if positive, caps the total number of attempts of all requests made for one build event; see withBudget.
+	budget int
+	// sleep waits between attempts. It defaults to sleepCtx if nil.
+	sleep func(context.Context, time.Duration) error
+	// jitter randomizes a backoff delay. It defaults to equalJitter if nil.
+	jitter func(time.Duration) time.Duration
+}
+
+// equalJitter returns a random delay between half of d and d, so that notifiers that failed together don't retry in
+// lockstep.
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter returns how long the response headers ask to wait before retrying: the Retry-After header, in seconds or
+// as an HTTP date, or, if the rate limit is exhausted, the time until the X-RateLimit-Reset epoch second. It returns 0
+// if the headers don't say.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	var d time.Duration
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			d = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			d = t.Sub(now)
+		}
+	} else if h.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			d = time.Unix(reset, 0).Sub(now)
+		}
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// idempotent returns true iff requests with the given method may be retried under the policy.
+func (p *retryPolicy) idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.retryCreates
+	default:
+		return false
+	}
+}
+
+// transient returns true iff err may not recur when the request is retried: a 429 or 5xx GitHub API response, a 403
+// that says when the rate limit frees up, or a network error such as a timeout, a reset or refused connection, or a
+// failed DNS lookup.
+func transient(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests || se.code >= 500 || (se.code == http.StatusForbidden && se.retryAfter > 0)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if notSent(err) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// attemptTimedOut returns true iff err is an attempt's own request timeout expiring while ctx, the context of the
+// request as a whole, is still live. The next attempt gets a fresh timeout, so it may well succeed.
+func attemptTimedOut(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+}
+
+// permanent returns true iff err won't go away by redelivering the build event: a template that fails to render, or a
+// 4xx GitHub API response other than a rejected token, a timeout, or an exhausted rate limit, e.g. a 422 for an invalid
+// payload or a 404 for a missing repo.
+func permanent(err error) bool {
+	var te *notifiers.TemplateError
+	if errors.As(err, &te) {
+		return true
+	}
+	var se *statusError
+	if !errors.As(err, &se) || se.code < 400 || se.code > 499 || se.rateLimited {
+		return false
+	}
+	switch se.code {
+	case http.StatusUnauthorized, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
+// responseLost returns true iff err is a failed request that may have reached GitHub without its response coming back,
+// e.g. one that timed out. Requests that GitHub answered with an error status, or that were never sent, didn't create
+// anything.
+func responseLost(err error) bool {
+	if err == nil || notSent(err) {
+		return false
+	}
+	var se *statusError
+	return !errors.As(err, &se)
+}
+
+// notSent returns true iff err shows that the request never reached GitHub because the connection could not be
+// established, e.g. due to a failed DNS lookup or a refused connection. Such requests are safe to retry regardless of
+// their method.
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// errRetryBudgetExhausted is returned for requests that are not attempted because the build event's retry budget is
+// used up.
+var errRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// retryBudget is the number of attempts that requests made for one build event have left, shared by all of them.
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// take uses up one attempt, returning false if there are none left. A nil budget is unlimited.
+func (b *retryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+type retryBudgetKey struct{}
+
+// withBudget returns a context carrying a fresh retry budget of the policy's size, which do draws every attempt of every
+// request made with the context from, so that one slow build event can't cause a storm of retries across its requests.
+// The context is returned as-is if the policy has no budget.
+func (p *retryPolicy) withBudget(ctx context.Context) context.Context {
+	if p.budget <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: p.budget})
+}
+
+// budgetFrom returns the retry budget carried by ctx, or nil if it carries none.
+func budgetFrom(ctx context.Context) *retryBudget {
+	b, _ := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	return b
+}
+
+// do calls attempt until it succeeds, it fails with a non-transient error, or the policy's attempts are used up. Requests
+// whose method is not idempotent under the policy are only retried if they were never sent. Each attempt is drawn from
+// ctx's retry budget, if any; a request is not attempted at all once the budget is used up.
+//
+// Retries wait out the jittered backoff, or as long as the failed response asked with its Retry-After or
+// X-RateLimit-Reset header if that is longer. Requests asked to wait longer than maxRetryWait aren't retried.
+func (p *retryPolicy) do(ctx context.Context, method, url string, attempt func() error) error {
+	max := p.maxAttempts
+	if max < 1 {
+		max = 1
+	}
+	idempotent := p.idempotent(method)
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = sleepCtx
+	}
+	jitter := p.jitter
+	if jitter == nil {
+		jitter = equalJitter
+	}
+
+	budget := budgetFrom(ctx)
+	if !budget.take() {
+		return fmt.Errorf("not attempting %s %q: %w", method, url, errRetryBudgetExhausted)
+	}
+
+	delay := p.backoff
+	var err error
+	for i := 1; ; i++ {
+		if err = attempt(); err == nil || i >= max || !(transient(err) || attemptTimedOut(ctx, err)) || !(idempotent || notSent(err)) {
+			return err
+		}
+		wait := jitter(delay)
+		var se *statusError
+		if errors.As(err, &se) && se.retryAfter > wait {
+			if se.retryAfter > p.maxRetryWait {
+				log.Warningf("attempt %d/%d of %s %q failed and GitHub asked to wait %v, longer than %v: %v", i, max, method, url, se.retryAfter, p.maxRetryWait, err)
+				return err
+			}
+			wait = se.retryAfter
+		}
+		if !budget.take() {
+			log.Warningf("attempt %d/%d of %s %q failed and the retry budget is exhausted: %v", i, max, method, url, err)
+			return err
+		}
+		log.Warningf("attempt %d/%d of %s %q failed, retrying in %v: %v", i, max, method, url, wait, err)
+		if serr := sleep(ctx, wait); serr != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// sleepCtx waits for d or until ctx is done, whichever comes first, and returns ctx's error in the latter case.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,676 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+// flakyGitHub fails the first `failures` requests with the given status code and then behaves like a fakeGitHub.
+type flakyGitHub struct {
+	fakeGitHub
+	code     int
+	headers  map[string]string // Set on failed responses.
+	failures int
+	mu       sync.Mutex
+	failed   int
+}
+
+func (f *flakyGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	fail := f.failed < f.failures
+	if fail {
+		f.failed++
+	}
+	f.mu.Unlock()
+	if fail {
+		f.fakeGitHub.mu.Lock()
+		f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+		f.fakeGitHub.mu.Unlock()
+		for k, v := range f.headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(f.code)
+		return
+	}
+	f.fakeGitHub.ServeHTTP(w, r)
+}
+
+// noSleep records the delays it is asked to wait without waiting.
+type noSleep struct {
+	delays []time.Duration
+}
+
+func (s *noSleep) sleep(_ context.Context, d time.Duration) error {
+	s.delays = append(s.delays, d)
+	return nil
+}
+
+// noJitter returns backoff delays unchanged.
+func noJitter(d time.Duration) time.Duration {
+	return d
+}
+
+func TestRetryCreateIssue(t *testing.T) {
+	failedBuild := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+	}
+	const create = "POST /repos/somename/somerepo/issues"
+
+	for _, tc := range []struct {
+		name      string
+		delivery  map[string]interface{}
+		code      int
+		wantCalls []string
+		wantErr   bool
+	}{{
+		name:      "create is not retried by default",
+		code:      http.StatusBadGateway,
+		wantCalls: []string{create},
+		wantErr:   true,
+	}, {
+		name:      "create is retried when idempotent",
+		delivery:  map[string]interface{}{"idempotentCreate": true},
+		code:      http.StatusBadGateway,
+		wantCalls: []string{create, create},
+	}, {
+		name:      "rate limited create is retried when idempotent",
+		delivery:  map[string]interface{}{"idempotentCreate": true},
+		code:      http.StatusTooManyRequests,
+		wantCalls: []string{create, create},
+	}, {
+		name:      "client errors are not retried",
+		delivery:  map[string]interface{}{"idempotentCreate": true},
+		code:      http.StatusUnprocessableEntity,
+		wantCalls: []string{create},
+		wantErr:   true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &flakyGitHub{fakeGitHub: fakeGitHub{t: t, issue: `{}`}, code: tc.code, failures: 1}
+			n := newTestNotifier(t, tc.delivery, issuePayload, fg)
+			n.retry.sleep = new(noSleep).sleep
+
+			if err := n.SendNotification(context.Background(), failedBuild); (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryCreateIssueFindsEarlierAttempt(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/issues"
+		search = "GET /search/issues"
+	)
+	marker := createMarker("somename/somerepo", "some-build-id", "failed")
+	for _, tc := range []struct {
+		name      string
+		found     string
+		wantCalls []string
+	}{{
+		name:      "earlier attempt created the issue",
+		found:     fmt.Sprintf(`{"number": 5, "body": "b\n\n<!-- %s -->"}`, marker),
+		wantCalls: []string{create, search},
+	}, {
+		name:      "earlier attempt created nothing",
+		wantCalls: []string{create, search, create},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue, responses: map[string]fakeResponse{
+				search: {http.StatusOK, `{"items": [` + tc.found + `]}`},
+			}}
+			// The first create's response is lost: it times out.
+			var mu sync.Mutex
+			creates := 0
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				creates++
+				lost := r.Method == http.MethodPost && creates == 1
+				mu.Unlock()
+				if lost {
+					// Record the request and drain its body so the server notices the client giving up.
+					fg.ServeHTTP(httptest.NewRecorder(), r)
+					io.Copy(io.Discard, r.Body)
+					<-r.Context().Done()
+					return
+				}
+				fg.ServeHTTP(w, r)
+			})
+			n := newTestNotifier(t, map[string]interface{}{"idempotentCreate": true, "createTimeout": "50ms", "skipCommitterLookup": true}, `{"title": "failed", "body": "b"}`, h)
+			n.retry.sleep = new(noSleep).sleep
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCalls, fg.gotCalls()); diff != "" {
+				t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+			}
+			if body, _ := fg.bodies[create]["body"].(string); !strings.Contains(body, marker) {
+				t.Errorf("created issue body %q lacks the marker %q", body, marker)
+			}
+		})
+	}
+}
+
+func TestRetryCreateIssueWithoutLostResponse(t *testing.T) {
+	// GitHub answered the failed create, so it created nothing and the retry doesn't look for it.
+	fg := &flakyGitHub{fakeGitHub: fakeGitHub{t: t, issue: createdIssue}, code: http.StatusBadGateway, failures: 1}
+	n := newTestNotifier(t, map[string]interface{}{"idempotentCreate": true, "skipCommitterLookup": true}, `{"title": "failed", "body": "b"}`, fg)
+	n.retry.sleep = new(noSleep).sleep
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	const create = "POST /repos/somename/somerepo/issues"
+	if diff := cmp.Diff([]string{create, create}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+	}
+	marker := createMarker("somename/somerepo", "some-build-id", "failed")
+	if body, _ := fg.bodies[create]["body"].(string); !strings.Contains(body, marker) {
+		t.Errorf("created issue body %q lacks the marker %q", body, marker)
+	}
+}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	const lookup = "GET /repos/somename/somerepo/commits/main"
+	for _, tc := range []struct {
+		name       string
+		delivery   map[string]interface{}
+		failures   int
+		wantCalls  int
+		wantErr    bool
+		wantDelays []time.Duration
+	}{{
+		name:       "succeeds after retries",
+		failures:   2,
+		wantCalls:  3,
+		wantDelays: []time.Duration{time.Second, 2 * time.Second},
+	}, {
+		name:       "gives up after max attempts",
+		delivery:   map[string]interface{}{"maxAttempts": 2, "retryBackoff": "10ms"},
+		failures:   5,
+		wantCalls:  2,
+		wantErr:    true,
+		wantDelays: []time.Duration{10 * time.Millisecond},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &flakyGitHub{fakeGitHub: fakeGitHub{t: t}, code: http.StatusServiceUnavailable, failures: tc.failures}
+			n := newTestNotifier(t, tc.delivery, issuePayload, fg)
+			s := new(noSleep)
+			n.retry.sleep = s.sleep
+			n.retry.jitter = noJitter
+
+			err := n.doRequest(context.Background(), http.MethodGet, fmt.Sprintf("%s/repos/somename/somerepo/commits/main", defaultGitHubAPIURL), nil, nil)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("doRequest got error %v, want error: %t", err, tc.wantErr)
+			}
+			if got := len(fg.gotCalls()); got != tc.wantCalls {
+				t.Errorf("got %d calls to %q, want %d", got, lookup, tc.wantCalls)
+			}
+			if diff := cmp.Diff(tc.wantDelays, s.delays); diff != "" {
+				t.Errorf("unexpected backoff delays (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		code       int
+		headers    map[string]string
+		wantCalls  int
+		wantDelays []time.Duration
+	}{{
+		name:       "waits out Retry-After",
+		code:       http.StatusTooManyRequests,
+		headers:    map[string]string{"Retry-After": "5"},
+		wantCalls:  2,
+		wantDelays: []time.Duration{5 * time.Second},
+	}, {
+		name:       "backoff longer than Retry-After",
+		code:       http.StatusServiceUnavailable,
+		headers:    map[string]string{"Retry-After": "0"},
+		wantCalls:  2,
+		wantDelays: []time.Duration{time.Second},
+	}, {
+		name:      "Retry-After longer than maxRetryWait",
+		code:      http.StatusTooManyRequests,
+		headers:   map[string]string{"Retry-After": "120"},
+		wantCalls: 1,
+	}, {
+		name:       "secondary rate limit",
+		code:       http.StatusForbidden,
+		headers:    map[string]string{"Retry-After": "3"},
+		wantCalls:  2,
+		wantDelays: []time.Duration{3 * time.Second},
+	}, {
+		name:      "exhausted rate limit without a reset",
+		code:      http.StatusForbidden,
+		headers:   map[string]string{"X-RateLimit-Remaining": "0"},
+		wantCalls: 1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &flakyGitHub{fakeGitHub: fakeGitHub{t: t}, code: tc.code, headers: tc.headers, failures: 1}
+			n := newTestNotifier(t, nil, issuePayload, fg)
+			s := new(noSleep)
+			n.retry.sleep = s.sleep
+			n.retry.jitter = noJitter
+
+			n.doRequest(context.Background(), http.MethodGet, fmt.Sprintf("%s/repos/somename/somerepo/commits/main", defaultGitHubAPIURL), nil, nil)
+			if got := len(fg.gotCalls()); got != tc.wantCalls {
+				t.Errorf("got %d calls, want %d", got, tc.wantCalls)
+			}
+			if diff := cmp.Diff(tc.wantDelays, s.delays); diff != "" {
+				t.Errorf("unexpected retry delays (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryAfterHeaders(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{name: "none"},
+		{name: "seconds", headers: map[string]string{"Retry-After": "30"}, want: 30 * time.Second},
+		{name: "HTTP date", headers: map[string]string{"Retry-After": now.Add(time.Minute).Format(http.TimeFormat)}, want: time.Minute},
+		{name: "past HTTP date", headers: map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}},
+		{name: "malformed", headers: map[string]string{"Retry-After": "soon"}},
+		{
+			name:    "rate limit reset",
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": fmt.Sprint(now.Add(90 * time.Second).Unix())},
+			want:    90 * time.Second,
+		},
+		{
+			name:    "rate limit not exhausted",
+			headers: map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": fmt.Sprint(now.Add(90 * time.Second).Unix())},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tc.headers {
+				h.Set(k, v)
+			}
+			if got := retryAfter(h, now); got != tc.want {
+				t.Errorf("retryAfter(%v) = %v, want %v", tc.headers, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if got := equalJitter(time.Second); got < time.Second/2 || got > time.Second {
+			t.Fatalf("equalJitter(1s) = %v, want between 500ms and 1s", got)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	fg := &flakyGitHub{fakeGitHub: fakeGitHub{t: t}, code: http.StatusServiceUnavailable, failures: 10}
+	n := newTestNotifier(t, map[string]interface{}{"maxAttempts": 3, "retryBudget": 4}, issuePayload, fg)
+	n.retry.sleep = new(noSleep).sleep
+
+	ctx := n.retry.withBudget(context.Background())
+	url := fmt.Sprintf("%s/repos/somename/somerepo/commits/main", defaultGitHubAPIURL)
+	for i, wantCalls := range []int{3, 4, 4} {
+		if err := n.doRequest(ctx, http.MethodGet, url, nil, nil); err == nil {
+			t.Errorf("request %d unexpectedly succeeded", i)
+		}
+		if got := len(fg.gotCalls()); got != wantCalls {
+			t.Errorf("got %d calls after request %d, want %d", got, i, wantCalls)
+		}
+	}
+	if err := n.doRequest(ctx, http.MethodGet, url, nil, nil); !errors.Is(err, errRetryBudgetExhausted) {
+		t.Errorf("got error %v, want %v", err, errRetryBudgetExhausted)
+	}
+
+	// A fresh budget is used for every build event.
+	if err := n.doRequest(n.retry.withBudget(context.Background()), http.MethodGet, url, nil, nil); err == nil {
+		t.Error("request unexpectedly succeeded")
+	}
+	if got := len(fg.gotCalls()); got != 7 {
+		t.Errorf("got %d calls with a fresh budget, want 7", got)
+	}
+}
+
+func TestRetryBudgetSendNotification(t *testing.T) {
+	fg := &flakyGitHub{fakeGitHub: fakeGitHub{t: t, issue: `{}`}, code: http.StatusBadGateway, failures: 1}
+	n := newTestNotifier(t, map[string]interface{}{"idempotentCreate": true, "retryBudget": 1}, issuePayload, fg)
+	n.retry.sleep = new(noSleep).sleep
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+	}
+	// The budget is spent, so the failed create is returned for redelivery instead of retried.
+	if err := n.SendNotification(context.Background(), build); err == nil || permanent(err) {
+		t.Fatalf("SendNotification got error %v, want a retryable error", err)
+	}
+	if diff := cmp.Diff([]string{"POST /repos/somename/somerepo/issues"}, fg.gotCalls()); diff != "" {
+		t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetryPolicyIdempotent(t *testing.T) {
+	p := &retryPolicy{}
+	for _, m := range []string{http.MethodGet, http.MethodPatch, http.MethodPut, http.MethodDelete} {
+		if !p.idempotent(m) {
+			t.Errorf("idempotent(%q) = false, want true", m)
+		}
+	}
+	if p.idempotent(http.MethodPost) {
+		t.Errorf("idempotent(POST) = true without retryCreates, want false")
+	}
+	p.retryCreates = true
+	if !p.idempotent(http.MethodPost) {
+		t.Errorf("idempotent(POST) = false with retryCreates, want true")
+	}
+}
+
+// failingTransport fails a request with each of errs in turn and then sends requests via next.
+type failingTransport struct {
+	errs     []error
+	next     http.RoundTripper
+	attempts int
+}
+
+func (f *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.attempts++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return f.next.RoundTrip(req)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryNetworkErrors(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	noHost := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "api.github.com", IsTemporary: true}}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	for _, tc := range []struct {
+		name         string
+		method       string
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+	}{{
+		name:         "connection refused then success",
+		method:       http.MethodGet,
+		errs:         []error{refused},
+		wantAttempts: 2,
+	}, {
+		name:         "DNS failures then success",
+		method:       http.MethodGet,
+		errs:         []error{noHost, noHost},
+		wantAttempts: 3,
+	}, {
+		name:         "timeout then success",
+		method:       http.MethodPatch,
+		errs:         []error{timeoutError{}},
+		wantAttempts: 2,
+	}, {
+		name:         "connection reset then success",
+		method:       http.MethodGet,
+		errs:         []error{reset},
+		wantAttempts: 2,
+	}, {
+		name:         "unsent create is retried",
+		method:       http.MethodPost,
+		errs:         []error{refused},
+		wantAttempts: 2,
+	}, {
+		name:         "create whose connection was reset is not retried",
+		method:       http.MethodPost,
+		errs:         []error{reset},
+		wantAttempts: 1,
+		wantErr:      true,
+	}, {
+		name:         "persistent failure gives up",
+		method:       http.MethodGet,
+		errs:         []error{refused, refused, refused},
+		wantAttempts: 3,
+		wantErr:      true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNotifier(t, nil, issuePayload, &fakeGitHub{t: t})
+			n.retry.sleep = new(noSleep).sleep
+			ft := &failingTransport{errs: tc.errs, next: n.httpClient.Transport}
+			n.httpClient = &http.Client{Transport: ft}
+
+			err := n.doRequest(context.Background(), tc.method, fmt.Sprintf("%s/repos/somename/somerepo/issues", defaultGitHubAPIURL), []byte(`{}`), nil)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("doRequest got error %v, want error: %t", err, tc.wantErr)
+			}
+			if ft.attempts != tc.wantAttempts {
+				t.Errorf("got %d attempts, want %d", ft.attempts, tc.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestPermanentIssueCreateErrors(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	for _, tc := range []struct {
+		name          string
+		response      fakeResponse
+		wantErr       bool
+		wantPermanent bool
+	}{{
+		name:     "created",
+		response: fakeResponse{http.StatusCreated, createdIssue},
+	}, {
+		name:     "server error",
+		response: fakeResponse{http.StatusInternalServerError, `{}`},
+		wantErr:  true,
+	}, {
+		name:     "rate limited",
+		response: fakeResponse{http.StatusTooManyRequests, `{}`},
+		wantErr:  true,
+	}, {
+		name:          "invalid payload",
+		response:      fakeResponse{http.StatusUnprocessableEntity, `{}`},
+		wantErr:       true,
+		wantPermanent: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{create: tc.response}}
+			n := newTestNotifier(t, map[string]interface{}{"maxAttempts": 1, "skipCommitterLookup": true}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo"},
+			}
+			err := n.SendNotification(context.Background(), build)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+			if got := notifiers.IsPermanent(err); got != tc.wantPermanent {
+				t.Errorf("SendNotification error %v is permanent: %t, want %t", err, got, tc.wantPermanent)
+			}
+		})
+	}
+}
+
+func TestPermanentErrors(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/statuses/abc123"
+	for _, tc := range []struct {
+		name     string
+		tmpl     string
+		response fakeResponse
+		repos    []interface{}
+		// otherResponse is the response for the commit status in the second of the repos.
+		otherResponse fakeResponse
+		wantErr       bool
+		wantPermanent bool
+	}{{
+		name:     "success",
+		response: fakeResponse{http.StatusCreated, `{}`},
+	}, {
+		name:          "invalid payload",
+		response:      fakeResponse{http.StatusUnprocessableEntity, `{}`},
+		wantErr:       true,
+		wantPermanent: true,
+	}, {
+		name:          "missing repo",
+		response:      fakeResponse{http.StatusNotFound, `{}`},
+		wantErr:       true,
+		wantPermanent: true,
+	}, {
+		name:     "rejected token",
+		response: fakeResponse{http.StatusUnauthorized, `{}`},
+		wantErr:  true,
+	}, {
+		name:     "server error",
+		response: fakeResponse{http.StatusBadGateway, `{}`},
+		wantErr:  true,
+	}, {
+		name:          "broken template",
+		tmpl:          `{"title": "{{.Build.Missing}}"}`,
+		wantErr:       true,
+		wantPermanent: true,
+	}, {
+		name:          "permanent for every repo",
+		response:      fakeResponse{http.StatusUnprocessableEntity, `{}`},
+		repos:         []interface{}{"somename/somerepo", "somename/other"},
+		otherResponse: fakeResponse{http.StatusNotFound, `{}`},
+		wantErr:       true,
+		wantPermanent: true,
+	}, {
+		name:          "retryable for some repo",
+		response:      fakeResponse{http.StatusUnprocessableEntity, `{}`},
+		repos:         []interface{}{"somename/somerepo", "somename/other"},
+		otherResponse: fakeResponse{http.StatusServiceUnavailable, `{}`},
+		wantErr:       true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{
+				create: tc.response,
+				"POST /repos/somename/other/statuses/abc123": tc.otherResponse,
+			}}
+			delivery := map[string]interface{}{"target": "commitStatus", "maxAttempts": 1}
+			if tc.repos != nil {
+				delivery["githubRepo"] = tc.repos
+			}
+			tmpl := tc.tmpl
+			if tmpl == "" {
+				tmpl = issuePayload
+			}
+			n := newTestNotifier(t, delivery, tmpl, fg)
+
+			build := &cbpb.Build{
+				Id:            "some-build-id",
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "COMMIT_SHA": "abc123"},
+			}
+			err := n.SendNotification(context.Background(), build)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SendNotification got error %v, want error: %t", err, tc.wantErr)
+			}
+			if got := notifiers.IsPermanent(err); got != tc.wantPermanent {
+				t.Errorf("SendNotification error %v is permanent: %t, want %t", err, got, tc.wantPermanent)
+			}
+		})
+	}
+}
+
+func TestRetryRequestTimeout(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	gotAttempts := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		slow := attempts == 1
+		mu.Unlock()
+		if slow {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, `{"sha": "abc"}`)
+	})
+	n := newTestNotifier(t, map[string]interface{}{requestTimeoutField: "50ms"}, issuePayload, h)
+	n.retry.sleep = new(noSleep).sleep
+
+	if err := n.doRequest(context.Background(), http.MethodGet, fmt.Sprintf("%s/repos/somename/somerepo/commits/main", defaultGitHubAPIURL), nil, nil); err != nil {
+		t.Errorf("doRequest failed after a timed out first attempt: %v", err)
+	}
+	if got := gotAttempts(); got != 2 {
+		t.Errorf("got %d attempts, want 2", got)
+	}
+
+	// A request whose own context is done isn't retried.
+	mu.Lock()
+	attempts = 0
+	mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	n.timeouts.request = 0
+	if err := n.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/repos/somename/somerepo/commits/main", defaultGitHubAPIURL), nil, nil); err == nil {
+		t.Error("doRequest succeeded after its context's deadline, want an error")
+	}
+	if got := gotAttempts(); got != 1 {
+		t.Errorf("got %d attempts after the context's deadline, want 1", got)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	log "github.com/golang/glog"
+)
+
+// patternRule maps the strings matching pattern to value, e.g. a branch to a label.
+type patternRule struct {
+	pattern *regexp.Regexp
+	value   string
+}
+
+// parsePatternRules parses the given delivery config field, a map of regular expressions to non-empty strings of the
+// given kind (e.g. "label"). The rules are sorted by pattern so they're applied in a stable order.
+func parsePatternRules(field, kind string, v interface{}) ([]patternRule, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty map of patterns to %ss, got %v", field, kind, v)
+	}
+	var rules []patternRule
+	for k, val := range m {
+		p, ok := k.(string)
+		if !ok || p == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to have non-empty string patterns, got %v", field, k)
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile delivery config field %q pattern %q: %w", field, p, err)
+		}
+		vs, ok := val.(string)
+		if !ok || vs == "" {
+			return nil, fmt.Errorf("expected delivery config field %q pattern %q to map to a non-empty %s, got %v", field, p, kind, val)
+		}
+		rules = append(rules, patternRule{pattern: re, value: vs})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].pattern.String() < rules[j].pattern.String() })
+	return rules, nil
+}
+
+// mergeListField adds the values missing from the list of strings at key (e.g. `labels`) of the rendered issue.
+func mergeListField(rendered []byte, key string, values []string) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to add %s to, got %q", key, rendered)
+	}
+	var merged []interface{}
+	seen := map[string]bool{}
+	if existing, ok := fields[key]; ok && existing != nil {
+		list, ok := existing.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected the rendered issue's %s to be a list, got %v", key, existing)
+		}
+		for _, e := range list {
+			if s, ok := e.(string); ok {
+				seen[s] = true
+			}
+			merged = append(merged, e)
+		}
+	}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			merged = append(merged, v)
+		}
+	}
+	fields[key] = merged
+	return json.Marshal(fields)
+}
+
+// GitHub's limits on the labels and assignees of an issue, which maxLabels and maxAssignees can only lower.
+const (
+	githubMaxLabels    = 100
+	githubMaxAssignees = 10
+)
+
+// parseListCap parses the optional delivery config field capping a list of issue fields, which defaults to and may not
+// exceed GitHub's limit.
+func parseListCap(delivery map[string]interface{}, field string, limit int) (int, error) {
+	if _, ok := delivery[field]; !ok {
+		return limit, nil
+	}
+	n, err := getIntField(delivery, field)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > limit {
+		return 0, fmt.Errorf("expected delivery config field %q to be between 1 and %d, got %d", field, limit, n)
+	}
+	return n, nil
+}
+
+// capListFields truncates the labels and assignees of the rendered issue to the notifier's caps, keeping the first
+// ones, i.e. those that the template sets before those derived from rules, and logging the dropped ones.
+func (g *githubissuesNotifier) capListFields(buildID string, rendered []byte) ([]byte, error) {
+	var err error
+	if rendered, err = capListField(buildID, rendered, "labels", g.maxLabels); err != nil {
+		return nil, err
+	}
+	return capListField(buildID, rendered, "assignees", g.maxAssignees)
+}
+
+// capListField truncates the list at key of the rendered issue to max entries. Issues without such a list are returned
+// as-is.
+func capListField(buildID string, rendered []byte, key string, max int) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to cap %s of, got %q", key, rendered)
+	}
+	list, ok := fields[key].([]interface{})
+	if !ok || max <= 0 || len(list) <= max {
+		return rendered, nil
+	}
+	log.Warningf("dropping %s %v of the issue for Build %q, over the limit of %d", key, list[max:], buildID, max)
+	fields[key] = list[:max]
+	return json.Marshal(fields)
+}
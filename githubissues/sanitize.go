@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"google.golang.org/protobuf/proto"
+)
+
+// markdownSanitizer escapes Markdown in the user-controlled values that templates interpolate, such as commit messages
+// and committer logins, so they render as the literal text.
+type markdownSanitizer struct {
+	// userDefined sanitizes all user-defined (`_`-prefixed) substitutions, GH_COMMITTER_LOGIN, and params.
+	userDefined bool
+	// substitutions are the names of further substitutions to sanitize.
+	substitutions map[string]bool
+}
+
+// parseSanitizeMarkdown parses the `sanitizeMarkdown` delivery config field, either `true` or a non-empty list of
+// substitution names. It returns nil if the field is `false`.
+func parseSanitizeMarkdown(v interface{}) (*markdownSanitizer, error) {
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		return &markdownSanitizer{userDefined: true}, nil
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		subs := map[string]bool{}
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("expected delivery config field %q to list substitution names like %q, got %v", sanitizeMarkdownField, "_COMMIT_MESSAGE", e)
+			}
+			subs[s] = true
+		}
+		return &markdownSanitizer{substitutions: subs}, nil
+	}
+	return nil, fmt.Errorf("expected delivery config field %q to be a boolean or a non-empty list of substitution names, got %v", sanitizeMarkdownField, v)
+}
+
+// sanitizes returns true iff the substitution's value is escaped.
+func (m *markdownSanitizer) sanitizes(key string) bool {
+	return m.substitutions[key] || m.userDefined && (strings.HasPrefix(key, "_") || key == committerLoginSubst)
+}
+
+// view returns a copy of the view, if m is non-nil, whose build and params have their sanitized values escaped. The
+// original build is left as-is for the notifier's own use, e.g. to look up the branch.
+func (m *markdownSanitizer) view(v *notifiers.TemplateView) *notifiers.TemplateView {
+	if m == nil {
+		return v
+	}
+	build := proto.Clone(v.Build.Build).(*cbpb.Build)
+	for k, s := range build.Substitutions {
+		if m.sanitizes(k) {
+			build.Substitutions[k] = escapeMarkdown(s)
+		}
+	}
+	sanitized := *v
+	sanitized.Build = &notifiers.BuildView{Build: build}
+	if m.userDefined && v.Params != nil {
+		sanitized.Params = map[string]string{}
+		for k, p := range v.Params {
+			sanitized.Params[k] = escapeMarkdown(p)
+		}
+	}
+	return &sanitized
+}
+
+// markdownEscaper backslash-escapes the punctuation that Markdown, GitHub's extensions of it, and inline HTML give a
+// meaning to. Escaped, each renders as itself.
+var markdownEscaper = func() *strings.Replacer {
+	var pairs []string
+	for _, c := range "\\`*_{}[]()<>#+-!|~&" {
+		pairs = append(pairs, string(c), "\\"+string(c))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// escapeMarkdown escapes s so that it renders as the literal text in GitHub Markdown.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+// staticResolver resolves every build's params to itself.
+type staticResolver map[string]string
+
+func (s staticResolver) Resolve(context.Context, notifiers.SecretGetter, *cbpb.Build) (map[string]string, error) {
+	return s, nil
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"fix the build", "fix the build"},
+		{"use `go test` here", "use \\`go test\\` here"},
+		{"unbalanced ``` fence", "unbalanced \\`\\`\\` fence"},
+		{"**really** important_fix", "\\*\\*really\\*\\* important\\_fix"},
+		{`<img src=x onerror="alert(1)">`, `\<img src=x onerror="alert\(1\)"\>`},
+		{"# not a heading\n- not a list", "\\# not a heading\n\\- not a list"},
+		{"[link](http://example.com) @user", "\\[link\\]\\(http://example.com\\) @user"},
+		{`C:\path &amp;`, `C:\\path \&amp;`},
+	} {
+		if got := escapeMarkdown(tc.in); got != tc.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSanitizeMarkdown(t *testing.T) {
+	const (
+		create  = "POST /repos/somename/somerepo/issues"
+		tmpl    = `{"title": "t", "body": {{json (printf "%s|%s|%s|%s" .Build.Substitutions._COMMIT_MESSAGE .Build.Substitutions.GH_COMMITTER_LOGIN .Build.Substitutions.BRANCH_NAME (index .Params "author"))}}}`
+		message = "fix `main` <b>now</b>"
+	)
+	for _, tc := range []struct {
+		name     string
+		sanitize interface{}
+		want     string
+	}{{
+		name: "unset",
+		want: "fix `main` <b>now</b>|octo_cat|feature_x|**me**",
+	}, {
+		name:     "off",
+		sanitize: false,
+		want:     "fix `main` <b>now</b>|octo_cat|feature_x|**me**",
+	}, {
+		name:     "user-defined values",
+		sanitize: true,
+		want:     "fix \\`main\\` \\<b\\>now\\</b\\>|octo\\_cat|feature_x|\\*\\*me\\*\\*",
+	}, {
+		name:     "listed substitutions",
+		sanitize: []interface{}{"BRANCH_NAME"},
+		want:     "fix `main` <b>now</b>|octo_cat|feature\\_x|**me**",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			delivery := map[string]interface{}{}
+			if tc.sanitize != nil {
+				delivery["sanitizeMarkdown"] = tc.sanitize
+			}
+			n := newTestNotifier(t, delivery, tmpl, fg)
+			n.br = staticResolver{"author": "**me**"}
+			build := &cbpb.Build{
+				Id:     "some-build-id",
+				Status: cbpb.Build_FAILURE,
+				Substitutions: map[string]string{
+					"REPO_FULL_NAME":     "somename/somerepo",
+					"BRANCH_NAME":        "feature_x",
+					"_COMMIT_MESSAGE":    message,
+					"GH_COMMITTER_LOGIN": "octo_cat",
+				},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			body, _ := fg.bodies[create]["body"].(string)
+			if !strings.HasPrefix(body, tc.want) {
+				t.Errorf("got issue body %q, want it to start with %q", body, tc.want)
+			}
+			if build.Substitutions["_COMMIT_MESSAGE"] != message {
+				t.Errorf("SendNotification changed the build's substitution to %q", build.Substitutions["_COMMIT_MESSAGE"])
+			}
+		})
+	}
+}
+
+func TestParseSanitizeMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "true", v: true},
+		{name: "false", v: false, wantNil: true},
+		{name: "list", v: []interface{}{"_COMMIT_MESSAGE"}},
+		{name: "empty list", v: []interface{}{}, wantErr: true},
+		{name: "empty name", v: []interface{}{""}, wantErr: true},
+		{name: "string", v: "true", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := parseSanitizeMarkdown(tc.v)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseSanitizeMarkdown(%v) = %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+			if !tc.wantErr && (m == nil) != tc.wantNil {
+				t.Errorf("parseSanitizeMarkdown(%v) = %+v, want nil: %t", tc.v, m, tc.wantNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	log "github.com/golang/glog"
+)
+
+// skipSubstitution is a substitution that builds set to opt out of notifications.
+type skipSubstitution struct {
+	key string
+	// value is the value that opts out. If it's "", any true boolean value (e.g. "true" or "1") does.
+	value string
+}
+
+// parseSkipSubstitution parses the `skipSubstitution` delivery config field, a substitution name optionally followed by
+// `=` and the value that opts out, e.g. `_SKIP_NOTIFY` or `_NOTIFY=off`.
+func parseSkipSubstitution(v interface{}) (*skipSubstitution, error) {
+	s, _ := v.(string)
+	split := strings.SplitN(s, "=", 2)
+	if split[0] == "" || strings.ContainsAny(split[0], " \t") {
+		return nil, fmt.Errorf("expected delivery config field %q to be a substitution name, optionally with a value, like %q, got %v", skipSubstitutionField, "_SKIP_NOTIFY=true", v)
+	}
+	skip := &skipSubstitution{key: split[0]}
+	if len(split) == 2 {
+		if split[1] == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to have a non-empty value after %q, got %v", skipSubstitutionField, "=", v)
+		}
+		skip.value = split[1]
+	}
+	return skip, nil
+}
+
+// skips returns true iff the build opts out of notifications with the skip substitution.
+func (s *skipSubstitution) skips(build *cbpb.Build) bool {
+	v, ok := build.Substitutions[s.key]
+	if !ok {
+		return false
+	}
+	if s.value != "" {
+		return v == s.value
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Warningf("ignoring %q of Build %q with non-boolean value %q", s.key, build.Id, v)
+		return false
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+func TestSkipSubstitution(t *testing.T) {
+	const create = "POST /repos/somename/somerepo/issues"
+	for _, tc := range []struct {
+		name          string
+		skip          string
+		substitutions map[string]string
+		wantCreate    bool
+	}{{
+		name:          "opted out",
+		skip:          "_SKIP_NOTIFY",
+		substitutions: map[string]string{"_SKIP_NOTIFY": "true"},
+	}, {
+		name:          "opted out with another boolean",
+		skip:          "_SKIP_NOTIFY",
+		substitutions: map[string]string{"_SKIP_NOTIFY": "1"},
+	}, {
+		name:          "not opted out",
+		skip:          "_SKIP_NOTIFY",
+		substitutions: map[string]string{"_SKIP_NOTIFY": "false"},
+		wantCreate:    true,
+	}, {
+		name:          "unset",
+		skip:          "_SKIP_NOTIFY",
+		substitutions: map[string]string{},
+		wantCreate:    true,
+	}, {
+		name:          "non-boolean",
+		skip:          "_SKIP_NOTIFY",
+		substitutions: map[string]string{"_SKIP_NOTIFY": "please"},
+		wantCreate:    true,
+	}, {
+		name:          "opted out with value",
+		skip:          "_NOTIFY=off",
+		substitutions: map[string]string{"_NOTIFY": "off"},
+	}, {
+		name:          "other value",
+		skip:          "_NOTIFY=off",
+		substitutions: map[string]string{"_NOTIFY": "true"},
+		wantCreate:    true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"skipSubstitution": tc.skip}, issuePayload, fg)
+			tc.substitutions["REPO_FULL_NAME"] = "somename/somerepo"
+			build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, Substitutions: tc.substitutions}
+
+			before := notifiers.FilteredTotal(notifiers.FilterReasonOptOut)
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if _, created := fg.bodies[create]; created != tc.wantCreate {
+				t.Errorf("created an issue: %t, want %t", created, tc.wantCreate)
+			}
+			wantOptOuts := int64(1)
+			if tc.wantCreate {
+				wantOptOuts = 0
+			}
+			if got := notifiers.FilteredTotal(notifiers.FilterReasonOptOut) - before; got != wantOptOuts {
+				t.Errorf("recorded %d opt-outs, want %d", got, wantOptOuts)
+			}
+		})
+	}
+}
+
+func TestParseSkipSubstitution(t *testing.T) {
+	for _, tc := range []struct {
+		v       interface{}
+		want    skipSubstitution
+		wantErr bool
+	}{
+		{v: "_SKIP_NOTIFY", want: skipSubstitution{key: "_SKIP_NOTIFY"}},
+		{v: "_NOTIFY=off", want: skipSubstitution{key: "_NOTIFY", value: "off"}},
+		{v: "_NOTIFY=a=b", want: skipSubstitution{key: "_NOTIFY", value: "a=b"}},
+		{v: "", wantErr: true},
+		{v: "=off", wantErr: true},
+		{v: "_NOTIFY=", wantErr: true},
+		{v: "_SKIP NOTIFY", wantErr: true},
+		{v: true, wantErr: true},
+	} {
+		got, err := parseSkipSubstitution(tc.v)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseSkipSubstitution(%v) = %v, want error: %t", tc.v, err, tc.wantErr)
+			continue
+		}
+		if err == nil && *got != tc.want {
+			t.Errorf("parseSkipSubstitution(%v) = %+v, want %+v", tc.v, *got, tc.want)
+		}
+	}
+}
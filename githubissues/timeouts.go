@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// defaultRequestTimeout is how long a GitHub API request may take if `requestTimeout` isn't configured.
+const defaultRequestTimeout = 30 * time.Second
+
+// requestTimeouts are how long each attempt at a GitHub API request may take, by the kind of call. Zero means no timeout.
+type requestTimeouts struct {
+	// request applies to calls without a timeout of their own, e.g. marker searches and labeling.
+	request time.Duration
+	create  time.Duration
+	lookup  time.Duration
+	close   time.Duration
+}
+
+// parseRequestTimeouts parses the `requestTimeout` delivery config field and the `createTimeout`, `lookupTimeout`, and
+// `closeTimeout` fields, which default to it.
+func parseRequestTimeouts(delivery map[string]interface{}) (requestTimeouts, error) {
+	var t requestTimeouts
+	var err error
+	if t.request, err = getDurationFieldOr(delivery, requestTimeoutField, defaultRequestTimeout); err != nil {
+		return t, err
+	}
+	if t.create, err = getDurationFieldOr(delivery, createTimeoutField, t.request); err != nil {
+		return t, err
+	}
+	if t.lookup, err = getDurationFieldOr(delivery, lookupTimeoutField, t.request); err != nil {
+		return t, err
+	}
+	if t.close, err = getDurationFieldOr(delivery, closeTimeoutField, t.request); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// getDurationFieldOr is getDurationField, returning def if the field is not set.
+func getDurationFieldOr(delivery map[string]interface{}, field string, def time.Duration) (time.Duration, error) {
+	if _, ok := delivery[field]; !ok {
+		return def, nil
+	}
+	return getDurationField(delivery, field)
+}
+
+type requestTimeoutKey struct{}
+
+// withRequestTimeout returns a context whose GitHub API requests each time out after d, instead of the default
+// requestTimeout. Every retry of a request gets the full timeout.
+func withRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, d)
+}
+
+// requestContext returns a context for one attempt at a GitHub API request made with ctx, which times out after the
+// timeout that ctx carries or else the notifier's default.
+func (g *githubissuesNotifier) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	d, ok := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	if !ok {
+		d = g.timeouts.request
+	}
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
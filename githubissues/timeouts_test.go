@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+// deadlineTransport records how long each request had left until its context's deadline, by "METHOD /path".
+type deadlineTransport struct {
+	next http.RoundTripper
+
+	mu   sync.Mutex
+	left map[string]time.Duration
+}
+
+func (d *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	left := time.Duration(-1)
+	if dl, ok := req.Context().Deadline(); ok {
+		left = time.Until(dl)
+	}
+	d.mu.Lock()
+	d.left[req.Method+" "+req.URL.Path] = left
+	d.mu.Unlock()
+	return d.next.RoundTrip(req)
+}
+
+func TestRequestTimeouts(t *testing.T) {
+	const (
+		create = "POST /repos/somename/somerepo/issues"
+		lookup = "GET /repos/somename/somerepo/commits/main"
+		close  = "PATCH /repos/somename/somerepo/issues/7"
+		search = "GET /search/issues"
+	)
+	for _, tc := range []struct {
+		name     string
+		delivery map[string]interface{}
+		want     map[string]time.Duration
+	}{{
+		name: "default",
+		want: map[string]time.Duration{create: defaultRequestTimeout, lookup: defaultRequestTimeout, close: defaultRequestTimeout, search: defaultRequestTimeout},
+	}, {
+		name:     "global",
+		delivery: map[string]interface{}{requestTimeoutField: "10s"},
+		want:     map[string]time.Duration{create: 10 * time.Second, lookup: 10 * time.Second, close: 10 * time.Second, search: 10 * time.Second},
+	}, {
+		name: "per call",
+		delivery: map[string]interface{}{
+			requestTimeoutField: "10s",
+			createTimeoutField:  "1m",
+			lookupTimeoutField:  "2s",
+			closeTimeoutField:   "20s",
+		},
+		want: map[string]time.Duration{create: time.Minute, lookup: 2 * time.Second, close: 20 * time.Second, search: 10 * time.Second},
+	}, {
+		name:     "disabled",
+		delivery: map[string]interface{}{requestTimeoutField: "0s", createTimeoutField: "1m"},
+		want:     map[string]time.Duration{create: time.Minute, lookup: -1, close: -1, search: -1},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNotifier(t, tc.delivery, issuePayload, &fakeGitHub{t: t, issue: createdIssue})
+			dt := &deadlineTransport{next: n.httpClient.Transport, left: map[string]time.Duration{}}
+			n.httpClient.Transport = dt
+			ctx := context.Background()
+
+			build := &cbpb.Build{Id: "some-build-id", Substitutions: map[string]string{"BRANCH_NAME": "main"}}
+			if _, err := n.getCommitter(ctx, build, "somename/somerepo"); err != nil {
+				t.Fatalf("getCommitter failed: %v", err)
+			}
+			iss, err := n.createIssue(ctx, "somename/somerepo", []byte(`{"title": "t", "body": "b"}`))
+			if err != nil {
+				t.Fatalf("createIssue failed: %v", err)
+			}
+			if err := n.closeIssue(ctx, iss, &notifiers.TemplateView{Build: &notifiers.BuildView{Build: build}}); err != nil {
+				t.Fatalf("closeIssue failed: %v", err)
+			}
+			if _, err := n.findIssues(ctx, "somename/somerepo", "some-marker", true); err != nil {
+				t.Fatalf("findIssues failed: %v", err)
+			}
+
+			for call, want := range tc.want {
+				got, ok := dt.left[call]
+				if !ok {
+					t.Errorf("%s was not requested; got requests %v", call, dt.left)
+					continue
+				}
+				if want < 0 {
+					if got >= 0 {
+						t.Errorf("%s had a timeout of about %v, want none", call, got)
+					}
+					continue
+				}
+				// The time left is measured after the request started, so allow for the test's own runtime.
+				if got > want || got < want-time.Second {
+					t.Errorf("%s had a timeout of about %v, want %v", call, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRequestTimeoutsErrors(t *testing.T) {
+	for _, delivery := range []map[string]interface{}{
+		{requestTimeoutField: "soon"},
+		{createTimeoutField: 30},
+		{lookupTimeoutField: "-1s"},
+		{closeTimeoutField: "1 minute"},
+	} {
+		if got, err := parseRequestTimeouts(delivery); err == nil {
+			t.Errorf("parseRequestTimeouts(%v) = %+v, want error", delivery, got)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// maxTitleLength is the longest issue title, in characters, that GitHub accepts.
+const maxTitleLength = 256
+
+// sanitizeTitle makes a rendered title acceptable to GitHub: runs of whitespace (including newlines, e.g. from commit
+// messages) collapse to a single space, other control characters are dropped, and titles longer than maxTitleLength
+// are truncated with an ellipsis.
+func sanitizeTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, title)
+	title = strings.Join(strings.Fields(title), " ")
+
+	if r := []rune(title); len(r) > maxTitleLength {
+		title = strings.TrimRightFunc(string(r[:maxTitleLength-1]), unicode.IsSpace) + "…"
+	}
+	return title
+}
+
+// parseTitleTemplate parses the titleTemplate delivery config field, a template of the title that replaces the one the
+// issue template renders, e.g. `Build failed: {{.Build.Substitutions.TRIGGER_NAME}}`.
+func parseTitleTemplate(v interface{}) (*template.Template, error) {
+	s, ok := v.(string)
+	if !ok || strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty template string, got %v", titleTemplateField, v)
+	}
+	tmpl, err := template.New(titleTemplateField).Funcs(notifiers.TemplateFuncs()).Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse title template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderTitle renders the title template, if any, over the view and sets it as the `title` of the rendered issue.
+func (g *githubissuesNotifier) renderTitle(rendered []byte, view *notifiers.TemplateView) ([]byte, error) {
+	if g.titleTmpl == nil {
+		return rendered, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("expected the rendered issue to be a JSON object to set the title of, got %q", rendered)
+	}
+	var buf bytes.Buffer
+	if err := notifiers.ExecuteTemplate(g.titleTmpl, &buf, view); err != nil {
+		return nil, err
+	}
+	fields["title"] = buf.String()
+	return json.Marshal(fields)
+}
+
+// sanitizeRendered returns the rendered template output with its `title` sanitized. Output that isn't a JSON object
+// with a string title is returned unchanged.
+func sanitizeRendered(rendered []byte) []byte {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil {
+		return rendered
+	}
+	title, ok := fields["title"].(string)
+	if !ok {
+		return rendered
+	}
+	clean := sanitizeTitle(title)
+	if clean == title {
+		return rendered
+	}
+	fields["title"] = clean
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return rendered
+	}
+	log.V(2).Infof("sanitized rendered title %q to %q", title, clean)
+	return out
+}
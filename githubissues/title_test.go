@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+func TestSanitizeTitle(t *testing.T) {
+	long := strings.Repeat("ab ", 100)
+	for _, tc := range []struct {
+		name  string
+		title string
+		want  string
+	}{{
+		name:  "clean",
+		title: "Build failed: main",
+		want:  "Build failed: main",
+	}, {
+		name:  "newlines and tabs",
+		title: "Build failed:\n\tfix the thing\r\n\nfor real",
+		want:  "Build failed: fix the thing for real",
+	}, {
+		name:  "control characters",
+		title: "Build\x00 fa\x1biled\x7f",
+		want:  "Build failed",
+	}, {
+		name:  "surrounding whitespace",
+		title: "  padded  ",
+		want:  "padded",
+	}, {
+		name:  "overlong",
+		title: long,
+		want:  strings.TrimSpace(long[:maxTitleLength-1]) + "…",
+	}, {
+		name:  "overlong multi-byte",
+		title: strings.Repeat("ü", 300),
+		want:  strings.Repeat("ü", maxTitleLength-1) + "…",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sanitizeTitle(tc.title)
+			if got != tc.want {
+				t.Errorf("sanitizeTitle(%q) = %q, want %q", tc.title, got, tc.want)
+			}
+			if n := utf8.RuneCountInString(got); n > maxTitleLength {
+				t.Errorf("sanitizeTitle(%q) has %d characters, want at most %d", tc.title, n, maxTitleLength)
+			}
+		})
+	}
+}
+
+func TestSendNotificationSanitizesTitle(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	n := newTestNotifier(t, nil, `{"title": "{{.Build.Substitutions.MSG}}", "body": "b"}`, fg)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "MSG": `fix\n\tstuff\u0007`},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	body := fg.bodies["POST /repos/somename/somerepo/issues"]
+	if got, want := body["title"], "fix stuff"; got != want {
+		t.Errorf("got title %q, want %q", got, want)
+	}
+	if got, want := body["body"], "b"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+}
+
+func TestTitleTemplate(t *testing.T) {
+	fg := &fakeGitHub{t: t, issue: createdIssue}
+	delivery := map[string]interface{}{
+		"titleTemplate": "Build failed: {{.Build.Substitutions.TRIGGER_NAME}}\non {{.Build.Substitutions.BRANCH_NAME}}",
+	}
+	n := newTestNotifier(t, delivery, issuePayload, fg)
+
+	build := &cbpb.Build{
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "TRIGGER_NAME": "deploy", "BRANCH_NAME": "main"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	body := fg.bodies["POST /repos/somename/somerepo/issues"]
+	if got, want := body["title"], "Build failed: deploy on main"; got != want {
+		t.Errorf("got title %q, want %q", got, want)
+	}
+	if got, _ := body["body"].(string); !strings.HasPrefix(got, "Cloud Build   status: **FAILURE**") {
+		t.Errorf("got body %q, want the issue template's body", got)
+	}
+}
+
+func TestParseTitleTemplateErrors(t *testing.T) {
+	for _, v := range []interface{}{42, "", "  ", "{{.Build"} {
+		if _, err := parseTitleTemplate(v); err == nil {
+			t.Errorf("parseTitleTemplate(%v) succeeded, want an error", v)
+		}
+	}
+}
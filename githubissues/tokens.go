@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// getBackupTokens fetches the tokens referenced by the `backupTokens` delivery config field, a list of
+// `secretRef: <name>` maps, in order. It returns nil if the field is unset.
+func getBackupTokens(ctx context.Context, cfg *notifiers.Config, sg notifiers.SecretGetter) ([]string, error) {
+	v, ok := cfg.Spec.Notification.Delivery[backupTokensField]
+	if !ok {
+		return nil, nil
+	}
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty list of `secretRef: <name>` maps, got %v", backupTokensField, v)
+	}
+	var tokens []string
+	for i, e := range l {
+		m, _ := e.(map[interface{}]interface{})
+		ref, _ := m["secretRef"].(string)
+		if ref == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to be a list of `secretRef: <name>` maps, got %v", backupTokensField, e)
+		}
+		resource, err := notifiers.FindSecretResourceName(cfg.Spec.Secrets, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find Secret for backup token %d (ref %q): %w", i+1, ref, err)
+		}
+		token, err := sg.GetSecret(ctx, resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get backup token %d: %w", i+1, err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+type tokenIndexKey struct{}
+
+// withToken returns a context whose GitHub API requests authenticate with the token at the given index: 0 for
+// githubToken, and i for the i-th backup token.
+func withToken(ctx context.Context, i int) context.Context {
+	return context.WithValue(ctx, tokenIndexKey{}, i)
+}
+
+// token returns the token that requests made with the context authenticate with. With a GitHub App configured, that's
+// its installation token instead of githubToken.
+func (g *githubissuesNotifier) token(ctx context.Context) (string, error) {
+	if i, ok := ctx.Value(tokenIndexKey{}).(int); ok && i > 0 && i <= len(g.backupTokens) {
+		return g.backupTokens[i-1], nil
+	}
+	if g.app != nil {
+		return g.app.token(ctx)
+	}
+	return g.githubToken, nil
+}
+
+// tokenRejected returns true iff err shows that GitHub rejected the token itself, because it's invalid or revoked, or
+// because it ran out of rate limit, so that another token may succeed.
+func tokenRejected(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	return se.code == http.StatusUnauthorized || se.code == http.StatusTooManyRequests || (se.code == http.StatusForbidden && se.rateLimited)
+}
+
+// withTokenFailover calls f with a context authenticating with githubToken and, while GitHub rejects the token, with
+// each backup token in turn. It returns the error of the last call.
+func (g *githubissuesNotifier) withTokenFailover(ctx context.Context, what string, f func(context.Context) error) error {
+	var err error
+	for i := 0; i <= len(g.backupTokens); i++ {
+		if err = f(withToken(ctx, i)); err == nil {
+			if i > 0 {
+				log.Infof("%s succeeded with backup token %d", what, i)
+			}
+			return nil
+		}
+		if !tokenRejected(err) {
+			return err
+		}
+		if i < len(g.backupTokens) {
+			log.Warningf("%s failed with token %d, failing over to backup token %d: %v", what, i, i+1, err)
+		}
+	}
+	return err
+}
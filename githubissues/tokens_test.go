@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+// tokenResponse is how the fake GitHub of TestTokenFailover responds to a token.
+type tokenResponse struct {
+	code    int
+	headers map[string]string
+}
+
+func TestTokenFailover(t *testing.T) {
+	revoked := tokenResponse{code: http.StatusUnauthorized}
+	rateLimited := tokenResponse{code: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0"}}
+	forbidden := tokenResponse{code: http.StatusForbidden}
+
+	for _, tc := range []struct {
+		name string
+		// responses maps tokens to non-OK responses; other tokens succeed.
+		responses  map[string]tokenResponse
+		wantTokens []string
+		wantErr    bool
+	}{{
+		name:       "primary succeeds",
+		wantTokens: []string{githubToken},
+	}, {
+		name:       "revoked primary fails over",
+		responses:  map[string]tokenResponse{githubToken: revoked},
+		wantTokens: []string{githubToken, "backup-1"},
+	}, {
+		name:       "rate-limited tokens fail over in order",
+		responses:  map[string]tokenResponse{githubToken: rateLimited, "backup-1": revoked},
+		wantTokens: []string{githubToken, "backup-1", "backup-2"},
+	}, {
+		name:       "other forbidden responses don't fail over",
+		responses:  map[string]tokenResponse{githubToken: forbidden},
+		wantTokens: []string{githubToken},
+		wantErr:    true,
+	}, {
+		name:       "all tokens rejected",
+		responses:  map[string]tokenResponse{githubToken: revoked, "backup-1": revoked, "backup-2": rateLimited},
+		wantTokens: []string{githubToken, "backup-1", "backup-2"},
+		wantErr:    true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, op := range []string{"create", "close"} {
+				var mu sync.Mutex
+				var gotTokens []string
+				h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					token := strings.TrimPrefix(r.Header.Get("Authorization"), "token ")
+					mu.Lock()
+					gotTokens = append(gotTokens, token)
+					mu.Unlock()
+					if resp, ok := tc.responses[token]; ok {
+						for k, v := range resp.headers {
+							w.Header().Set(k, v)
+						}
+						w.WriteHeader(resp.code)
+						fmt.Fprint(w, `{}`)
+						return
+					}
+					fmt.Fprint(w, createdIssue)
+				})
+				n := newTestNotifier(t, nil, issuePayload, h)
+				n.backupTokens = []string{"backup-1", "backup-2"}
+
+				var err error
+				if op == "create" {
+					_, err = n.createIssue(context.Background(), "somename/somerepo", []byte(`{"title": "t"}`))
+				} else {
+					iss := &issue{Number: 7, URL: "https://api.github.com/repos/somename/somerepo/issues/7"}
+					err = n.closeIssue(context.Background(), iss, &notifiers.TemplateView{})
+				}
+				if (err != nil) != tc.wantErr {
+					t.Errorf("%s: got error %v, want error: %t", op, err, tc.wantErr)
+				}
+				if diff := cmp.Diff(tc.wantTokens, gotTokens); diff != "" {
+					t.Errorf("%s: unexpected tokens used (-want +got):\n%s", op, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBackupTokensConfig(t *testing.T) {
+	cfg := func(backupTokens interface{}) *notifiers.Config {
+		return &notifiers.Config{
+			Spec: &notifiers.Spec{
+				Notification: &notifiers.Notification{
+					Filter: `build.status == Build.Status.SUCCESS`,
+					Delivery: map[string]interface{}{
+						"githubToken":  map[interface{}]interface{}{"secretRef": "mytoken"},
+						"githubRepo":   "somename/somerepo",
+						"backupTokens": backupTokens,
+					},
+				},
+				Secrets: []*notifiers.Secret{
+					{LocalName: "mytoken", ResourceName: "mysekrit"},
+					{LocalName: "backup", ResourceName: "backupsekrit"},
+				},
+			},
+		}
+	}
+
+	n := new(githubissuesNotifier)
+	if err := n.SetUp(context.Background(), cfg([]interface{}{map[interface{}]interface{}{"secretRef": "backup"}}), issuePayload, new(fakeSecretGetter), new(fakeBindingResolver)); err != nil {
+		t.Fatalf("SetUp failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{githubToken}, n.backupTokens); diff != "" {
+		t.Errorf("unexpected backup tokens (-want +got):\n%s", diff)
+	}
+
+	for _, bad := range []interface{}{
+		"backup",
+		[]interface{}{},
+		[]interface{}{"backup"},
+		[]interface{}{map[interface{}]interface{}{"secretRef": "unknown"}},
+	} {
+		if err := new(githubissuesNotifier).SetUp(context.Background(), cfg(bad), issuePayload, new(fakeSecretGetter), new(fakeBindingResolver)); err == nil {
+			t.Errorf("SetUp with backupTokens %v succeeded, want error", bad)
+		}
+	}
+}
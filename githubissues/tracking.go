@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// Supported values of the `trackingIssueKey` delivery config field.
+const (
+	trackingKeyCommit = "commit"
+	trackingKeyBranch = "branch"
+)
+
+// parseCommentStatuses parses the `commentStatuses` delivery config field, a non-empty list of build statuses.
+func parseCommentStatuses(v interface{}) (map[cbpb.Build_Status]bool, error) {
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty list of build statuses, got %v", commentStatusesField, v)
+	}
+	statuses := map[cbpb.Build_Status]bool{}
+	for _, e := range l {
+		name, _ := e.(string)
+		status, ok := cbpb.Build_Status_value[name]
+		if !ok {
+			return nil, fmt.Errorf("expected delivery config field %q to list build statuses like %q, got %v", commentStatusesField, "WORKING", e)
+		}
+		statuses[cbpb.Build_Status(status)] = true
+	}
+	return statuses, nil
+}
+
+// trackingRef returns the build's commit or branch, per the configured tracking key, or "" if it has none.
+func (g *githubissuesNotifier) trackingRef(build *cbpb.Build) string {
+	if g.trackingKey == trackingKeyBranch {
+		return build.Substitutions["BRANCH_NAME"]
+	}
+	return build.Substitutions["COMMIT_SHA"]
+}
+
+// trackingMarker returns the marker embedded in the tracking issue of the repo's commit or branch.
+func trackingMarker(repo, key, ref string) string {
+	return newMarker("tracking", repo+"@"+key+":"+ref)
+}
+
+// sendTrackingComment comments the rendered issue body on the tracking issue of the build's commit or branch, or, if
+// there is none yet, creates it from the rendered issue.
+func (g *githubissuesNotifier) sendTrackingComment(ctx context.Context, build *cbpb.Build, repo string, rendered []byte) error {
+	ref := g.trackingRef(build)
+	if ref == "" {
+		log.Warningf("Build %q has no %s to key a tracking issue by, skipping comment", build.Id, g.trackingKey)
+		notifiers.RecordFiltered(notifiers.FilterReasonNoRef)
+		return nil
+	}
+	marker := trackingMarker(repo, g.trackingKey, ref)
+
+	number, ok := g.trackingIssues.get(ctx, marker)
+	if !ok {
+		open, err := g.findOpenIssues(ctx, repo, marker)
+		if err != nil {
+			return fmt.Errorf("failed to look up the tracking issue for %s %q: %w", g.trackingKey, ref, err)
+		}
+		if len(open) > 0 {
+			number, ok = int64(open[0].Number), true
+			g.trackingIssues.put(ctx, marker, number)
+		}
+	}
+
+	if ok {
+		body, err := commentPayload(rendered)
+		if err != nil {
+			return err
+		}
+		g.logPayload(build, "comment", body)
+		if err := g.doRequest(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/comments", g.repoURL(repo), number), body, nil); err != nil {
+			var se *statusError
+			if errors.As(err, &se) {
+				log.Warningf("failed to comment on tracking issue #%d in %q: %v%s", number, repo, se, g.errorPayload("comment", body))
+				return nil
+			}
+			return fmt.Errorf("failed to comment on tracking issue #%d: %w%s", number, err, g.errorPayload("comment", body))
+		}
+		log.V(2).Infof("commented on tracking issue #%d in %q for Build %q", number, repo, build.Id)
+		return nil
+	}
+
+	rendered, err := g.capListFields(build.Id, rendered)
+	if err != nil {
+		return err
+	}
+	if rendered, err = embedMarker(rendered, marker); err != nil {
+		return err
+	}
+	g.logPayload(build, "issue", rendered)
+	iss, err := g.createMarkedIssue(ctx, repo, rendered, marker)
+	if err != nil {
+		var se *statusError
+		if errors.As(err, &se) {
+			log.Warningf("failed to create tracking issue: %v%s", se, g.errorPayload("issue", rendered))
+			return nil
+		}
+		return fmt.Errorf("failed to create tracking issue: %w%s", err, g.errorPayload("issue", rendered))
+	}
+	g.trackingIssues.put(ctx, marker, int64(iss.Number))
+	log.V(2).Infof("created tracking issue #%d in %q for %s %q", iss.Number, repo, g.trackingKey, ref)
+	return nil
+}
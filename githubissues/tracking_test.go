@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCommentStatuses(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		v       interface{}
+		want    map[cbpb.Build_Status]bool
+		wantErr bool
+	}{{
+		name: "statuses",
+		v:    []interface{}{"WORKING", "SUCCESS"},
+		want: map[cbpb.Build_Status]bool{cbpb.Build_WORKING: true, cbpb.Build_SUCCESS: true},
+	}, {
+		name:    "empty",
+		v:       []interface{}{},
+		wantErr: true,
+	}, {
+		name:    "unknown status",
+		v:       []interface{}{"RUNNING"},
+		wantErr: true,
+	}, {
+		name:    "not a list",
+		v:       "WORKING",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCommentStatuses(tc.v)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCommentStatuses(%v) got error %v, want error: %t", tc.v, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected statuses (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSendTrackingComment(t *testing.T) {
+	const (
+		create  = "POST /repos/somename/somerepo/issues"
+		search  = "GET /search/issues"
+		comment = "POST /repos/somename/somerepo/issues/7/comments"
+	)
+	const tmpl = `{"title": "Build of {{.Build.Substitutions.COMMIT_SHA}}", "body": "Build {{.Build.Id}} is {{.Build.Status}}"}`
+	delivery := map[string]interface{}{
+		"commentStatuses": []interface{}{"WORKING", "SUCCESS", "FAILURE"},
+	}
+	build := func(status cbpb.Build_Status) *cbpb.Build {
+		return &cbpb.Build{
+			Id:            "some-build-id",
+			Status:        status,
+			Substitutions: map[string]string{"REPO_FULL_NAME": "somename/somerepo", "COMMIT_SHA": "abc123"},
+		}
+	}
+	marker := trackingMarker("somename/somerepo", trackingKeyCommit, "abc123")
+
+	t.Run("creates and then comments on the tracking issue", func(t *testing.T) {
+		fg := &fakeGitHub{t: t, issue: createdIssue}
+		n := newTestNotifier(t, delivery, tmpl, fg)
+		prd, err := notifiers.MakeCELPredicate(`build.status == Build.Status.WORKING || build.status == Build.Status.SUCCESS`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.filter = prd
+
+		for _, s := range []cbpb.Build_Status{cbpb.Build_WORKING, cbpb.Build_SUCCESS} {
+			if err := n.SendNotification(context.Background(), build(s)); err != nil {
+				t.Fatalf("SendNotification(%v) failed: %v", s, err)
+			}
+		}
+		if diff := cmp.Diff([]string{search, create, comment}, fg.gotCalls()); diff != "" {
+			t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+		}
+		if body, _ := fg.bodies[create]["body"].(string); !strings.HasPrefix(body, "Build some-build-id is WORKING") || !strings.Contains(body, marker) {
+			t.Errorf("got tracking issue body %q, want the WORKING body and marker %q", body, marker)
+		}
+		if got, want := fg.bodies[comment]["body"], "Build some-build-id is SUCCESS"; got != want {
+			t.Errorf("got comment body %q, want %q", got, want)
+		}
+	})
+
+	t.Run("finds the tracking issue of another instance", func(t *testing.T) {
+		fg := &fakeGitHub{t: t, responses: map[string]fakeResponse{
+			search: {http.StatusOK, fmt.Sprintf(`{"items": [{"number": 7, "body": "b\n\n<!-- %s -->"}]}`, marker)},
+		}}
+		n := newTestNotifier(t, delivery, tmpl, fg)
+
+		if err := n.SendNotification(context.Background(), build(cbpb.Build_FAILURE)); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{search, comment}, fg.gotCalls()); diff != "" {
+			t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+		}
+		if got, want := fg.bodies[comment]["body"], "Build some-build-id is FAILURE"; got != want {
+			t.Errorf("got comment body %q, want %q", got, want)
+		}
+	})
+
+	t.Run("other statuses create issues", func(t *testing.T) {
+		fg := &fakeGitHub{t: t, issue: createdIssue}
+		n := newTestNotifier(t, map[string]interface{}{"commentStatuses": []interface{}{"WORKING"}}, tmpl, fg)
+
+		if err := n.SendNotification(context.Background(), build(cbpb.Build_FAILURE)); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{create}, fg.gotCalls()); diff != "" {
+			t.Errorf("unexpected GitHub calls (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("keyed by branch", func(t *testing.T) {
+		fg := &fakeGitHub{t: t, issue: createdIssue}
+		n := newTestNotifier(t, map[string]interface{}{"commentStatuses": []interface{}{"FAILURE"}, "trackingIssueKey": "branch"}, tmpl, fg)
+
+		b := build(cbpb.Build_FAILURE)
+		b.Substitutions["BRANCH_NAME"] = "main"
+		if err := n.SendNotification(context.Background(), b); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+		want := trackingMarker("somename/somerepo", trackingKeyBranch, "main")
+		if body, _ := fg.bodies[create]["body"].(string); !strings.Contains(body, want) {
+			t.Errorf("got tracking issue body %q, want marker %q", body, want)
+		}
+	})
+
+	t.Run("no commit", func(t *testing.T) {
+		fg := &fakeGitHub{t: t, issue: createdIssue}
+		n := newTestNotifier(t, delivery, tmpl, fg)
+
+		b := build(cbpb.Build_FAILURE)
+		delete(b.Substitutions, "COMMIT_SHA")
+		if err := n.SendNotification(context.Background(), b); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+		if calls := fg.gotCalls(); len(calls) != 0 {
+			t.Errorf("got GitHub calls %v, want none", calls)
+		}
+	})
+}
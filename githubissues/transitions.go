@@ -0,0 +1,57 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+)
+
+// statusRetention is how long the last-seen status of a build is remembered.
+// Cloud Build publishes all of a build's events well within this window.
+const statusRetention = 24 * time.Hour
+
+// statusTracker remembers, in its state store, the last status seen for each build ID so that repeated (e.g.
+// redelivered) events for the same status can be dropped.
+type statusTracker struct {
+	store notifiers.StateStore
+}
+
+func newStatusTracker(store notifiers.StateStore) *statusTracker {
+	return &statusTracker{store: store}
+}
+
+// transitioned returns true iff the given status differs from the last status recorded for the build (or the build has
+// not been seen before). It fails open: if the state store can't be reached, the status counts as a transition.
+func (s *statusTracker) transitioned(ctx context.Context, buildID string, status cbpb.Build_Status) bool {
+	prev, ok, err := s.store.Get(ctx, "status/"+buildID)
+	if err != nil {
+		log.Warningf("failed to check last-seen status of Build %q, treating it as changed: %v", buildID, err)
+		return true
+	}
+	return !ok || prev != status.String()
+}
+
+// record records the given status as the last one seen for the build. It's called once the status has been notified,
+// so that an event whose notification failed still counts as a transition when it's redelivered.
+func (s *statusTracker) record(ctx context.Context, buildID string, status cbpb.Build_Status) {
+	if err := s.store.Put(ctx, "status/"+buildID, status.String(), statusRetention); err != nil {
+		log.Warningf("failed to record status %v of Build %q: %v", status, buildID, err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+func TestStatusTracker(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	s := newStatusTracker(&notifiers.MemoryStateStore{Now: func() time.Time { return now }})
+
+	for _, step := range []struct {
+		name    string
+		elapsed time.Duration
+		id      string
+		status  cbpb.Build_Status
+		want    bool
+	}{
+		{name: "first event", id: "a", status: cbpb.Build_WORKING, want: true},
+		{name: "redelivered event", id: "a", status: cbpb.Build_WORKING, want: false},
+		{name: "terminal transition", id: "a", status: cbpb.Build_SUCCESS, want: true},
+		{name: "redelivered terminal event", id: "a", status: cbpb.Build_SUCCESS, want: false},
+		{name: "other build", id: "b", status: cbpb.Build_SUCCESS, want: true},
+		{name: "forgotten after retention", elapsed: statusRetention + time.Minute, id: "b", status: cbpb.Build_SUCCESS, want: true},
+	} {
+		now = start.Add(step.elapsed)
+		got := s.transitioned(context.Background(), step.id, step.status)
+		if got != step.want {
+			t.Errorf("%s: transitioned(%q, %v) = %v, want %v", step.name, step.id, step.status, got, step.want)
+		}
+		if got {
+			s.record(context.Background(), step.id, step.status)
+		}
+	}
+}
+
+func TestStatusTrackerFailsOpen(t *testing.T) {
+	s := newStatusTracker(failingStore{})
+	for i := 0; i < 2; i++ {
+		if !s.transitioned(context.Background(), "a", cbpb.Build_SUCCESS) {
+			t.Fatalf("transitioned() call %d with failing store = false, want true", i)
+		}
+	}
+}
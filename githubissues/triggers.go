@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"text/template"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+)
+
+// parseTriggerTemplates parses the `triggerTemplates` delivery config field, a map of build trigger IDs or names to
+// issue templates.
+func parseTriggerTemplates(v interface{}) (map[string]*template.Template, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty map of trigger IDs or names to templates, got %v", triggerTemplatesField, v)
+	}
+	tmpls := map[string]*template.Template{}
+	for k, t := range m {
+		trigger, _ := k.(string)
+		if trigger == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to have trigger ID or name keys, got %v", triggerTemplatesField, k)
+		}
+		ts, ok := t.(string)
+		if !ok || ts == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to map %q to a template string, got %v", triggerTemplatesField, trigger, t)
+		}
+		tmpl, err := template.New(triggerTemplatesField + "." + trigger).Funcs(notifiers.TemplateFuncs()).Parse(ts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse issue template of trigger %q: %w", trigger, err)
+		}
+		tmpls[trigger] = tmpl
+	}
+	return tmpls, nil
+}
+
+// issueTemplate returns the issue template of the build's trigger, looked up by the build's trigger ID and then by its
+// TRIGGER_NAME substitution, or the notifier's issue template if its trigger has none.
+func (g *githubissuesNotifier) issueTemplate(build *cbpb.Build) *template.Template {
+	if id := build.BuildTriggerId; id != "" {
+		if tmpl, ok := g.triggerTemplates[id]; ok {
+			return tmpl
+		}
+	}
+	if name := build.Substitutions["TRIGGER_NAME"]; name != "" {
+		if tmpl, ok := g.triggerTemplates[name]; ok {
+			return tmpl
+		}
+	}
+	return g.tmpl
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+func TestTriggerTemplates(t *testing.T) {
+	triggerTemplates := map[interface{}]interface{}{
+		"1234-abcd": `{"title": "Deploy failed: {{.Build.Id}}", "body": "by ID"}`,
+		"nightly":   `{"title": "Nightly failed: {{.Build.Id}}", "body": "by name"}`,
+	}
+	for _, tc := range []struct {
+		name        string
+		triggerID   string
+		triggerName string
+		wantTitle   string
+	}{
+		{name: "by trigger ID", triggerID: "1234-abcd", wantTitle: "Deploy failed: some-build-id"},
+		{name: "by TRIGGER_NAME", triggerID: "5678-efgh", triggerName: "nightly", wantTitle: "Nightly failed: some-build-id"},
+		{name: "ID takes precedence", triggerID: "1234-abcd", triggerName: "nightly", wantTitle: "Deploy failed: some-build-id"},
+		{name: "unknown trigger falls back", triggerID: "5678-efgh", triggerName: "hourly", wantTitle: "Cloud Build [some-project]: FAILURE"},
+		{name: "manual build falls back", wantTitle: "Cloud Build [some-project]: FAILURE"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &fakeGitHub{t: t, issue: createdIssue}
+			n := newTestNotifier(t, map[string]interface{}{"triggerTemplates": triggerTemplates}, issuePayload, fg)
+
+			build := &cbpb.Build{
+				Id:             "some-build-id",
+				ProjectId:      "some-project",
+				Status:         cbpb.Build_FAILURE,
+				BuildTriggerId: tc.triggerID,
+				Substitutions:  map[string]string{"REPO_FULL_NAME": "somename/somerepo", "TRIGGER_NAME": tc.triggerName},
+			}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+			if got := fg.bodies["POST /repos/somename/somerepo/issues"]["title"]; got != tc.wantTitle {
+				t.Errorf("created issue title = %v, want %q", got, tc.wantTitle)
+			}
+		})
+	}
+}
+
+func TestParseTriggerTemplatesErrors(t *testing.T) {
+	for _, v := range []interface{}{
+		"nightly",
+		map[interface{}]interface{}{},
+		map[interface{}]interface{}{"": `{"title": "t"}`},
+		map[interface{}]interface{}{1: `{"title": "t"}`},
+		map[interface{}]interface{}{"nightly": ""},
+		map[interface{}]interface{}{"nightly": []interface{}{"t"}},
+		map[interface{}]interface{}{"nightly": "{{.Build"},
+	} {
+		if _, err := parseTriggerTemplates(v); err == nil {
+			t.Errorf("parseTriggerTemplates(%v) succeeded, want error", v)
+		}
+	}
+}
@@ -8,6 +8,7 @@ require (
 	cloud.google.com/go v0.110.2
 	cloud.google.com/go/bigquery v1.50.0
 	cloud.google.com/go/cloudbuild v1.14.0
+	cloud.google.com/go/pubsub v1.30.0
 	cloud.google.com/go/secretmanager v1.11.0
 	cloud.google.com/go/storage v1.29.0
 	github.com/andybalholm/brotli v1.0.5 // indirect
@@ -18,13 +19,21 @@ require (
 	github.com/google/flatbuffers v23.3.3+incompatible // indirect
 	github.com/google/go-cmp v0.5.9
 	github.com/google/go-containerregistry v0.15.2
+	github.com/googleapis/gax-go/v2 v2.11.0
 	github.com/gorilla/websocket v1.5.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.4 // indirect
 	github.com/pierrec/lz4/v4 v4.1.17 // indirect
 	github.com/slack-go/slack v0.12.2
 	github.com/stoewer/go-strcase v1.3.0 // indirect
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.42.0
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.16.0
+	go.opentelemetry.io/otel/sdk v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
 	golang.org/x/exp v0.0.0-20230420155640-133eef4313cb // indirect
 	google.golang.org/api v0.126.0
+	google.golang.org/genproto/googleapis/api v0.0.0-20230530153820-e85fd2cbaebc
+	google.golang.org/grpc v1.55.0
 	google.golang.org/protobuf v1.30.0
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/client-go v0.27.1
@@ -19,7 +19,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"text/template"
 
@@ -29,9 +31,21 @@ import (
 )
 
 const (
-	urlSecretName = "urlRef"
+	urlSecretName    = "urlRef"
+	contentTypeField = "contentType"
+	payloadFieldsKey = "payloadFields"
+	methodField      = "method"
 )
 
+// Supported values of the `contentType` delivery config field.
+const (
+	contentTypeJSON = "application/json"
+	contentTypeForm = "application/x-www-form-urlencoded"
+)
+
+// allowedMethods are the supported values of the `method` delivery config field.
+var allowedMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+
 func main() {
 	if err := notifiers.Main(new(httpNotifier)); err != nil {
 		log.Fatalf("fatal error: %v", err)
@@ -39,20 +53,31 @@ func main() {
 }
 
 type httpNotifier struct {
-	filter   notifiers.EventFilter
-	tmpl     *template.Template
-	url      string
+	filter notifiers.EventFilter
+	tmpl   *template.Template
+	url    string
+	client *http.Client
+	// method is the HTTP method requests are sent with.
+	method string
+	// contentType is the media type the rendered template is sent as.
+	contentType string
+	// fields maps payload keys to the templates rendering their values. If non-empty, it replaces tmpl.
+	fields   map[string]*template.Template
+	name     string
 	br       notifiers.BindingResolver
 	tmplView *notifiers.TemplateView
 }
 
 func (h *httpNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, httpTemplate string, sg notifiers.SecretGetter, br notifiers.BindingResolver) error {
+	h.name = notifiers.NotifierName(cfg, h)
+
 	prd, err := notifiers.MakeCELPredicate(cfg.Spec.Notification.Filter)
 	if err != nil {
 		return fmt.Errorf("failed to create CELPredicate: %w", err)
 	}
 	h.filter = prd
 	h.br = br
+	h.client = notifiers.NewTracingHTTPClient()
 
 	if url, ok := cfg.Spec.Notification.Delivery["url"].(string); ok {
 		h.url = url
@@ -72,7 +97,32 @@ func (h *httpNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, httpTem
 		h.url = url
 	}
 
-	tmpl, err := template.New("http_template").Parse(httpTemplate)
+	h.contentType = contentTypeJSON
+	if ct, ok := cfg.Spec.Notification.Delivery[contentTypeField]; ok {
+		switch ct {
+		case contentTypeJSON, contentTypeForm:
+			h.contentType = ct.(string)
+		default:
+			return fmt.Errorf("expected delivery config field %q to be one of %q or %q, got %v", contentTypeField, contentTypeJSON, contentTypeForm, ct)
+		}
+	}
+
+	h.method = http.MethodPost
+	if m, ok := cfg.Spec.Notification.Delivery[methodField]; ok {
+		h.method, err = parseMethod(m)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pf, ok := cfg.Spec.Notification.Delivery[payloadFieldsKey]; ok {
+		h.fields, err = parsePayloadFields(pf)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpl, err := template.New("http_template").Funcs(notifiers.TemplateFuncs()).Parse(httpTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %v", err)
 	}
@@ -94,8 +144,9 @@ func (h *httpNotifier) SendNotification(ctx context.Context, build *cbpb.Build)
 		return fmt.Errorf("failed to resolve bindings: %w", err)
 	}
 	h.tmplView = &notifiers.TemplateView{
-		Build:  &notifiers.BuildView{Build: build},
-		Params: bindings,
+		Build:        &notifiers.BuildView{Build: build},
+		Params:       bindings,
+		NotifierName: h.name,
 	}
 
 	logURL, err := notifiers.AddUTMParams(build.LogUrl, notifiers.HTTPMedium)
@@ -104,22 +155,28 @@ func (h *httpNotifier) SendNotification(ctx context.Context, build *cbpb.Build)
 	}
 	build.LogUrl = logURL
 
-	payload := new(bytes.Buffer)
 	var buf bytes.Buffer
-	if err := h.tmpl.Execute(&buf, h.tmplView); err != nil {
+	if len(h.fields) > 0 {
+		if err := h.renderFields(&buf); err != nil {
+			return err
+		}
+	} else if err := h.tmpl.Execute(&buf, h.tmplView); err != nil {
 		return err
 	}
-	err = json.NewEncoder(payload).Encode(buf)
-	if err != nil {
-		return fmt.Errorf("failed to encode payload: %w", err)
+	body := buf.String()
+	if h.contentType == contentTypeForm {
+		body, err = formEncode(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, strings.NewReader(buf.String()))
+	req, err := http.NewRequestWithContext(ctx, h.method, h.url, strings.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("failed to create a new HTTP request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", h.contentType)
 	req.Header.Set("User-Agent", "GCB-Notifier/0.1 (http)")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to make HTTP request: %w", err)
 	}
@@ -132,3 +189,75 @@ func (h *httpNotifier) SendNotification(ctx context.Context, build *cbpb.Build)
 	log.V(2).Infoln("send HTTP request successfully")
 	return nil
 }
+
+// parseMethod parses the `method` delivery config field, one of allowedMethods in any case.
+func parseMethod(v interface{}) (string, error) {
+	if m, ok := v.(string); ok {
+		for _, a := range allowedMethods {
+			if strings.EqualFold(m, a) {
+				return a, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("expected delivery config field %q to be one of %q, got %v", methodField, allowedMethods, v)
+}
+
+// formEncode converts the rendered template, a flat JSON object, into a URL-encoded form.
+// String values are used as-is and all other values are encoded as JSON.
+func formEncode(rendered []byte) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rendered, &fields); err != nil {
+		return "", fmt.Errorf("expected template to render a JSON object for form encoding: %w", err)
+	}
+	vals := url.Values{}
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			vals.Set(k, s)
+			continue
+		}
+		j, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode form field %q: %w", k, err)
+		}
+		vals.Set(k, string(j))
+	}
+	return vals.Encode(), nil
+}
+
+// parsePayloadFields parses the `payloadFields` delivery config field, a map of payload key to value template.
+func parsePayloadFields(v interface{}) (map[string]*template.Template, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		return nil, fmt.Errorf("expected delivery config field %q to be a non-empty map of payload keys to templates, got %v", payloadFieldsKey, v)
+	}
+	fields := map[string]*template.Template{}
+	for k, t := range m {
+		key, ok := k.(string)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected delivery config field %q to have non-empty string keys, got %v", payloadFieldsKey, k)
+		}
+		ts, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected delivery config field %q key %q to be a template string, got %v", payloadFieldsKey, key, t)
+		}
+		tmpl, err := template.New(key).Funcs(notifiers.TemplateFuncs()).Parse(ts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template for payload field %q: %w", key, err)
+		}
+		fields[key] = tmpl
+	}
+	return fields, nil
+}
+
+// renderFields writes the JSON object of every payload field rendered from the template view.
+func (h *httpNotifier) renderFields(w io.Writer) error {
+	payload := map[string]string{}
+	for key, tmpl := range h.fields {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, h.tmplView); err != nil {
+			return fmt.Errorf("failed to render payload field %q: %w", key, err)
+		}
+		payload[key] = buf.String()
+	}
+	return json.NewEncoder(w).Encode(payload)
+}
@@ -16,10 +16,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
 	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestSetUp(t *testing.T) {
@@ -128,6 +135,62 @@ func TestSetUp(t *testing.T) {
 			},
 		},
 		wantErr: true,
+	}, {
+		name: "empty payloadFields",
+		cfg: &notifiers.Config{
+			Spec: &notifiers.Spec{
+				Notification: &notifiers.Notification{
+					Filter: `build.status == Build.Status.SUCCESS`,
+					Delivery: map[string]interface{}{
+						"url":           url,
+						"payloadFields": map[interface{}]interface{}{},
+					},
+				},
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "bad payloadFields template",
+		cfg: &notifiers.Config{
+			Spec: &notifiers.Spec{
+				Notification: &notifiers.Notification{
+					Filter: `build.status == Build.Status.SUCCESS`,
+					Delivery: map[string]interface{}{
+						"url":           url,
+						"payloadFields": map[interface{}]interface{}{"text": "{{.Build.Id"},
+					},
+				},
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "unsupported method",
+		cfg: &notifiers.Config{
+			Spec: &notifiers.Spec{
+				Notification: &notifiers.Notification{
+					Filter: `build.status == Build.Status.SUCCESS`,
+					Delivery: map[string]interface{}{
+						"url":    url,
+						"method": "DELETE",
+					},
+				},
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "unsupported contentType",
+		cfg: &notifiers.Config{
+			Spec: &notifiers.Spec{
+				Notification: &notifiers.Notification{
+					Filter: `build.status == Build.Status.SUCCESS`,
+					Delivery: map[string]interface{}{
+						"url":         url,
+						"contentType": "text/plain",
+					},
+				},
+			},
+		},
+		wantErr: true,
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			n := new(httpNotifier)
@@ -151,6 +214,172 @@ func TestSetUp(t *testing.T) {
 	}
 }
 
+func TestSendNotificationContentType(t *testing.T) {
+	const tmpl = `{"status": "{{.Build.Status}}", "id": "{{.Build.Id}}", "count": 2}`
+
+	for _, tc := range []struct {
+		name            string
+		contentType     string
+		wantContentType string
+		check           func(t *testing.T, body string)
+	}{{
+		name:            "default JSON",
+		wantContentType: "application/json",
+		check: func(t *testing.T, body string) {
+			if want := `{"status": "SUCCESS", "id": "some-build-id", "count": 2}`; body != want {
+				t.Errorf("got body %q, want %q", body, want)
+			}
+		},
+	}, {
+		name:            "form",
+		contentType:     "application/x-www-form-urlencoded",
+		wantContentType: "application/x-www-form-urlencoded",
+		check: func(t *testing.T, body string) {
+			vals, err := url.ParseQuery(body)
+			if err != nil {
+				t.Fatalf("failed to parse form body %q: %v", body, err)
+			}
+			want := url.Values{"status": {"SUCCESS"}, "id": {"some-build-id"}, "count": {"2"}}
+			if vals.Encode() != want.Encode() {
+				t.Errorf("got form %v, want %v", vals, want)
+			}
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotContentType, gotBody string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotContentType = r.Header.Get("Content-Type")
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("failed to read request body: %v", err)
+				}
+				gotBody = string(b)
+			}))
+			defer srv.Close()
+
+			delivery := map[string]interface{}{"url": srv.URL}
+			if tc.contentType != "" {
+				delivery["contentType"] = tc.contentType
+			}
+			cfg := &notifiers.Config{
+				Spec: &notifiers.Spec{
+					Notification: &notifiers.Notification{
+						Filter:   `build.status == Build.Status.SUCCESS`,
+						Delivery: delivery,
+					},
+				},
+			}
+			n := new(httpNotifier)
+			if err := n.SetUp(context.Background(), cfg, tmpl, new(fakeSecretGetter), &fakeBindingResolver{}); err != nil {
+				t.Fatalf("SetUp failed: %v", err)
+			}
+			if err := n.SendNotification(context.Background(), &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_SUCCESS}); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			if gotContentType != tc.wantContentType {
+				t.Errorf("got Content-Type %q, want %q", gotContentType, tc.wantContentType)
+			}
+			tc.check(t, gotBody)
+		})
+	}
+}
+
+func TestSendNotificationMethod(t *testing.T) {
+	for _, tc := range []struct {
+		method     string
+		wantMethod string
+	}{
+		{"", http.MethodPost},
+		{"POST", http.MethodPost},
+		{"PUT", http.MethodPut},
+		{"patch", http.MethodPatch},
+	} {
+		t.Run(tc.wantMethod+"/"+tc.method, func(t *testing.T) {
+			var gotMethod string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+			}))
+			defer srv.Close()
+
+			delivery := map[string]interface{}{"url": srv.URL}
+			if tc.method != "" {
+				delivery["method"] = tc.method
+			}
+			cfg := &notifiers.Config{
+				Spec: &notifiers.Spec{
+					Notification: &notifiers.Notification{
+						Filter:   `build.status == Build.Status.SUCCESS`,
+						Delivery: delivery,
+					},
+				},
+			}
+			n := new(httpNotifier)
+			if err := n.SetUp(context.Background(), cfg, `{"id": "{{.Build.Id}}"}`, new(fakeSecretGetter), &fakeBindingResolver{}); err != nil {
+				t.Fatalf("SetUp failed: %v", err)
+			}
+			if err := n.SendNotification(context.Background(), &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_SUCCESS}); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			if gotMethod != tc.wantMethod {
+				t.Errorf("got method %q, want %q", gotMethod, tc.wantMethod)
+			}
+		})
+	}
+}
+
+func TestSendNotificationPayloadFields(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		fields map[interface{}]interface{}
+		want   map[string]string
+	}{{
+		name:   "slack-shaped",
+		fields: map[interface{}]interface{}{"text": "Build {{.Build.Id}} is {{.Build.Status}}"},
+		want:   map[string]string{"text": "Build some-build-id is SUCCESS"},
+	}, {
+		name: "custom-shaped",
+		fields: map[interface{}]interface{}{
+			"build_id": "{{.Build.Id}}",
+			"state":    "{{.Build.Status}}",
+			"branch":   `{{index .Build.Substitutions "BRANCH_NAME"}}`,
+		},
+		want: map[string]string{"build_id": "some-build-id", "state": "SUCCESS", "branch": "main"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got map[string]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+			}))
+			defer srv.Close()
+
+			cfg := &notifiers.Config{
+				Spec: &notifiers.Spec{
+					Notification: &notifiers.Notification{
+						Filter:   `build.status == Build.Status.SUCCESS`,
+						Delivery: map[string]interface{}{"url": srv.URL, "payloadFields": tc.fields},
+					},
+				},
+			}
+			n := new(httpNotifier)
+			if err := n.SetUp(context.Background(), cfg, `{"unused": true}`, new(fakeSecretGetter), &fakeBindingResolver{}); err != nil {
+				t.Fatalf("SetUp failed: %v", err)
+			}
+			build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_SUCCESS, Substitutions: map[string]string{"BRANCH_NAME": "main"}}
+			if err := n.SendNotification(context.Background(), build); err != nil {
+				t.Fatalf("SendNotification failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected payload (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 const urlSecretResource = "projects/test-project/secrets/test-secret/versions/latest"
 const urlSecret = "http://example.com/?secret"
 
@@ -162,3 +391,9 @@ func (f *fakeSecretGetter) GetSecret(_ context.Context, name string) (string, er
 	}
 	return urlSecret, nil
 }
+
+type fakeBindingResolver struct{}
+
+func (f *fakeBindingResolver) Resolve(_ context.Context, _ notifiers.SecretGetter, _ *cbpb.Build) (map[string]string, error) {
+	return map[string]string{}, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"regexp"
+)
+
+var (
+	// ansiPattern matches complete ANSI escape sequences: CSI sequences such as colors (`ESC[31m`) and cursor movement,
+	// OSC sequences such as hyperlinks and window titles, terminated by BEL or ST, and two-byte escapes such as `ESC c`.
+	ansiPattern = regexp.MustCompile("\x1b(?:\\[[0-?]*[ -/]*[@-~]|\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|[0-Z\\\\^-~])")
+	// ansiTailPattern matches an escape sequence cut off at the end of truncated text.
+	ansiTailPattern = regexp.MustCompile("\x1b(?:\\[[0-?]*[ -/]*|\\][^\x07\x1b]*)?$")
+	// ansiHeadPattern matches the rest of a common CSI sequence, e.g. `[0;31m` of a color, whose ESC was cut off at the
+	// start of truncated text. Only sequences with numeric parameters match, so that text like `[INFO]` is kept.
+	ansiHeadPattern = regexp.MustCompile(`^\[[0-9;]*[0-9][mKJGHA-D]`)
+)
+
+// StripANSI returns s without ANSI escape sequences, such as the colors that tools write to build logs, which GitHub
+// and chat services render as garbage. Sequences cut off at either end of s, e.g. by truncating a log, are removed too.
+func StripANSI(s string) string {
+	s = ansiPattern.ReplaceAllString(s, "")
+	s = ansiTailPattern.ReplaceAllString(s, "")
+	return ansiHeadPattern.ReplaceAllString(s, "")
+}
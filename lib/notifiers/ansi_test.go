@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestStripANSI(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "plain",
+		in:   "[INFO] step 1: ok",
+		want: "[INFO] step 1: ok",
+	}, {
+		name: "colors",
+		in:   "\x1b[1;31mERROR\x1b[0m: \x1b[33mtest failed\x1b[m",
+		want: "ERROR: test failed",
+	}, {
+		name: "256 and true colors",
+		in:   "\x1b[38;5;196mred\x1b[39m \x1b[48;2;0;0;255mblue\x1b[49m",
+		want: "red blue",
+	}, {
+		name: "cursor movement and erase",
+		in:   "Pulling\x1b[2K\x1b[1G\x1b[1Adone",
+		want: "Pullingdone",
+	}, {
+		name: "hyperlink",
+		in:   "see \x1b]8;;https://example.com\x07the docs\x1b]8;;\x1b\\ for more",
+		want: "see the docs for more",
+	}, {
+		name: "two-byte escape",
+		in:   "reset\x1bcdone",
+		want: "resetdone",
+	}, {
+		name: "cut off at the end",
+		in:   "\x1b[32mPASS\x1b[0m\n\x1b[31mFAIL\x1b[0",
+		want: "PASS\nFAIL",
+	}, {
+		name: "lone ESC at the end",
+		in:   "FAIL\x1b",
+		want: "FAIL",
+	}, {
+		name: "OSC cut off at the end",
+		in:   "FAIL \x1b]8;;https://exam",
+		want: "FAIL ",
+	}, {
+		name: "ESC cut off at the start",
+		in:   "[0;31mFAIL\x1b[0m",
+		want: "FAIL",
+	}, {
+		name: "bracketed text at the start is kept",
+		in:   "[3rd party] [1m",
+		want: "[3rd party] [1m",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := StripANSI(tc.in); got != tc.want {
+				t.Errorf("StripANSI(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStripANSITemplateFunc(t *testing.T) {
+	tmpl := template.Must(template.New("strip").Funcs(TemplateFuncs()).Parse(`{{stripAnsi .}}`))
+	var buf bytes.Buffer
+	if err := ExecuteTemplate(tmpl, &buf, "\x1b[31merror\x1b[0m"); err != nil {
+		t.Fatalf("ExecuteTemplate got unexpected error: %v", err)
+	}
+	if buf.String() != "error" {
+		t.Errorf("rendered %q, want %q", buf.String(), "error")
+	}
+}
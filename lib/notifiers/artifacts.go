@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"cloud.google.com/go/storage"
+)
+
+// ArtifactURLTTLField is the delivery config field that GetArtifactLinker reads the lifetime of signed artifact URLs
+// from. If it's unset, artifact URLs are unsigned.
+const ArtifactURLTTLField = "artifactUrlTtl"
+
+// maxSignedURLTTL is the longest lifetime GCS allows for V4 signed URLs.
+const maxSignedURLTTL = 7 * 24 * time.Hour
+
+// ArtifactLinker links to the GCS objects that builds upload as artifacts. The zero value links to them with plain,
+// unsigned URLs, which only work for publicly readable buckets.
+type ArtifactLinker struct {
+	// TTL is the lifetime of signed URLs. If it's zero, URLs are unsigned.
+	TTL  time.Duration
+	sign func(bucket, object string, opts *storage.SignedURLOptions) (string, error)
+}
+
+// GetArtifactLinker returns the ArtifactLinker configured in the `artifactUrlTtl` delivery config field, a duration
+// (e.g. `15m`) after which the signed URLs it makes expire. If the field is unset, it returns a linker making unsigned
+// URLs. Signing uses the runtime service account, which needs permission to sign blobs as itself.
+func GetArtifactLinker(ctx context.Context, cfg *Config) (*ArtifactLinker, error) {
+	v, ok := cfg.Spec.Notification.Delivery[ArtifactURLTTLField]
+	if !ok {
+		return new(ArtifactLinker), nil
+	}
+	s, _ := v.(string)
+	ttl, err := time.ParseDuration(s)
+	if err != nil || ttl <= 0 || ttl > maxSignedURLTTL {
+		return nil, fmt.Errorf("expected delivery config field %q to be a duration between 0s and %v, got %v", ArtifactURLTTLField, maxSignedURLTTL, v)
+	}
+	if Validating(ctx) {
+		return &ArtifactLinker{TTL: ttl}, nil
+	}
+	sc, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new GCS client: %w", err)
+	}
+	return &ArtifactLinker{
+		TTL: ttl,
+		sign: func(bucket, object string, opts *storage.SignedURLOptions) (string, error) {
+			return sc.Bucket(bucket).SignedURL(object, opts)
+		},
+	}, nil
+}
+
+// URL returns a URL of the build's artifact at the given path, which is either a `gs://bucket/object` path or relative
+// to the build's `artifacts.objects.location`.
+func (a *ArtifactLinker) URL(build *cbpb.Build, path string) (string, error) {
+	bucket, object, err := artifactObject(build, path)
+	if err != nil {
+		return "", err
+	}
+	if a == nil || a.TTL <= 0 {
+		return (&url.URL{Scheme: "https", Host: "storage.googleapis.com", Path: "/" + bucket + "/" + object}).String(), nil
+	}
+	u, err := a.sign(bucket, object, &storage.SignedURLOptions{
+		Method:  http.MethodGet,
+		Expires: time.Now().Add(a.TTL),
+		Scheme:  storage.SigningSchemeV4,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL of artifact %q: %w", path, err)
+	}
+	return u, nil
+}
+
+// artifactObject returns the GCS bucket and object of the build's artifact at the given path.
+func artifactObject(build *cbpb.Build, path string) (bucket, object string, err error) {
+	if path == "" {
+		return "", "", fmt.Errorf("expected an artifact path, got %q", path)
+	}
+	full := path
+	if !strings.HasPrefix(path, "gs://") {
+		loc := build.GetArtifacts().GetObjects().GetLocation()
+		if !strings.HasPrefix(loc, "gs://") {
+			return "", "", fmt.Errorf("Build %q uploads no artifacts to GCS to find %q in", build.GetId(), path)
+		}
+		full = strings.TrimSuffix(loc, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+	split := strings.SplitN(strings.TrimPrefix(full, "gs://"), "/", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", "", fmt.Errorf("expected artifact path %q to name a GCS object", full)
+	}
+	return split[0], split[1], nil
+}
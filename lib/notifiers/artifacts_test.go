@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"strconv"
+	"testing"
+	"text/template"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"cloud.google.com/go/storage"
+)
+
+func artifactBuild() *cbpb.Build {
+	return &cbpb.Build{
+		Id: "some-build-id",
+		Artifacts: &cbpb.Artifacts{
+			Objects: &cbpb.Artifacts_ArtifactObjects{Location: "gs://some-bucket/reports/"},
+		},
+	}
+}
+
+func TestArtifactURLUnsigned(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		build   *cbpb.Build
+		path    string
+		want    string
+		wantErr bool
+	}{{
+		name:  "relative",
+		build: artifactBuild(),
+		path:  "coverage/badge.svg",
+		want:  "https://storage.googleapis.com/some-bucket/reports/coverage/badge.svg",
+	}, {
+		name:  "escaped",
+		build: artifactBuild(),
+		path:  "test report #1.svg",
+		want:  "https://storage.googleapis.com/some-bucket/reports/test%20report%20%231.svg",
+	}, {
+		name:  "absolute",
+		build: &cbpb.Build{Id: "some-build-id"},
+		path:  "gs://other-bucket/badge.svg",
+		want:  "https://storage.googleapis.com/other-bucket/badge.svg",
+	}, {
+		name:    "no artifacts location",
+		build:   &cbpb.Build{Id: "some-build-id"},
+		path:    "badge.svg",
+		wantErr: true,
+	}, {
+		name:    "bucket only",
+		build:   artifactBuild(),
+		path:    "gs://some-bucket",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := new(ArtifactLinker).URL(tc.build, tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("URL(%q) = %q, want error", tc.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("URL(%q) failed: %v", tc.path, err)
+			}
+			if got != tc.want {
+				t.Errorf("URL(%q) = %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestArtifactURLSigned(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	a := &ArtifactLinker{
+		TTL: 15 * time.Minute,
+		sign: func(bucket, object string, opts *storage.SignedURLOptions) (string, error) {
+			opts.GoogleAccessID = "notifier@some-project.iam.gserviceaccount.com"
+			opts.PrivateKey = pemKey
+			return storage.SignedURL(bucket, object, opts)
+		},
+	}
+
+	got, err := a.URL(artifactBuild(), "coverage/badge.svg")
+	if err != nil {
+		t.Fatalf("URL failed: %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("URL returned unparsable URL %q: %v", got, err)
+	}
+	if u.Host != "storage.googleapis.com" || u.Path != "/some-bucket/reports/coverage/badge.svg" {
+		t.Errorf("URL = %q, want a URL of gs://some-bucket/reports/coverage/badge.svg", got)
+	}
+	if got := u.Query().Get("X-Goog-Algorithm"); got != "GOOG4-RSA-SHA256" {
+		t.Errorf("URL param X-Goog-Algorithm = %q, want %q", got, "GOOG4-RSA-SHA256")
+	}
+	// The expiry is relative to the time of signing, so allow for the test's own runtime.
+	if got, err := strconv.Atoi(u.Query().Get("X-Goog-Expires")); err != nil || got < 890 || got > 900 {
+		t.Errorf("URL param X-Goog-Expires = %q, want about 900", u.Query().Get("X-Goog-Expires"))
+	}
+	if u.Query().Get("X-Goog-Signature") == "" {
+		t.Errorf("URL %q is not signed", got)
+	}
+}
+
+func TestTemplateViewArtifactURL(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`![coverage]({{.ArtifactURL "badge.svg"}})`))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &TemplateView{Build: &BuildView{Build: artifactBuild()}}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if want := "![coverage](https://storage.googleapis.com/some-bucket/reports/badge.svg)"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGetArtifactLinker(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		delivery map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "unset", delivery: map[string]interface{}{}},
+		{name: "not a duration", delivery: map[string]interface{}{ArtifactURLTTLField: "soon"}, wantErr: true},
+		{name: "zero", delivery: map[string]interface{}{ArtifactURLTTLField: "0s"}, wantErr: true},
+		{name: "too long", delivery: map[string]interface{}{ArtifactURLTTLField: "192h"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Spec: &Spec{Notification: &Notification{Delivery: tc.delivery}}}
+			a, err := GetArtifactLinker(context.Background(), cfg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("GetArtifactLinker succeeded unexpectedly: %+v", a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetArtifactLinker failed: %v", err)
+			}
+			if a.TTL != 0 {
+				t.Errorf("GetArtifactLinker TTL = %v, want 0", a.TTL)
+			}
+		})
+	}
+}
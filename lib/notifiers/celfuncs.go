@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// celBuildFunc is a helper function available to CEL filters that takes the `build` as its only argument.
+type celBuildFunc struct {
+	name       string
+	resultType *exprpb.Type
+	fn         func(*cbpb.Build) ref.Val
+}
+
+// celBuildFuncs are the helper functions available to all CEL filters, e.g. `hitTimeout(build)`.
+var celBuildFuncs = []*celBuildFunc{{
+	name:       "hitTimeout",
+	resultType: decls.Bool,
+	fn: func(b *cbpb.Build) ref.Val {
+		return types.Bool(b.GetStatus() == cbpb.Build_TIMEOUT)
+	},
+}, {
+	name:       "pendingApproval",
+	resultType: decls.Bool,
+	fn: func(b *cbpb.Build) ref.Val {
+		return types.Bool(b.GetApproval().GetState() == cbpb.BuildApproval_PENDING)
+	},
+}, {
+	name:       "queueSeconds",
+	resultType: decls.Int,
+	fn: func(b *cbpb.Build) ref.Val {
+		d, _ := queueDuration(b)
+		return types.Int(d / time.Second)
+	},
+}}
+
+// celFuncDecls returns the CEL declarations of celBuildFuncs.
+func celFuncDecls() []*exprpb.Decl {
+	var ds []*exprpb.Decl
+	for _, f := range celBuildFuncs {
+		ds = append(ds, decls.NewFunction(f.name,
+			decls.NewOverload(f.name+"_build", []*exprpb.Type{decls.NewObjectType(cloudBuildProtoPkg + ".Build")}, f.resultType)))
+	}
+	return ds
+}
+
+// celFuncOverloads returns the CEL implementations of celBuildFuncs.
+func celFuncOverloads() []*functions.Overload {
+	var ols []*functions.Overload
+	for _, f := range celBuildFuncs {
+		f := f
+		unary := func(v ref.Val) ref.Val {
+			b, ok := v.Value().(*cbpb.Build)
+			if !ok {
+				return types.NewErr("%s: expected a Build argument, got %s", f.name, v.Type())
+			}
+			return f.fn(b)
+		}
+		ols = append(ols, &functions.Overload{Operator: f.name, Unary: unary})
+	}
+	return ols
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+const (
+	// cloudEventsContentType is the media type of CloudEvents sent in structured mode, with the event's attributes and
+	// data in a JSON envelope.
+	cloudEventsContentType = "application/cloudevents+json"
+	// messagePublishedType is the type of the CloudEvents that Eventarc sends for Pub/Sub messages, e.g. those that
+	// Cloud Build publishes to the `cloud-builds` topic.
+	messagePublishedType = "google.cloud.pubsub.topic.v1.messagePublished"
+)
+
+// cloudEvent is a CloudEvent in the JSON format used by structured mode.
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md.
+type cloudEvent struct {
+	SpecVersion string          `json:"specversion"`
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+	DataBase64  []byte          `json:"data_base64"`
+}
+
+// decodePushRequest returns the Pub/Sub push message in the body of a request to the receiver. Requests carrying
+// CloudEvents, as Eventarc delivers them, are detected by their `ce-specversion` header (binary mode) or their content
+// type (structured mode), and their event data is decoded instead: either the Pub/Sub message of a messagePublished
+// event, which has the same shape as a push request, or, for other event types, a build itself.
+func decodePushRequest(h http.Header, body []byte) (*pubSubPushWrapper, error) {
+	var ce cloudEvent
+	var data []byte
+	if mt, _, err := mime.ParseMediaType(h.Get("Content-Type")); err == nil && mt == cloudEventsContentType {
+		if err := json.Unmarshal(body, &ce); err != nil {
+			return nil, fmt.Errorf("failed to decode structured CloudEvent: %w", err)
+		}
+		if ce.SpecVersion == "" {
+			return nil, errors.New("expected structured CloudEvent to have a specversion")
+		}
+		data = ce.DataBase64
+		if data == nil {
+			data = ce.Data
+		}
+	} else if v := h.Get("Ce-Specversion"); v != "" {
+		ce = cloudEvent{SpecVersion: v, ID: h.Get("Ce-Id"), Type: h.Get("Ce-Type")}
+		data = body
+	} else {
+		pspw := new(pubSubPushWrapper)
+		if err := json.Unmarshal(body, pspw); err != nil {
+			return nil, err
+		}
+		return pspw, nil
+	}
+
+	pspw := new(pubSubPushWrapper)
+	if ce.Type != messagePublishedType {
+		pspw.Message = pubSubPushMessage{ID: ce.ID, Data: data}
+		return pspw, nil
+	}
+	if err := json.Unmarshal(data, pspw); err != nil {
+		return nil, fmt.Errorf("failed to decode data of CloudEvent %q as a Pub/Sub message: %w", ce.ID, err)
+	}
+	if pspw.Message.ID == "" {
+		pspw.Message.ID = ce.ID
+	}
+	return pspw, nil
+}
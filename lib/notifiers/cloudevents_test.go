@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestNewReceiverCloudEvents(t *testing.T) {
+	sentBuild := &cbpb.Build{
+		ProjectId:     "some-project",
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"foo": "bar"},
+	}
+	data := buildJSON(t, sentBuild)
+	published := fmt.Sprintf(`{"message": {"data": %q, "messageId": "some-message-id"}, "subscription": "projects/some-project/subscriptions/eventarc"}`,
+		base64.StdEncoding.EncodeToString(data))
+
+	for _, tc := range []struct {
+		name     string
+		header   http.Header
+		body     string
+		wantCode int
+	}{{
+		name:     "legacy push request",
+		header:   http.Header{"Content-Type": {"application/json"}},
+		body:     fmt.Sprintf(`{"message": {"data": %q, "id": "some-message-id"}}`, base64.StdEncoding.EncodeToString(data)),
+		wantCode: http.StatusOK,
+	}, {
+		name: "binary messagePublished event",
+		header: http.Header{
+			"Content-Type":   {"application/json"},
+			"Ce-Specversion": {"1.0"},
+			"Ce-Id":          {"some-event-id"},
+			"Ce-Type":        {messagePublishedType},
+			"Ce-Source":      {"//pubsub.googleapis.com/projects/some-project/topics/cloud-builds"},
+		},
+		body:     published,
+		wantCode: http.StatusOK,
+	}, {
+		name:   "structured messagePublished event",
+		header: http.Header{"Content-Type": {"application/cloudevents+json; charset=utf-8"}},
+		body: fmt.Sprintf(`{"specversion": "1.0", "id": "some-event-id", "type": %q, "source": "//pubsub.googleapis.com/", "data": %s}`,
+			messagePublishedType, published),
+		wantCode: http.StatusOK,
+	}, {
+		name: "binary build event",
+		header: http.Header{
+			"Content-Type":   {"application/json"},
+			"Ce-Specversion": {"1.0"},
+			"Ce-Id":          {"some-event-id"},
+			"Ce-Type":        {"google.cloud.cloudbuild.build.v1.statusChanged"},
+		},
+		body:     string(data),
+		wantCode: http.StatusOK,
+	}, {
+		name:   "structured build event with base64 data",
+		header: http.Header{"Content-Type": {"application/cloudevents+json"}},
+		body: fmt.Sprintf(`{"specversion": "1.0", "id": "some-event-id", "type": "google.cloud.cloudbuild.build.v1.statusChanged", "data_base64": %q}`,
+			base64.StdEncoding.EncodeToString(data)),
+		wantCode: http.StatusOK,
+	}, {
+		name:     "structured event without a specversion",
+		header:   http.Header{"Content-Type": {"application/cloudevents+json"}},
+		body:     fmt.Sprintf(`{"id": "some-event-id", "type": %q, "data": %s}`, messagePublishedType, published),
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "malformed structured event",
+		header:   http.Header{"Content-Type": {"application/cloudevents+json"}},
+		body:     `{"specversion": `,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "messagePublished event with malformed data",
+		header: http.Header{
+			"Ce-Specversion": {"1.0"},
+			"Ce-Id":          {"some-event-id"},
+			"Ce-Type":        {messagePublishedType},
+		},
+		body:     `{"message": "not a message"}`,
+		wantCode: http.StatusBadRequest,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := &signalingNotifier{builds: make(chan *cbpb.Build, 1)}
+			req := httptest.NewRequest(http.MethodPost, "http://notifier.example.com/", bytes.NewBufferString(tc.body))
+			req.Header = tc.header
+			w := httptest.NewRecorder()
+			newReceiver(n, &receiverParams{})(w, req)
+
+			if got := w.Result().StatusCode; got != tc.wantCode {
+				t.Fatalf("got status code %d, want %d", got, tc.wantCode)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			select {
+			case got := <-n.builds:
+				if diff := cmp.Diff(sentBuild, got, protocmp.Transform()); diff != "" {
+					t.Errorf("unexpected difference between sent Build and received Build:\n%s", diff)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("failed to receive a Build from the notifier before the timeout")
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"cloud.google.com/go/storage"
+	log "github.com/golang/glog"
+)
+
+// Substitutions set by the built-in enrichers.
+const (
+	// CommitterSubstitution is set by CommitterEnricher.
+	CommitterSubstitution = "_COMMITTER"
+	// LogTailSubstitution is set by LogTailEnricher.
+	LogTailSubstitution = "_LOG_TAIL"
+)
+
+// Enricher augments a build event, typically by setting substitutions, before it's passed to SendNotification, so the
+// notifier's templates can use data that the Cloud Build event lacks.
+type Enricher interface {
+	Enrich(context.Context, *cbpb.Build) error
+}
+
+// EnricherFunc adapts a function to an Enricher.
+type EnricherFunc func(context.Context, *cbpb.Build) error
+
+// Enrich calls f.
+func (f EnricherFunc) Enrich(ctx context.Context, build *cbpb.Build) error {
+	return f(ctx, build)
+}
+
+// EnrichingNotifier is implemented by notifiers that need build events enriched. Main runs the notifier's enrichers, in
+// order, on every build event before calling SendNotification. Enrichers is called once per event, after SetUp.
+type EnrichingNotifier interface {
+	Notifier
+	Enrichers() []Enricher
+}
+
+// enrich runs the notifier's enrichers, if any, on the build in order. Enrichment is best-effort: failures are logged
+// and the remaining enrichers still run.
+func enrich(ctx context.Context, notifier Notifier, build *cbpb.Build) {
+	en, ok := notifier.(EnrichingNotifier)
+	if !ok {
+		return
+	}
+	for i, e := range en.Enrichers() {
+		if err := e.Enrich(ctx, build); err != nil {
+			log.Warningf("enricher %d (%T) failed for Build %q: %v", i, e, build.Id, err)
+		}
+	}
+}
+
+// setSubstitution sets the build's substitution unless it's already set.
+func setSubstitution(build *cbpb.Build, key, value string) {
+	if build.Substitutions == nil {
+		build.Substitutions = map[string]string{}
+	}
+	if _, ok := build.Substitutions[key]; !ok {
+		build.Substitutions[key] = value
+	}
+}
+
+// CommitterEnricher sets a substitution of builds to the committer that Lookup returns, e.g. from the source host's API.
+// Builds for which Lookup returns "" are left as-is.
+type CommitterEnricher struct {
+	// Lookup returns the build's committer, or "" if it has none.
+	Lookup func(context.Context, *cbpb.Build) (string, error)
+	// Substitution is the substitution set to the committer. It defaults to CommitterSubstitution.
+	Substitution string
+	// Set, if non-nil, sets the substitution, e.g. to apply the notifier's own overwrite rules. By default, builds that
+	// already set the substitution keep it, and their committer isn't looked up.
+	Set func(build *cbpb.Build, key, value string)
+}
+
+// Enrich implements Enricher.
+func (c *CommitterEnricher) Enrich(ctx context.Context, build *cbpb.Build) error {
+	key := c.Substitution
+	if key == "" {
+		key = CommitterSubstitution
+	}
+	set := c.Set
+	if set == nil {
+		if _, ok := build.Substitutions[key]; ok {
+			return nil
+		}
+		set = setSubstitution
+	}
+	committer, err := c.Lookup(ctx, build)
+	if err != nil {
+		return fmt.Errorf("failed to look up committer: %w", err)
+	}
+	if committer != "" {
+		set(build, key, committer)
+	}
+	return nil
+}
+
+// LogTailEnricher sets the LogTailSubstitution of failed builds to the last lines of their log in the build's logs
+// bucket, with ANSI escape sequences stripped (see StripANSI). Builds that didn't fail, or that log elsewhere, are left
+// as-is.
+type LogTailEnricher struct {
+	// Lines is the number of lines kept.
+	Lines int
+	// FailedStep keeps only the lines of the build's failed step (see BuildView.FailedStepView), without their
+	// StepLogPrefix. The tail of the
+	// whole log is kept if no step failed, e.g. if the build timed out between steps.
+	FailedStep bool
+	grf        gcsReaderFactory
+}
+
+// NewLogTailEnricher returns a LogTailEnricher keeping the given number of lines, reading logs with a new GCS client.
+func NewLogTailEnricher(ctx context.Context, lines int) (*LogTailEnricher, error) {
+	if Validating(ctx) {
+		return &LogTailEnricher{Lines: lines}, nil
+	}
+	sc, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new GCS client: %w", err)
+	}
+	return &LogTailEnricher{Lines: lines, grf: &actualGCSReaderFactory{sc}}, nil
+}
+
+// maxLogLineBytes is the longest log line LogTailEnricher reads.
+const maxLogLineBytes = 1 << 20
+
+// Enrich implements Enricher.
+func (l *LogTailEnricher) Enrich(ctx context.Context, build *cbpb.Build) error {
+	switch build.Status {
+	case cbpb.Build_FAILURE, cbpb.Build_INTERNAL_ERROR, cbpb.Build_TIMEOUT:
+	default:
+		return nil
+	}
+	bucket, object, ok := logObject(build)
+	if !ok {
+		return nil
+	}
+	r, err := l.grf.NewReader(ctx, bucket, object)
+	if err != nil {
+		return fmt.Errorf("failed to get reader for (bucket=%q, object=%q): %w", bucket, object, err)
+	}
+	defer r.Close()
+
+	prefix := ""
+	if l.FailedStep {
+		if s := (&BuildView{Build: build}).FailedStepView(); s != nil {
+			prefix = StepLogPrefix(s.Index, s.BuildStep)
+		}
+	}
+	tail := make([]string, 0, l.Lines)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxLogLineBytes)
+	for sc.Scan() {
+		line := sc.Text()
+		if prefix != "" {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			line = strings.TrimPrefix(line, prefix)
+		}
+		if len(tail) == l.Lines {
+			tail = append(tail[:0], tail[1:]...)
+		}
+		tail = append(tail, line)
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read log of Build %q: %w", build.Id, err)
+	}
+	// The tail is stripped as a whole, so that only its start counts as a truncation boundary.
+	setSubstitution(build, LogTailSubstitution, StripANSI(strings.Join(tail, "\n")))
+	return nil
+}
+
+// StepLogPrefix returns the prefix that Cloud Build puts on the log lines of the build step at the given index, e.g.
+// `Step #2 - "test": `.
+func StepLogPrefix(index int, step *cbpb.BuildStep) string {
+	label := fmt.Sprintf("Step #%d", index)
+	if step.GetId() != "" {
+		label += fmt.Sprintf(" - %q", step.GetId())
+	}
+	return label + ": "
+}
+
+// BuildLogs opens the logs of builds in their logs buckets, for notifiers that need more of a log than
+// LogTailEnricher keeps.
+type BuildLogs struct {
+	grf gcsReaderFactory
+}
+
+// NewBuildLogs returns a BuildLogs reading logs with a new GCS client.
+func NewBuildLogs(ctx context.Context) (*BuildLogs, error) {
+	if Validating(ctx) {
+		return new(BuildLogs), nil
+	}
+	sc, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new GCS client: %w", err)
+	}
+	return &BuildLogs{grf: &actualGCSReaderFactory{sc}}, nil
+}
+
+// Open returns a reader of the build's log, which the caller must close, or false if the build doesn't log to GCS.
+func (b *BuildLogs) Open(ctx context.Context, build *cbpb.Build) (io.ReadCloser, bool, error) {
+	bucket, object, ok := logObject(build)
+	if !ok {
+		return nil, false, nil
+	}
+	r, err := b.grf.NewReader(ctx, bucket, object)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get reader for (bucket=%q, object=%q): %w", bucket, object, err)
+	}
+	return r, true, nil
+}
+
+// logObject returns the GCS bucket and object of the build's log, per its `gs://bucket[/path]` logs bucket, and false if
+// the build doesn't log to GCS.
+func logObject(build *cbpb.Build) (bucket, object string, ok bool) {
+	path := strings.TrimPrefix(build.GetLogsBucket(), "gs://")
+	if path == build.GetLogsBucket() || path == "" || build.GetId() == "" {
+		return "", "", false
+	}
+	split := strings.SplitN(strings.TrimSuffix(path, "/"), "/", 2)
+	object = "log-" + build.GetId() + ".txt"
+	if len(split) == 2 {
+		object = split[1] + "/" + object
+	}
+	return split[0], object, true
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeEnrichingNotifier struct {
+	fakeNotifier
+	enrichers []Enricher
+}
+
+func (f *fakeEnrichingNotifier) Enrichers() []Enricher {
+	return f.enrichers
+}
+
+// appendTrail returns an enricher that appends the given value to the _TRAIL substitution.
+func appendTrail(v string) Enricher {
+	return EnricherFunc(func(_ context.Context, build *cbpb.Build) error {
+		if t := build.Substitutions["_TRAIL"]; t != "" {
+			v = t + "," + v
+		}
+		build.Substitutions["_TRAIL"] = v
+		return nil
+	})
+}
+
+func TestDispatchEnrichers(t *testing.T) {
+	n := &fakeEnrichingNotifier{
+		fakeNotifier: fakeNotifier{notifs: make(chan *cbpb.Build, 1)},
+		enrichers: []Enricher{
+			appendTrail("first"),
+			EnricherFunc(func(context.Context, *cbpb.Build) error { return errors.New("boom") }),
+			appendTrail("second"),
+			&CommitterEnricher{Lookup: func(_ context.Context, build *cbpb.Build) (string, error) {
+				// Later enrichers see the earlier ones' substitutions.
+				return "octocat@" + build.Substitutions["_TRAIL"], nil
+			}},
+		},
+	}
+	if err := dispatch(context.Background(), n, &cbpb.Build{Id: "some-build-id", Substitutions: map[string]string{}}); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+	want := map[string]string{"_TRAIL": "first,second", CommitterSubstitution: "octocat@first,second"}
+	if diff := cmp.Diff(want, (<-n.notifs).Substitutions); diff != "" {
+		t.Errorf("unexpected substitutions (-want +got):\n%s", diff)
+	}
+}
+
+func TestDispatchWithoutEnrichers(t *testing.T) {
+	n := &fakeNotifier{notifs: make(chan *cbpb.Build, 1)}
+	if err := dispatch(context.Background(), n, &cbpb.Build{Id: "some-build-id"}); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+	if got := (<-n.notifs).Substitutions; got != nil {
+		t.Errorf("got substitutions %v, want none", got)
+	}
+}
+
+func TestCommitterEnricher(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		substs  map[string]string
+		lookup  string
+		err     error
+		want    map[string]string
+		wantErr bool
+	}{{
+		name:   "sets committer",
+		lookup: "octocat",
+		want:   map[string]string{CommitterSubstitution: "octocat"},
+	}, {
+		name:   "keeps existing committer",
+		substs: map[string]string{CommitterSubstitution: "hubot"},
+		lookup: "octocat",
+		want:   map[string]string{CommitterSubstitution: "hubot"},
+	}, {
+		name: "no committer found",
+	}, {
+		name:    "lookup failure",
+		err:     errors.New("boom"),
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &CommitterEnricher{Lookup: func(context.Context, *cbpb.Build) (string, error) { return tc.lookup, tc.err }}
+			build := &cbpb.Build{Substitutions: tc.substs}
+			if err := e.Enrich(context.Background(), build); (err != nil) != tc.wantErr {
+				t.Fatalf("Enrich got error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, build.Substitutions); diff != "" {
+				t.Errorf("unexpected substitutions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommitterEnricherHooks(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		enricher *CommitterEnricher
+		substs   map[string]string
+		want     map[string]string
+	}{{
+		name:     "sets the given substitution",
+		enricher: &CommitterEnricher{Substitution: "_AUTHOR"},
+		want:     map[string]string{"_AUTHOR": "octocat"},
+	}, {
+		name: "sets with the given function",
+		enricher: &CommitterEnricher{Set: func(build *cbpb.Build, key, value string) {
+			build.Substitutions[key] = value
+		}},
+		substs: map[string]string{CommitterSubstitution: "hubot"},
+		want:   map[string]string{CommitterSubstitution: "octocat"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.enricher.Lookup = func(context.Context, *cbpb.Build) (string, error) { return "octocat", nil }
+			build := &cbpb.Build{Substitutions: tc.substs}
+			if err := tc.enricher.Enrich(context.Background(), build); err != nil {
+				t.Fatalf("Enrich failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, build.Substitutions); diff != "" {
+				t.Errorf("unexpected substitutions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLogTailEnricher(t *testing.T) {
+	grf := &fakeGCSReaderFactory{data: map[string]string{
+		"gs://some-bucket/log-some-build-id.txt":           "starting\nstep 1\nstep 2\nerror: boom\n",
+		"gs://some-bucket/some/path/log-some-build-id.txt": "only line",
+		"gs://some-bucket/colored/log-some-build-id.txt":   "\x1b[32mstep 1\x1b[0m\n\x1b[1;31mstep 2\x1b[0m\n\x1b[31merror: \x1b[1mboom\x1b[0m\n",
+		"gs://some-bucket/steps/log-some-build-id.txt": "Step #0 - \"build\": compiling\nStep #1 - \"test\": running\nStep #1 - \"test\": FAIL: TestFoo\n" +
+			"Step #0 - \"build\": done\nStep #1 - \"test\": exit status 1\nERROR: build step 1 failed\n",
+	}}
+	failedSteps := []*cbpb.BuildStep{{Id: "build", Status: cbpb.Build_SUCCESS}, {Id: "test", Status: cbpb.Build_FAILURE}}
+	for _, tc := range []struct {
+		name       string
+		build      *cbpb.Build
+		failedStep bool
+		want       map[string]string
+		wantErr    bool
+	}{{
+		name:  "failed build",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, LogsBucket: "gs://some-bucket"},
+		want:  map[string]string{LogTailSubstitution: "step 2\nerror: boom"},
+	}, {
+		name:  "logs bucket with a path",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_TIMEOUT, LogsBucket: "gs://some-bucket/some/path/"},
+		want:  map[string]string{LogTailSubstitution: "only line"},
+	}, {
+		name:  "colored log",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, LogsBucket: "gs://some-bucket/colored"},
+		want:  map[string]string{LogTailSubstitution: "step 2\nerror: boom"},
+	}, {
+		name:       "failed step",
+		build:      &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, LogsBucket: "gs://some-bucket/steps", Steps: failedSteps},
+		failedStep: true,
+		want:       map[string]string{LogTailSubstitution: "FAIL: TestFoo\nexit status 1"},
+	}, {
+		name: "internal error step",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_INTERNAL_ERROR, LogsBucket: "gs://some-bucket/steps",
+			Steps: []*cbpb.BuildStep{{Id: "build", Status: cbpb.Build_SUCCESS}, {Id: "test", Status: cbpb.Build_INTERNAL_ERROR}}},
+		failedStep: true,
+		want:       map[string]string{LogTailSubstitution: "FAIL: TestFoo\nexit status 1"},
+	}, {
+		name:  "failed step not kept",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE, LogsBucket: "gs://some-bucket/steps", Steps: failedSteps},
+		want:  map[string]string{LogTailSubstitution: "Step #1 - \"test\": exit status 1\nERROR: build step 1 failed"},
+	}, {
+		name:       "no failed step",
+		build:      &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_TIMEOUT, LogsBucket: "gs://some-bucket/steps"},
+		failedStep: true,
+		want:       map[string]string{LogTailSubstitution: "Step #1 - \"test\": exit status 1\nERROR: build step 1 failed"},
+	}, {
+		name:  "successful build",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_SUCCESS, LogsBucket: "gs://some-bucket"},
+	}, {
+		name:  "no logs bucket",
+		build: &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE},
+	}, {
+		name:    "missing log",
+		build:   &cbpb.Build{Id: "other-build-id", Status: cbpb.Build_FAILURE, LogsBucket: "gs://some-bucket"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &LogTailEnricher{Lines: 2, FailedStep: tc.failedStep, grf: grf}
+			if err := e.Enrich(context.Background(), tc.build); (err != nil) != tc.wantErr {
+				t.Fatalf("Enrich got error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, tc.build.Substitutions); diff != "" {
+				t.Errorf("unexpected substitutions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepLogPrefix(t *testing.T) {
+	if got, want := StepLogPrefix(2, &cbpb.BuildStep{Id: "test"}), `Step #2 - "test": `; got != want {
+		t.Errorf("got prefix %q, want %q", got, want)
+	}
+	if got, want := StepLogPrefix(0, &cbpb.BuildStep{}), "Step #0: "; got != want {
+		t.Errorf("got prefix %q, want %q", got, want)
+	}
+}
+
+func TestTemplateViewLogTail(t *testing.T) {
+	v := &TemplateView{Build: &BuildView{Build: &cbpb.Build{Substitutions: map[string]string{LogTailSubstitution: "error: boom"}}}}
+	if got := v.LogTail(); got != "error: boom" {
+		t.Errorf("got log tail %q, want %q", got, "error: boom")
+	}
+	if got := (&TemplateView{}).LogTail(); got != "" {
+		t.Errorf("got log tail %q without a build, want none", got)
+	}
+}
+
+func TestBuildLogsOpen(t *testing.T) {
+	b := &BuildLogs{grf: &fakeGCSReaderFactory{data: map[string]string{
+		"gs://some-bucket/some/path/log-some-build-id.txt": "Step #0: ok\n",
+	}}}
+	for _, tc := range []struct {
+		name    string
+		build   *cbpb.Build
+		want    string
+		wantOK  bool
+		wantErr bool
+	}{{
+		name:   "log",
+		build:  &cbpb.Build{Id: "some-build-id", LogsBucket: "gs://some-bucket/some/path"},
+		want:   "Step #0: ok\n",
+		wantOK: true,
+	}, {
+		name:  "no logs bucket",
+		build: &cbpb.Build{Id: "some-build-id"},
+	}, {
+		name:    "missing log",
+		build:   &cbpb.Build{Id: "other-build-id", LogsBucket: "gs://some-bucket/some/path"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, ok, err := b.Open(context.Background(), tc.build)
+			if (err != nil) != tc.wantErr || ok != tc.wantOK {
+				t.Fatalf("Open got (ok %t, error %v), want (ok %t, error: %t)", ok, err, tc.wantOK, tc.wantErr)
+			}
+			if !ok {
+				return
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got log %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"gopkg.in/yaml.v2"
+)
+
+// IdentitiesField is the delivery config field that GetIdentities reads the identity map from.
+const IdentitiesField = "identities"
+
+// Identity is a person's identities in the services that notifiers deliver to.
+type Identity struct {
+	// SlackID is the Slack member ID, e.g. `U0123ABCD`, used in `<@...>` mentions.
+	SlackID string `yaml:"slackId"`
+	// TeamsID is the Microsoft Teams user ID or UPN used in mentions.
+	TeamsID string `yaml:"teamsId"`
+	// Email is the email address.
+	Email string `yaml:"email"`
+}
+
+// Identities maps GitHub logins to their identities in other services.
+type Identities map[string]*Identity
+
+// Lookup returns the identity of the given GitHub login, compared case-insensitively, or nil if it has none.
+func (ids Identities) Lookup(login string) *Identity {
+	return ids[strings.ToLower(login)]
+}
+
+// GetIdentities returns the identity map in the `identities` delivery config field, or nil if there is none. The field
+// holds the map itself, a `secretRef` to a secret holding it as YAML, or the `gs://` path of a YAML object holding it.
+// Notifiers should call it in SetUp and keep the result rather than loading it per build.
+func GetIdentities(ctx context.Context, cfg *Config, sg SecretGetter) (Identities, error) {
+	v, ok := cfg.Spec.Notification.Delivery[IdentitiesField]
+	if !ok {
+		return nil, nil
+	}
+	var grf gcsReaderFactory
+	if _, ok := v.(string); ok && !Validating(ctx) {
+		sc, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create new GCS client: %w", err)
+		}
+		defer sc.Close()
+		grf = &actualGCSReaderFactory{sc}
+	}
+	return getIdentities(ctx, cfg, sg, grf)
+}
+
+func getIdentities(ctx context.Context, cfg *Config, sg SecretGetter, grf gcsReaderFactory) (Identities, error) {
+	v := cfg.Spec.Notification.Delivery[IdentitiesField]
+	var raw []byte
+	switch t := v.(type) {
+	case string:
+		m := gcsConfigPattern.FindStringSubmatch(t)
+		if m == nil {
+			return nil, fmt.Errorf("expected delivery config field %q to be a map, a secret ref, or a gs:// path, got %q", IdentitiesField, t)
+		}
+		if Validating(ctx) {
+			return nil, nil
+		}
+		r, err := grf.NewReader(ctx, m[1], m[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reader for (bucket=%q, object=%q): %w", m[1], m[2], err)
+		}
+		defer r.Close()
+		if raw, err = ioutil.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("failed to read identities from %q: %w", t, err)
+		}
+	case map[interface{}]interface{}:
+		if _, ok := t[secretRef]; ok {
+			ref, err := GetSecretRef(cfg.Spec.Notification.Delivery, IdentitiesField)
+			if err != nil {
+				return nil, err
+			}
+			resource, err := FindSecretResourceName(cfg.Spec.Secrets, ref)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find Secret for ref %q: %w", ref, err)
+			}
+			if Validating(ctx) {
+				return nil, nil
+			}
+			s, err := sg.GetSecret(ctx, resource)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get identities secret: %w", err)
+			}
+			raw = []byte(s)
+			break
+		}
+		var err error
+		if raw, err = yaml.Marshal(t); err != nil {
+			return nil, fmt.Errorf("failed to re-encode delivery config field %q: %w", IdentitiesField, err)
+		}
+	default:
+		return nil, fmt.Errorf("expected delivery config field %q to be a map, a secret ref, or a gs:// path, got %v", IdentitiesField, v)
+	}
+	return parseIdentities(raw)
+}
+
+// parseIdentities parses a YAML map of GitHub login to Identity.
+func parseIdentities(raw []byte) (Identities, error) {
+	var m map[string]*Identity
+	if err := yaml.UnmarshalStrict(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse identities: %w", err)
+	}
+	ids := Identities{}
+	for login, id := range m {
+		if login == "" || id == nil {
+			return nil, fmt.Errorf("expected identities to map GitHub logins to identities, got %q: %v", login, id)
+		}
+		ids[strings.ToLower(login)] = id
+	}
+	return ids, nil
+}
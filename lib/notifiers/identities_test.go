@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const identitiesYAML = `
+octocat:
+  slackId: U0123ABCD
+  teamsId: octocat@example.com
+  email: octocat@example.com
+hubot:
+  email: hubot@example.com
+`
+
+func TestGetIdentities(t *testing.T) {
+	want := Identities{
+		"octocat": {SlackID: "U0123ABCD", TeamsID: "octocat@example.com", Email: "octocat@example.com"},
+		"hubot":   {Email: "hubot@example.com"},
+	}
+	sg := &fakeSecretGetter{secrets: map[string]string{"projects/p/secrets/identities/versions/latest": identitiesYAML}}
+	grf := &fakeGCSReaderFactory{data: map[string]string{"gs://some-bucket/identities.yaml": identitiesYAML}}
+
+	for _, tc := range []struct {
+		name    string
+		field   interface{}
+		want    Identities
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name: "inline",
+		field: map[interface{}]interface{}{
+			"Octocat": map[interface{}]interface{}{"slackId": "U0123ABCD", "teamsId": "octocat@example.com", "email": "octocat@example.com"},
+			"hubot":   map[interface{}]interface{}{"email": "hubot@example.com"},
+		},
+		want: want,
+	}, {
+		name:  "secret",
+		field: map[interface{}]interface{}{"secretRef": "identities"},
+		want:  want,
+	}, {
+		name:  "GCS object",
+		field: "gs://some-bucket/identities.yaml",
+		want:  want,
+	}, {
+		name:    "missing GCS object",
+		field:   "gs://some-bucket/nope.yaml",
+		wantErr: true,
+	}, {
+		name:    "not a gs:// path",
+		field:   "identities.yaml",
+		wantErr: true,
+	}, {
+		name:    "unknown secret ref",
+		field:   map[interface{}]interface{}{"secretRef": "nope"},
+		wantErr: true,
+	}, {
+		name:    "unknown identity field",
+		field:   map[interface{}]interface{}{"octocat": map[interface{}]interface{}{"slack": "U0123ABCD"}},
+		wantErr: true,
+	}, {
+		name:    "login without identity",
+		field:   map[interface{}]interface{}{"octocat": nil},
+		wantErr: true,
+	}, {
+		name:    "list",
+		field:   []interface{}{"octocat"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			delivery := map[string]interface{}{}
+			if tc.field != nil {
+				delivery[IdentitiesField] = tc.field
+			}
+			cfg := &Config{Spec: &Spec{
+				Notification: &Notification{Delivery: delivery},
+				Secrets:      []*Secret{{LocalName: "identities", ResourceName: "projects/p/secrets/identities/versions/latest"}},
+			}}
+			var got Identities
+			var err error
+			if tc.field == nil {
+				got, err = GetIdentities(context.Background(), cfg, sg)
+			} else {
+				got, err = getIdentities(context.Background(), cfg, sg, grf)
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("got identities %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected identities diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestIdentitiesLookup(t *testing.T) {
+	ids, err := parseIdentities([]byte(identitiesYAML))
+	if err != nil {
+		t.Fatalf("parseIdentities failed: %v", err)
+	}
+	for _, tc := range []struct {
+		login     string
+		wantSlack string
+		wantTeams string
+		wantEmail string
+	}{
+		{"octocat", "U0123ABCD", "octocat@example.com", "octocat@example.com"},
+		{"OctoCat", "U0123ABCD", "octocat@example.com", "octocat@example.com"},
+		{"hubot", "", "", "hubot@example.com"},
+	} {
+		id := ids.Lookup(tc.login)
+		if id == nil {
+			t.Errorf("Lookup(%q) = nil, want an identity", tc.login)
+			continue
+		}
+		if id.SlackID != tc.wantSlack || id.TeamsID != tc.wantTeams || id.Email != tc.wantEmail {
+			t.Errorf("Lookup(%q) = %+v, want Slack ID %q, Teams ID %q, and email %q", tc.login, id, tc.wantSlack, tc.wantTeams, tc.wantEmail)
+		}
+	}
+	if id := ids.Lookup("someone-else"); id != nil {
+		t.Errorf("Lookup of an unknown login = %+v, want nil", id)
+	}
+	if id := Identities(nil).Lookup("octocat"); id != nil {
+		t.Errorf("Lookup on a nil map = %+v, want nil", id)
+	}
+}
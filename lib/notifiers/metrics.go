@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"expvar"
+)
+
+// Reasons a notifier skips a build event without sending a notification, recorded by RecordFiltered.
+const (
+	// FilterReasonCEL means the build didn't match the notifier's CEL filter.
+	FilterReasonCEL = "cel_filter"
+	// FilterReasonNoRepo means the notifier couldn't determine where to send the notification from the build.
+	FilterReasonNoRepo = "no_repo"
+	// FilterReasonNoRef means the build lacks the commit or branch the notification would be attached to.
+	FilterReasonNoRef = "no_ref"
+	// FilterReasonSuppressed means the notifier's deduplication or cooldown settings suppressed the notification.
+	FilterReasonSuppressed = "suppressed"
+	// FilterReasonStale means the build is older than the notifier's maximum event age, e.g. for a late redelivery.
+	FilterReasonStale = "stale"
+	// FilterReasonBranch means the build's branch isn't one the notifier is configured for, or the build has none.
+	FilterReasonBranch = "branch"
+	// FilterReasonOptOut means the build opted out of notifications, e.g. by setting a substitution.
+	FilterReasonOptOut = "opt_out"
+	// FilterReasonEmpty means the notifier's template rendered a notification without content, e.g. a blank title.
+	FilterReasonEmpty = "empty"
+)
+
+// filteredTotal counts skipped build events by reason. Like all expvar variables, it's served as JSON at /debug/vars
+// by notifiers running with the (default) HTTP event source.
+var filteredTotal = expvar.NewMap("filtered_total")
+
+// RecordFiltered increments the filtered_total{reason} counter for a build event that was skipped without sending a
+// notification.
+func RecordFiltered(reason string) {
+	filteredTotal.Add(reason, 1)
+}
+
+// FilteredTotal returns the number of build events skipped for the given reason so far.
+func FilteredTotal(reason string) int64 {
+	v, ok := filteredTotal.Get(reason).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestRecordFiltered(t *testing.T) {
+	before := FilteredTotal(FilterReasonNoRepo)
+	RecordFiltered(FilterReasonNoRepo)
+	RecordFiltered(FilterReasonNoRepo)
+
+	if got, want := FilteredTotal(FilterReasonNoRepo), before+2; got != want {
+		t.Errorf("FilteredTotal(%q) = %d, want %d", FilterReasonNoRepo, got, want)
+	}
+	if got := FilteredTotal("never-recorded"); got != 0 {
+		t.Errorf("FilteredTotal for an unrecorded reason = %d, want 0", got)
+	}
+
+	var published map[string]int64
+	if err := json.Unmarshal([]byte(expvar.Get("filtered_total").String()), &published); err != nil {
+		t.Fatalf("failed to decode published filtered_total: %v", err)
+	}
+	if published[FilterReasonNoRepo] != before+2 {
+		t.Errorf("published filtered_total = %v, want %q: %d", published, FilterReasonNoRepo, before+2)
+	}
+}
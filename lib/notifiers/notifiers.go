@@ -27,7 +27,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,10 +41,16 @@ import (
 	log "github.com/golang/glog"
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker/decls"
+	"github.com/googleapis/gax-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"gopkg.in/yaml.v2"
 )
 
@@ -66,12 +75,24 @@ var (
 var (
 	smoketest  = flag.Bool("smoketest", false, "If true, Main will simply log the notifier type and exit.")
 	setupCheck = flag.Bool("setup_check", false, "If true, the configuration YAML is read from stdin and notifier.SetUp is called in a faked-out way. The smoketest flag takes priority over this one.")
+	validate   = flag.Bool("validate", false, "If true, the configuration YAML is read from stdin and fully validated offline, and Main exits with an error listing every problem found. Nothing is sent and no secrets are accessed. The smoketest flag takes priority over this one.")
+	tmplFile   = flag.String("template_file", "", "With --validate, a local file to read the template from instead of the config's template URI or content.")
 )
 
 var (
 	gcsConfigPattern = regexp.MustCompile(`^gs://([[\w-_.]+)/([^\\]+$)`)
+	// secretNamePattern matches a Secret Manager secret, optionally with a version (a number, `latest`, or an alias).
+	secretNamePattern = regexp.MustCompile(`^(projects/[^/]+/secrets/[^/]+)(/versions/[^/]+)?$`)
 )
 
+// Named `LOG_LEVEL` values and the glog verbosity they map to.
+// Any non-negative integer is also accepted and used as the verbosity directly.
+var logLevels = map[string]int{
+	"info":  0,
+	"debug": 2,
+	"trace": 3,
+}
+
 // Config is the common type for (YAML-based) configuration files for notifications.
 type Config struct {
 	APIVersion string    `yaml:"apiVersion"`
@@ -85,6 +106,22 @@ type Metadata struct {
 	Name string `yaml:"name"`
 }
 
+// NotifierName returns the name identifying the notifier in logs and templates: the config's `metadata.name`, or if it
+// has none, the notifier's type, e.g. "slack" for a *slackNotifier.
+func NotifierName(cfg *Config, notifier interface{}) string {
+	if cfg != nil && cfg.Metadata != nil && cfg.Metadata.Name != "" {
+		return cfg.Metadata.Name
+	}
+	t := reflect.TypeOf(notifier)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Notifier")
+}
+
 // Spec is the data container for the fields that are relevant to the functionality of the notifier.
 type Spec struct {
 	Notification *Notification `yaml:"notification"`
@@ -110,6 +147,54 @@ type Template struct {
 type TemplateView struct {
 	Build  *BuildView        `json:"Build"`
 	Params map[string]string `json:"Params"`
+	// NotifierName is the name of the notifier sending the notification; see NotifierName.
+	NotifierName string `json:"NotifierName"`
+	// Identities is the notifier's identity map, if it loaded one; see GetIdentities.
+	Identities Identities `json:"Identities,omitempty"`
+	// ArtifactLinker makes the URLs returned by ArtifactURL. If it's nil, they're unsigned; see GetArtifactLinker.
+	ArtifactLinker *ArtifactLinker `json:"-"`
+
+	// secretRefs maps the substitutions masked by MaskSecretRefs to the secrets they reference, which Secret resolves
+	// with sg.
+	secretRefs map[string]string
+	sg         SecretGetter
+}
+
+// ArtifactURL returns a URL of the build's artifact at the given path, either a `gs://` path or one relative to the
+// build's artifacts location, e.g. for embedding a coverage badge as a Markdown image. The URL is signed if the
+// notifier's ArtifactLinker signs URLs.
+func (v *TemplateView) ArtifactURL(path string) (string, error) {
+	var build *cbpb.Build
+	if v.Build != nil {
+		build = v.Build.Build
+	}
+	return v.ArtifactLinker.URL(build, path)
+}
+
+// LogTail returns the build's log tail that LogTailEnricher set, if any.
+func (v *TemplateView) LogTail() string {
+	if v.Build == nil {
+		return ""
+	}
+	return v.Build.GetSubstitutions()[LogTailSubstitution]
+}
+
+// BuildJSON returns the build in its Cloud Build API JSON form (e.g. `logUrl`, `substitutions`, `steps`), decoded into
+// generic maps and slices so that templates can `index` into fields that BuildView does not surface. It is only
+// computed when a template calls it.
+func (v *TemplateView) BuildJSON() (map[string]interface{}, error) {
+	if v.Build == nil || v.Build.Build == nil {
+		return nil, nil
+	}
+	b, err := protojson.Marshal(v.Build.Build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal build to JSON: %w", err)
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode build JSON: %w", err)
+	}
+	return m, nil
 }
 
 // BuildView is the data container that contains the build
@@ -117,6 +202,173 @@ type BuildView struct {
 	*cbpb.Build
 }
 
+// TimeoutSeconds returns the build's configured timeout in whole seconds, or 0 if it has none.
+func (b *BuildView) TimeoutSeconds() int64 {
+	return b.GetTimeout().GetSeconds()
+}
+
+// elapsed returns the time between the given timestamps, and false if either is missing.
+func elapsed(from, to *timestamppb.Timestamp) (time.Duration, bool) {
+	if from == nil || to == nil {
+		return 0, false
+	}
+	d := to.AsTime().Sub(from.AsTime())
+	if d < 0 {
+		// Guard against clock skew between the timestamps.
+		d = 0
+	}
+	return d, true
+}
+
+// roundedDuration formats the duration rounded to the second (e.g. "1m30s"), or "" if ok is false.
+func roundedDuration(d time.Duration, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return d.Round(time.Second).String()
+}
+
+// queueDuration returns how long the build waited between being created and starting, and false if it lacks either
+// timestamp.
+func queueDuration(b *cbpb.Build) (time.Duration, bool) {
+	return elapsed(b.GetCreateTime(), b.GetStartTime())
+}
+
+// QueueDuration returns how long the build waited to start after it was created, rounded to the second (e.g. "1m30s"),
+// or "" if it hasn't started yet or lacks a creation time.
+func (b *BuildView) QueueDuration() string {
+	return roundedDuration(queueDuration(b.Build))
+}
+
+// QueueSeconds returns how long the build waited to start after it was created in whole seconds, or 0 if it hasn't
+// started yet or lacks a creation time.
+func (b *BuildView) QueueSeconds() int64 {
+	d, _ := queueDuration(b.Build)
+	return int64(d / time.Second)
+}
+
+// WorkDuration returns how long the build ran between starting and finishing, rounded to the second (e.g. "4m12s"), or
+// "" if it hasn't finished yet.
+func (b *BuildView) WorkDuration() string {
+	return roundedDuration(elapsed(b.GetStartTime(), b.GetFinishTime()))
+}
+
+// WorkSeconds returns how long the build ran between starting and finishing in whole seconds, or 0 if it hasn't
+// finished yet.
+func (b *BuildView) WorkSeconds() int64 {
+	d, _ := elapsed(b.GetStartTime(), b.GetFinishTime())
+	return int64(d / time.Second)
+}
+
+// FailureType returns the type of the build's failure that Cloud Build reported (e.g. "USER_BUILD_STEP" or
+// "PUSH_FAILED"), or "" if it reported none.
+func (b *BuildView) FailureType() string {
+	t := b.GetFailureInfo().GetType()
+	if t == cbpb.Build_FailureInfo_FAILURE_TYPE_UNSPECIFIED {
+		return ""
+	}
+	return t.String()
+}
+
+// FailureDetail returns Cloud Build's explanation of the build's failure, or "" if it gave none.
+func (b *BuildView) FailureDetail() string {
+	return b.GetFailureInfo().GetDetail()
+}
+
+// ApprovalState returns the state of the build's manual approval (e.g. "PENDING", "APPROVED", or "REJECTED"), or "" if
+// the build does not require approval.
+func (b *BuildView) ApprovalState() string {
+	if b.GetApproval() == nil {
+		return ""
+	}
+	return b.GetApproval().GetState().String()
+}
+
+// buildNamePattern matches the resource name of a regional Build, i.e. `projects/{project}/locations/{region}/builds/{id}`.
+var buildNamePattern = regexp.MustCompile(`^projects/([^/]+)/locations/([^/]+)/builds/[^/]+$`)
+
+// ProjectID returns the ID of the project the build ran in, falling back to the one in the build's resource name.
+func (b *BuildView) ProjectID() string {
+	if p := b.GetProjectId(); p != "" {
+		return p
+	}
+	if m := buildNamePattern.FindStringSubmatch(b.GetName()); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Region returns the region the build ran in, taken from the build's resource name, or "global" for builds that didn't
+// run in a regional pool.
+func (b *BuildView) Region() string {
+	if m := buildNamePattern.FindStringSubmatch(b.GetName()); m != nil {
+		return m[2]
+	}
+	return "global"
+}
+
+// ConsoleURL returns the link to the build's page in the Cloud Build UI of the Google Cloud console.
+func (b *BuildView) ConsoleURL() string {
+	path := "/cloud-build/builds/" + url.PathEscape(b.GetId())
+	if r := b.Region(); r != "global" {
+		path = "/cloud-build/builds;region=" + url.PathEscape(r) + "/" + url.PathEscape(b.GetId())
+	}
+	return "https://console.cloud.google.com" + path + "?project=" + url.QueryEscape(b.ProjectID())
+}
+
+// FailedStep returns the first of the build's steps that failed or timed out, or nil if none did.
+func (b *BuildView) FailedStep() *cbpb.BuildStep {
+	if s := b.FailedStepView(); s != nil {
+		return s.BuildStep
+	}
+	return nil
+}
+
+// FailedStepView returns the StepView of FailedStep, or nil if no step failed.
+func (b *BuildView) FailedStepView() *StepView {
+	for i, s := range b.GetSteps() {
+		switch s.GetStatus() {
+		case cbpb.Build_FAILURE, cbpb.Build_INTERNAL_ERROR, cbpb.Build_TIMEOUT:
+			return &StepView{BuildStep: s, Index: i}
+		}
+	}
+	return nil
+}
+
+// StepViews returns the build's steps in order, for templates that list them.
+func (b *BuildView) StepViews() []*StepView {
+	var steps []*StepView
+	for i, s := range b.GetSteps() {
+		steps = append(steps, &StepView{BuildStep: s, Index: i})
+	}
+	return steps
+}
+
+// StepView is a build step exposed to notifier templates, with the raw BuildStep fields (e.g. `Id`, `Name`, `Status`,
+// and `ExitCode`) and helpers.
+type StepView struct {
+	*cbpb.BuildStep
+	// Index is the step's position among the build's steps.
+	Index int
+}
+
+// Label returns the step's label in the build log, e.g. `Step #2 - "test"`.
+func (s *StepView) Label() string {
+	return strings.TrimSuffix(StepLogPrefix(s.Index, s.BuildStep), ": ")
+}
+
+// Duration returns how long the step ran, rounded to the second (e.g. "2m5s"), or "" if it hasn't finished or didn't
+// run.
+func (s *StepView) Duration() string {
+	return roundedDuration(elapsed(s.GetTiming().GetStartTime(), s.GetTiming().GetEndTime()))
+}
+
+// DurationSeconds returns how long the step ran in whole seconds, or 0 if it hasn't finished or didn't run.
+func (s *StepView) DurationSeconds() int64 {
+	d, _ := elapsed(s.GetTiming().GetStartTime(), s.GetTiming().GetEndTime())
+	return int64(d / time.Second)
+}
+
 // SecretConfig is the data container used in a Spec.Notification config for referencing a secret in the Spec.Secrets list.
 type SecretConfig struct {
 	LocalName string `yaml:"secretRef"`
@@ -141,6 +393,11 @@ type pubSubPushWrapper struct {
 }
 
 // Notifier is the interface type that users should implement for usage in Cloud Build notifiers.
+//
+// SetUp is called once, before any notifications are sent. SendNotification is then called for every build event, and
+// may be called concurrently from multiple goroutines, e.g. for Pub/Sub push requests served in parallel or messages
+// received from a subscription in parallel. Implementations must therefore not keep per-event state on the notifier and
+// must synchronize any state that they share across events, such as caches or suppression windows.
 type Notifier interface {
 	SetUp(context.Context, *Config, string, SecretGetter, BindingResolver) error
 	SendNotification(context.Context, *cbpb.Build) error
@@ -188,11 +445,22 @@ func Main(notifier Notifier) error {
 	if !flag.Parsed() {
 		flag.Parse()
 	}
+	if err := setLogLevelFromEnv(); err != nil {
+		return fmt.Errorf("failed to set log level: %w", err)
+	}
 	if *smoketest {
 		log.V(0).Infof("notifier smoketest: %T", notifier)
 		return nil
 	}
 
+	if *validate {
+		if err := validateOffline(ctx, notifier, os.Stdin, *tmplFile); err != nil {
+			return fmt.Errorf("config is invalid: %w", err)
+		}
+		log.V(0).Info("config is valid")
+		return nil
+	}
+
 	if *setupCheck {
 		log.V(2).Info("starting setup check")
 		cfg, err := decodeConfig(os.Stdin)
@@ -223,6 +491,12 @@ func Main(notifier Notifier) error {
 		return nil
 	}
 
+	shutdownTracing, err := setUpTracing(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(ctx)
+
 	cfgPath, ok := GetEnv("CONFIG_PATH")
 	if !ok {
 		return errors.New("expected CONFIG_PATH to be non-empty")
@@ -255,7 +529,10 @@ func Main(notifier Notifier) error {
 		return fmt.Errorf("failed to parse template from notiifer spec %q: %w", cfg.Spec.Notification.Template, err)
 	}
 
-	sm := &actualSecretManager{client: smc}
+	sm, err := getSecretGetter(&actualSecretManager{client: smc})
+	if err != nil {
+		return err
+	}
 
 	br, err := newResolver(cfg)
 	if err != nil {
@@ -267,11 +544,43 @@ func Main(notifier Notifier) error {
 	}
 
 	_, ignoreBadMessages := GetEnv("IGNORE_BAD_MESSAGES")
+	queue, err := getDispatchQueue(notifier)
+	if err != nil {
+		return err
+	}
+	defer queue.close()
+	params := &receiverParams{ignoreBadMessages: ignoreBadMessages, name: NotifierName(cfg, notifier), queue: queue}
+
+	source := eventSourceHTTP
+	if s, ok := GetEnv("EVENT_SOURCE"); ok {
+		source = s
+	}
+	switch source {
+	case eventSourceHTTP:
+	case eventSourcePubSub:
+		subName, ok := GetEnv("PUBSUB_SUBSCRIPTION")
+		if !ok {
+			return fmt.Errorf("expected PUBSUB_SUBSCRIPTION to be non-empty with EVENT_SOURCE %q", eventSourcePubSub)
+		}
+		sub, closeSub, err := newPubSubSubscription(ctx, subName)
+		if err != nil {
+			return err
+		}
+		defer closeSub()
+		log.V(2).Infof("receiving build events from Pub/Sub subscription %q", subName)
+		return receivePubSub(ctx, sub, notifier, params)
+	default:
+		return fmt.Errorf("expected EVENT_SOURCE to be %q or %q, got %q", eventSourceHTTP, eventSourcePubSub, source)
+	}
+
+	if params.auth, err = getPushAuthenticator(); err != nil {
+		return err
+	}
 
 	log.V(2).Infoln("starting HTTP server...")
 
 	// Our Pub/Sub push receiver.
-	http.HandleFunc("/", newReceiver(notifier, &receiverParams{ignoreBadMessages}))
+	http.HandleFunc("/", newReceiver(notifier, params))
 
 	// An auxilliary, healthz-style receiver.
 	// You can call this endpoint using the curl command here:
@@ -329,21 +638,44 @@ func (a *actualGCSReaderFactory) NewReader(ctx context.Context, bucket, object s
 	return a.client.Bucket(bucket).Object(object).NewReader(ctx)
 }
 
+// secretVersionAccessor is the subset of *secretmanager.Client that actualSecretManager uses.
+type secretVersionAccessor interface {
+	AccessSecretVersion(context.Context, *smpb.AccessSecretVersionRequest, ...gax.CallOption) (*smpb.AccessSecretVersionResponse, error)
+}
+
 type actualSecretManager struct {
-	client *secretmanager.Client
-	// TODO(ljr): Do we want any sort of timed cache here?
+	client secretVersionAccessor
 }
 
+// GetSecret returns the payload of the given secret version, e.g. `projects/p/secrets/s/versions/5`. Secret names
+// without a version resolve to the latest version.
 func (a *actualSecretManager) GetSecret(ctx context.Context, name string) (string, error) {
+	version, err := secretVersionName(name)
+	if err != nil {
+		return "", err
+	}
 	// See https://github.com/GoogleCloudPlatform/golang-samples/blob/master/secretmanager/access_secret_version.go# for an example usage.
-	res, err := a.client.AccessSecretVersion(ctx, &smpb.AccessSecretVersionRequest{Name: name})
+	res, err := a.client.AccessSecretVersion(ctx, &smpb.AccessSecretVersionRequest{Name: version})
 	if err != nil {
-		return "", fmt.Errorf("failed to get secret named %q: %w", name, err)
+		return "", fmt.Errorf("failed to get secret named %q: %w", version, err)
 	}
 
 	return string(res.GetPayload().GetData()), nil
 }
 
+// secretVersionName returns the secret version resource name for the given secret or secret version resource name,
+// defaulting to the `latest` version.
+func secretVersionName(name string) (string, error) {
+	m := secretNamePattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", fmt.Errorf("expected secret %q to be of the form projects/{project}/secrets/{secret}[/versions/{version}]", name)
+	}
+	if m[2] == "" {
+		return m[1] + "/versions/latest", nil
+	}
+	return name, nil
+}
+
 // setupCheckSecretGetter is a faked-out SecretGetter that is only used by the setup check functionality in Main.
 type setupCheckSecretGetter struct{}
 
@@ -453,11 +785,7 @@ func validateConfig(cfg *Config) error {
 }
 
 func validateTemplate(s string) error {
-	_, err := template.New("").Funcs(template.FuncMap{
-		"replace": func(s, old, new string) string {
-			return strings.ReplaceAll(s, old, new)
-		},
-	}).Parse(s)
+	_, err := template.New("").Funcs(TemplateFuncs()).Parse(s)
 
 	return err
 }
@@ -467,6 +795,8 @@ func MakeCELPredicate(filter string) (*CELPredicate, error) {
 	env, err := cel.NewEnv(
 		// Declare the `build` variable for useage in CEL programs.
 		cel.Declarations(decls.NewIdent("build", decls.NewObjectType(cloudBuildProtoPkg+".Build"), nil)),
+		// Declare the helper functions that take the `build`, e.g. `hitTimeout(build)`.
+		cel.Declarations(celFuncDecls()...),
 		// Register the `Build` type in the environment.
 		cel.Types(new(cbpb.Build)),
 		// `Container` is necessary for better (enum) scoping
@@ -486,7 +816,7 @@ func MakeCELPredicate(filter string) (*CELPredicate, error) {
 		return nil, fmt.Errorf("expected CEL filter %q to have a boolean result type, but was %v", filter, ast.ResultType())
 	}
 
-	prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+	prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize), cel.Functions(celFuncOverloads()...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CEL program from filter %q: %w", filter, err)
 	}
@@ -505,60 +835,114 @@ func GetEnv(name string) (string, bool) {
 	return val, val != ""
 }
 
+// setLogLevelFromEnv sets the glog verbosity (i.e. the `-v` flag) from the `LOG_LEVEL` environment variable, if present.
+// The environment variable takes precedence over any `-v` flag passed to the binary.
+func setLogLevelFromEnv() error {
+	lvl, ok := GetEnv("LOG_LEVEL")
+	if !ok {
+		return nil
+	}
+
+	v, ok := logLevels[strings.ToLower(lvl)]
+	if !ok {
+		n, err := strconv.Atoi(lvl)
+		if err != nil || n < 0 {
+			return fmt.Errorf("expected LOG_LEVEL %q to be one of %v or a non-negative integer", lvl, logLevels)
+		}
+		v = n
+	}
+
+	if err := flag.Set("v", strconv.Itoa(v)); err != nil {
+		return fmt.Errorf("failed to set glog verbosity to %d: %w", v, err)
+	}
+	log.V(2).Infof("set log verbosity to %d from LOG_LEVEL %q", v, lvl)
+	return nil
+}
+
 type receiverParams struct {
 	ignoreBadMessages bool
+	// name is the NotifierName, which prefixes the receiver's logs.
+	name string
+	// queue, if non-nil, dispatches received build events on its workers.
+	queue *dispatchQueue
+	// auth, if non-nil, verifies that HTTP push requests come from an authenticated push subscription.
+	auth *pushAuthenticator
+}
+
+// logPrefix returns the prefix of log lines about the named notifier's events, so the logs of several notifiers can be
+// told apart.
+func logPrefix(name string) string {
+	return "[" + name + "]"
 }
 
-// newReceiver returns a Pub/Sub push HTTP receiving http.HandlerFunc that calls the given notifier.
+// newReceiver returns a Pub/Sub push HTTP receiving http.HandlerFunc that calls the given notifier. It also accepts
+// build events that Eventarc delivers as CloudEvents (see decodePushRequest).
 func newReceiver(notifier Notifier, params *receiverParams) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		var pspw pubSubPushWrapper
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer().Start(ctx, "notifiers.Receive", trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("notifier.type", fmt.Sprintf("%T", notifier)),
+				attribute.String("notifier.name", params.name),
+			))
+		defer span.End()
+
+		if err := params.auth.authenticate(ctx, r); err != nil {
+			log.Warningf("%s rejecting unauthenticated push request: %v", logPrefix(params.name), err)
+			span.SetStatus(codes.Error, "unauthenticated push request")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
-			log.Errorf("failed to read request message: %v", err)
+			log.Errorf("%s failed to read request message: %v", logPrefix(params.name), err)
 			http.Error(w, "Bad request body", http.StatusBadRequest)
 			return
 		}
 
-		if err := json.Unmarshal(body, &pspw); err != nil {
-			log.Errorf("failed to unmarshal body %q: %v", body, err)
+		pspw, err := decodePushRequest(r.Header, body)
+		if err != nil {
+			log.Errorf("%s failed to unmarshal body %q: %v", logPrefix(params.name), body, err)
 			http.Error(w, "Bad pubsub.Message JSON", http.StatusBadRequest)
 			return
 		}
 
-		log.V(2).Infof("got PubSub message with ID %q from subscription %q", pspw.Message.ID, pspw.Subscription)
+		log.V(2).Infof("%s got PubSub message with ID %q from subscription %q", logPrefix(params.name), pspw.Message.ID, pspw.Subscription)
 
-		build := new(cbpb.Build)
-		// Be as lenient as possible in unmarshalling.
-		// `Unmarshal` will fail if we get a payload with a field that is unknown to the current proto version unless `DiscardUnknown` is set.
-		uo := protojson.UnmarshalOptions{
-			AllowPartial:   true,
-			DiscardUnknown: true,
-		}
-		bv2 := protoadapt.MessageV2Of(build)
-		if err := uo.Unmarshal(pspw.Message.Data, bv2); err != nil {
+		build, err := decodeBuild(pspw.Message.Data)
+		if err != nil {
 			if params.ignoreBadMessages {
-				log.Warningf("not attempting to handle unmarshal-able Pub/Sub message id=%q data=%q publishTime=%q which gave error: %v",
-					pspw.Message.ID, string(pspw.Message.Data), pspw.Message.PublishTime, err)
+				log.Warningf("%s not attempting to handle unmarshal-able Pub/Sub message id=%q data=%q publishTime=%q which gave error: %v",
+					logPrefix(params.name), pspw.Message.ID, string(pspw.Message.Data), pspw.Message.PublishTime, err)
 				return
 			}
 
-			log.Errorf("failed to unmarshal PubSub message id=%q data=%q publishTime=%q into a Build: %v",
-				pspw.Message.ID, string(pspw.Message.Data), pspw.Message.PublishTime, err)
+			log.Errorf("%s failed to unmarshal PubSub message id=%q data=%q publishTime=%q into a Build: %v",
+				logPrefix(params.name), pspw.Message.ID, string(pspw.Message.Data), pspw.Message.PublishTime, err)
 			http.Error(w, "Bad Cloud Build Pub/Sub data", http.StatusBadRequest)
 			return
 		}
-		build = protoadapt.MessageV1Of(bv2).(*cbpb.Build)
 
-		log.V(2).Infof("got PubSub Build payload:\n%+v\nattempting to send notification", prototext.Format(build))
-		if err := notifier.SendNotification(ctx, build); err != nil {
-			log.Errorf("failed to run SendNotification: %v", err)
+		if err := params.queue.send(ctx, notifier, build); err != nil {
+			if errors.Is(err, errQueueFull) {
+				log.Warningf("%s nacking PubSub message %q: %v", logPrefix(params.name), pspw.Message.ID, err)
+				span.SetStatus(codes.Error, err.Error())
+				http.Error(w, "notification queue is full", http.StatusTooManyRequests)
+				return
+			}
+			span.SetStatus(codes.Error, "failed to send notification")
+			if IsPermanent(err) {
+				log.Errorf("%s dropping PubSub message %q after SendNotification failed permanently: %v", logPrefix(params.name), pspw.Message.ID, err)
+				return
+			}
+			log.Errorf("%s failed to run SendNotification: %v", logPrefix(params.name), err)
 			http.Error(w, "failed to send notification", http.StatusInternalServerError)
 			return
 		}
 
-		log.V(2).Infof("acking PubSub message %q with Build payload:\n%v", pspw.Message.ID, prototext.Format(build))
+		log.V(2).Infof("%s acking PubSub message %q with Build payload:\n%v", logPrefix(params.name), pspw.Message.ID, prototext.Format(build))
 	}
 }
 
@@ -594,6 +978,66 @@ func FindSecretResourceName(secrets []*Secret, ref string) (string, error) {
 	return "", fmt.Errorf("failed to find Secret with reference name %q in the given secret list", ref)
 }
 
+// SecretErrors maps config field names to the errors encountered while resolving their secrets.
+type SecretErrors map[string]error
+
+func (e SecretErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, fmt.Sprintf("field %q: %v", f, e[f]))
+	}
+	return fmt.Sprintf("failed to resolve %d secret(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// GetSecretRefs is like GetSecretRef for each of the given field names. It returns a map of field name to the Secret's
+// local reference name, or a SecretErrors covering every field whose reference is missing or malformed.
+func GetSecretRefs(config map[string]interface{}, fieldNames ...string) (map[string]string, error) {
+	refs := map[string]string{}
+	errs := SecretErrors{}
+	for _, f := range fieldNames {
+		ref, err := GetSecretRef(config, f)
+		if err != nil {
+			errs[f] = err
+			continue
+		}
+		refs[f] = ref
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return refs, nil
+}
+
+// GetSecrets resolves every field's Secret local reference name (as returned by GetSecretRefs) against the given Secret
+// list and fetches its value. It returns a map of field name to secret value, or, if any reference cannot be found or
+// fetched, no values and a SecretErrors covering every failed field.
+func GetSecrets(ctx context.Context, sg SecretGetter, secrets []*Secret, refs map[string]string) (map[string]string, error) {
+	vals := map[string]string{}
+	errs := SecretErrors{}
+	for f, ref := range refs {
+		resource, err := FindSecretResourceName(secrets, ref)
+		if err != nil {
+			errs[f] = err
+			continue
+		}
+		val, err := sg.GetSecret(ctx, resource)
+		if err != nil {
+			errs[f] = fmt.Errorf("failed to get secret %q: %w", resource, err)
+			continue
+		}
+		vals[f] = val
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return vals, nil
+}
+
 // UTMMedium is an enum that corresponds to a strict set of values for `utm_medium`.
 type UTMMedium string
 
@@ -19,24 +19,30 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"google.golang.org/protobuf/protoadapt"
 
 	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	smpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/googleapis/gax-go/v2"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -181,6 +187,51 @@ func TestMakeCELPredicate(t *testing.T) {
 			filter:    `build.status in [Build.Status.FAILURE, Build.Status.TIMEOUT] && build.substitutions["TAG_NAME"].matches("^v\\d{1}\\.\\d{1}\\.\\d{3}$")`,
 			build:     &cbpb.Build{Status: cbpb.Build_TIMEOUT, Substitutions: map[string]string{"TAG_NAME": "v1.2.003"}},
 			wantMatch: true,
+		}, {
+			name:      "hit timeout",
+			filter:    `hitTimeout(build)`,
+			build:     &cbpb.Build{Status: cbpb.Build_TIMEOUT},
+			wantMatch: true,
+		}, {
+			name:      "failure is not a timeout",
+			filter:    `hitTimeout(build)`,
+			build:     &cbpb.Build{Status: cbpb.Build_FAILURE},
+			wantMatch: false,
+		}, {
+			name:      "failures other than timeouts",
+			filter:    `build.status in [Build.Status.FAILURE, Build.Status.TIMEOUT] && !hitTimeout(build)`,
+			build:     &cbpb.Build{Status: cbpb.Build_FAILURE},
+			wantMatch: true,
+		}, {
+			name:      "pending approval",
+			filter:    `pendingApproval(build)`,
+			build:     &cbpb.Build{Status: cbpb.Build_PENDING, Approval: &cbpb.BuildApproval{State: cbpb.BuildApproval_PENDING}},
+			wantMatch: true,
+		}, {
+			name:      "approved is not pending approval",
+			filter:    `pendingApproval(build)`,
+			build:     &cbpb.Build{Status: cbpb.Build_QUEUED, Approval: &cbpb.BuildApproval{State: cbpb.BuildApproval_APPROVED}},
+			wantMatch: false,
+		}, {
+			name:      "no approval config is not pending approval",
+			filter:    `pendingApproval(build)`,
+			build:     &cbpb.Build{Status: cbpb.Build_PENDING},
+			wantMatch: false,
+		}, {
+			name:      "slow queue",
+			filter:    `queueSeconds(build) > 600`,
+			build:     &cbpb.Build{CreateTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00"), StartTime: convertToTimestamp(t, "2019-07-01T12:15:00.000-00:00")},
+			wantMatch: true,
+		}, {
+			name:      "fast queue",
+			filter:    `queueSeconds(build) > 600`,
+			build:     &cbpb.Build{CreateTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00"), StartTime: convertToTimestamp(t, "2019-07-01T12:00:05.000-00:00")},
+			wantMatch: false,
+		}, {
+			name:      "not started is not a slow queue",
+			filter:    `queueSeconds(build) > 600`,
+			build:     &cbpb.Build{Status: cbpb.Build_QUEUED, CreateTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00")},
+			wantMatch: false,
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -212,6 +263,9 @@ func TestMakeCELPredicateErrors(t *testing.T) {
 	}, {
 		name:   "bad result type",
 		filter: `build.id`,
+	}, {
+		name:   "helper with wrong argument type",
+		filter: `hitTimeout(build.id)`,
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			if _, err := MakeCELPredicate(tc.filter); err == nil {
@@ -504,6 +558,158 @@ func TestGetSecretRef(t *testing.T) {
 	}
 }
 
+func TestGetSecretRefsAggregatesErrors(t *testing.T) {
+	config := map[string]interface{}{
+		"tokenRef":    map[interface{}]interface{}{string(secretRef): "token"},
+		"passwordRef": 404,
+	}
+
+	refs, err := GetSecretRefs(config, "tokenRef")
+	if err != nil {
+		t.Fatalf("GetSecretRefs got unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"tokenRef": "token"}, refs); diff != "" {
+		t.Errorf("GetSecretRefs produced unexpected refs diff: (want- got+)\n%s", diff)
+	}
+
+	_, err = GetSecretRefs(config, "tokenRef", "passwordRef", "hmacRef")
+	var errs SecretErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("GetSecretRefs got error %v, want SecretErrors", err)
+	}
+	if len(errs) != 2 || errs["passwordRef"] == nil || errs["hmacRef"] == nil {
+		t.Errorf("GetSecretRefs got errors for %v, want errors for exactly passwordRef and hmacRef", errs)
+	}
+}
+
+func TestGetSecrets(t *testing.T) {
+	sg := &fakeSecretGetter{secrets: map[string]string{
+		"projects/p/secrets/token/versions/latest":    "s3cr3t",
+		"projects/p/secrets/password/versions/latest": "hunter2",
+	}}
+	secrets := []*Secret{
+		{LocalName: "token", ResourceName: "projects/p/secrets/token/versions/latest"},
+		{LocalName: "password", ResourceName: "projects/p/secrets/password/versions/latest"},
+		{LocalName: "hmac", ResourceName: "projects/p/secrets/hmac/versions/latest"},
+	}
+
+	for _, tc := range []struct {
+		name          string
+		refs          map[string]string
+		want          map[string]string
+		wantErrFields []string
+	}{{
+		name: "all resolved",
+		refs: map[string]string{"tokenRef": "token", "passwordRef": "password"},
+		want: map[string]string{"tokenRef": "s3cr3t", "passwordRef": "hunter2"},
+	}, {
+		name:          "partial failure reports every failed field",
+		refs:          map[string]string{"tokenRef": "token", "hmacRef": "hmac", "certRef": "cert"},
+		wantErrFields: []string{"certRef", "hmacRef"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := GetSecrets(context.Background(), sg, secrets, tc.refs)
+			if tc.wantErrFields == nil {
+				if err != nil {
+					t.Fatalf("GetSecrets got unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("GetSecrets produced unexpected values diff: (want- got+)\n%s", diff)
+				}
+				return
+			}
+
+			var errs SecretErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("GetSecrets got error %v, want SecretErrors", err)
+			}
+			var gotFields []string
+			for f := range errs {
+				gotFields = append(gotFields, f)
+			}
+			sort.Strings(gotFields)
+			if diff := cmp.Diff(tc.wantErrFields, gotFields); diff != "" {
+				t.Errorf("GetSecrets produced unexpected failed fields diff: (want- got+)\n%s", diff)
+			}
+			if got != nil {
+				t.Errorf("GetSecrets returned values %v alongside an error, want none", got)
+			}
+			for _, f := range tc.wantErrFields {
+				if !strings.Contains(err.Error(), f) {
+					t.Errorf("GetSecrets error %q does not mention field %q", err, f)
+				}
+			}
+		})
+	}
+}
+
+// fakeSecretVersionAccessor returns the requested version's name as its payload and records every request.
+type fakeSecretVersionAccessor struct {
+	requested []string
+}
+
+func (f *fakeSecretVersionAccessor) AccessSecretVersion(_ context.Context, req *smpb.AccessSecretVersionRequest, _ ...gax.CallOption) (*smpb.AccessSecretVersionResponse, error) {
+	f.requested = append(f.requested, req.GetName())
+	return &smpb.AccessSecretVersionResponse{Payload: &smpb.SecretPayload{Data: []byte("value of " + req.GetName())}}, nil
+}
+
+func TestActualSecretManagerVersions(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		secret      string
+		wantVersion string
+		wantErr     bool
+	}{{
+		name:        "latest",
+		secret:      "projects/p/secrets/token/versions/latest",
+		wantVersion: "projects/p/secrets/token/versions/latest",
+	}, {
+		name:        "pinned",
+		secret:      "projects/p/secrets/token/versions/5",
+		wantVersion: "projects/p/secrets/token/versions/5",
+	}, {
+		name:        "alias",
+		secret:      "projects/p/secrets/token/versions/prod",
+		wantVersion: "projects/p/secrets/token/versions/prod",
+	}, {
+		name:        "unversioned defaults to latest",
+		secret:      "projects/p/secrets/token",
+		wantVersion: "projects/p/secrets/token/versions/latest",
+	}, {
+		name:    "malformed",
+		secret:  "token",
+		wantErr: true,
+	}, {
+		name:    "empty version",
+		secret:  "projects/p/secrets/token/versions/",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := new(fakeSecretVersionAccessor)
+			sm := &actualSecretManager{client: fake}
+			got, err := sm.GetSecret(context.Background(), tc.secret)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("GetSecret(%q) succeeded, want error", tc.secret)
+				}
+				if len(fake.requested) != 0 {
+					t.Errorf("GetSecret(%q) requested %q, want no requests", tc.secret, fake.requested)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSecret(%q) got unexpected error: %v", tc.secret, err)
+			}
+			if diff := cmp.Diff([]string{tc.wantVersion}, fake.requested); diff != "" {
+				t.Errorf("GetSecret(%q) requested unexpected versions (-want +got):\n%s", tc.secret, diff)
+			}
+			if want := "value of " + tc.wantVersion; got != want {
+				t.Errorf("GetSecret(%q) = %q, want %q", tc.secret, got, want)
+			}
+		})
+	}
+}
+
 func TestAddUTMParams(t *testing.T) {
 	const defaultURL = "https://console.cloud.google.com/cloud-build/builds/some-build-id-here?project=12345"
 	for _, tc := range []struct {
@@ -669,6 +875,40 @@ func (f *fakeNotifier) SendNotification(_ context.Context, build *cbpb.Build) er
 	return nil
 }
 
+func TestNotifierName(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      *Config
+		notifier interface{}
+		want     string
+	}{{
+		name:     "metadata name",
+		cfg:      &Config{Metadata: &Metadata{Name: "team-a-slack"}},
+		notifier: new(fakeNotifier),
+		want:     "team-a-slack",
+	}, {
+		name:     "no metadata",
+		cfg:      &Config{},
+		notifier: new(fakeNotifier),
+		want:     "fake",
+	}, {
+		name:     "empty metadata name",
+		cfg:      &Config{Metadata: &Metadata{}},
+		notifier: &errNotifier{},
+		want:     "err",
+	}, {
+		name:     "nil config",
+		notifier: fatalNotifier{},
+		want:     "fatal",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NotifierName(tc.cfg, tc.notifier); got != tc.want {
+				t.Errorf("NotifierName() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestNewReceiver(t *testing.T) {
 	const projectID = "some-project-id"
 	sentBuild := &cbpb.Build{
@@ -779,6 +1019,11 @@ func TestNewReceiverError(t *testing.T) {
 			body:     buildToBuffer(t, new(cbpb.Build)),
 			sendErr:  errors.New("failed to reticulate splines"),
 			wantCode: http.StatusInternalServerError,
+		}, {
+			name:     "permanent send notification error",
+			body:     buildToBuffer(t, new(cbpb.Build)),
+			sendErr:  fmt.Errorf("failed to send: %w", Permanent(errors.New("splines are unreticulatable"))),
+			wantCode: http.StatusOK,
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -904,3 +1149,371 @@ func TestParseTemplate(t *testing.T) {
 	}
 
 }
+
+func TestSetLogLevelFromEnv(t *testing.T) {
+	vf := flag.Lookup("v")
+	orig := vf.Value.String()
+	defer flag.Set("v", orig)
+
+	for _, tc := range []struct {
+		name    string
+		env     string
+		want    string
+		wantErr bool
+	}{{
+		name: "unset keeps flag value",
+		env:  "",
+		want: orig,
+	}, {
+		name: "named level",
+		env:  "debug",
+		want: "2",
+	}, {
+		name: "named level is case-insensitive",
+		env:  "TRACE",
+		want: "3",
+	}, {
+		name: "numeric level",
+		env:  "5",
+		want: "5",
+	}, {
+		name:    "unknown level",
+		env:     "loud",
+		wantErr: true,
+	}, {
+		name:    "negative level",
+		env:     "-1",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			flag.Set("v", orig)
+			t.Setenv("LOG_LEVEL", tc.env)
+
+			err := setLogLevelFromEnv()
+			if err != nil {
+				if tc.wantErr {
+					t.Logf("got expected error: %v", err)
+					return
+				}
+				t.Fatalf("setLogLevelFromEnv() got unexpected error: %v", err)
+			}
+			if tc.wantErr {
+				t.Fatal("setLogLevelFromEnv() unexpectedly succeeded")
+			}
+
+			if got := vf.Value.String(); got != tc.want {
+				t.Errorf("got verbosity %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildViewTimeoutSeconds(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		build *cbpb.Build
+		want  int64
+	}{{
+		name:  "timeout set",
+		build: &cbpb.Build{Timeout: durationpb.New(10 * time.Minute)},
+		want:  600,
+	}, {
+		name:  "no timeout",
+		build: &cbpb.Build{},
+		want:  0,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (&BuildView{Build: tc.build}).TimeoutSeconds(); got != tc.want {
+				t.Errorf("TimeoutSeconds() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildViewQueueDuration(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		build       *cbpb.Build
+		want        string
+		wantSeconds int64
+	}{{
+		name:        "queued",
+		build:       &cbpb.Build{CreateTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00"), StartTime: convertToTimestamp(t, "2019-07-01T12:01:30.400-00:00")},
+		want:        "1m30s",
+		wantSeconds: 90,
+	}, {
+		name:        "started before created",
+		build:       &cbpb.Build{CreateTime: convertToTimestamp(t, "2019-07-01T12:00:01.000-00:00"), StartTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00")},
+		want:        "0s",
+		wantSeconds: 0,
+	}, {
+		name:        "not started",
+		build:       &cbpb.Build{CreateTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00")},
+		want:        "",
+		wantSeconds: 0,
+	}, {
+		name:        "no create time",
+		build:       &cbpb.Build{StartTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00")},
+		want:        "",
+		wantSeconds: 0,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			bv := &BuildView{Build: tc.build}
+			if got := bv.QueueDuration(); got != tc.want {
+				t.Errorf("QueueDuration() = %q, want %q", got, tc.want)
+			}
+			if got := bv.QueueSeconds(); got != tc.wantSeconds {
+				t.Errorf("QueueSeconds() = %d, want %d", got, tc.wantSeconds)
+			}
+		})
+	}
+}
+
+func TestBuildViewWorkDuration(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		build       *cbpb.Build
+		want        string
+		wantSeconds int64
+	}{{
+		name:        "finished",
+		build:       &cbpb.Build{StartTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00"), FinishTime: convertToTimestamp(t, "2019-07-01T12:04:11.600-00:00")},
+		want:        "4m12s",
+		wantSeconds: 251,
+	}, {
+		name:  "not finished",
+		build: &cbpb.Build{StartTime: convertToTimestamp(t, "2019-07-01T12:00:00.000-00:00")},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			bv := &BuildView{Build: tc.build}
+			if got := bv.WorkDuration(); got != tc.want {
+				t.Errorf("WorkDuration() = %q, want %q", got, tc.want)
+			}
+			if got := bv.WorkSeconds(); got != tc.wantSeconds {
+				t.Errorf("WorkSeconds() = %d, want %d", got, tc.wantSeconds)
+			}
+		})
+	}
+}
+
+func TestBuildViewFailureInfo(t *testing.T) {
+	bv := &BuildView{Build: &cbpb.Build{FailureInfo: &cbpb.Build_FailureInfo{
+		Type:   cbpb.Build_FailureInfo_USER_BUILD_STEP,
+		Detail: "Build step failure: build step 1 \"golang\" failed: step exited with non-zero status: 1",
+	}}}
+	if got, want := bv.FailureType(), "USER_BUILD_STEP"; got != want {
+		t.Errorf("FailureType() = %q, want %q", got, want)
+	}
+	if got, want := bv.FailureDetail(), bv.GetFailureInfo().GetDetail(); got != want {
+		t.Errorf("FailureDetail() = %q, want %q", got, want)
+	}
+	none := &BuildView{Build: &cbpb.Build{FailureInfo: &cbpb.Build_FailureInfo{}}}
+	if got := none.FailureType(); got != "" {
+		t.Errorf("FailureType() of an unspecified failure = %q, want none", got)
+	}
+	if got := (&BuildView{Build: &cbpb.Build{}}).FailureDetail(); got != "" {
+		t.Errorf("FailureDetail() without failure info = %q, want none", got)
+	}
+}
+
+func TestBuildViewStepViews(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	view := &TemplateView{Build: &BuildView{Build: &cbpb.Build{Steps: []*cbpb.BuildStep{
+		{Id: "build", Name: "gcr.io/cloud-builders/docker", Status: cbpb.Build_SUCCESS,
+			Timing: &cbpb.TimeSpan{StartTime: timestamppb.New(start), EndTime: timestamppb.New(start.Add(62 * time.Second))}},
+		{Name: "golang", Status: cbpb.Build_FAILURE, ExitCode: 2,
+			Timing: &cbpb.TimeSpan{StartTime: timestamppb.New(start.Add(62 * time.Second)), EndTime: timestamppb.New(start.Add(187 * time.Second))}},
+		{Id: "deploy", Name: "gcr.io/cloud-builders/gcloud", Status: cbpb.Build_CANCELLED},
+	}}}}
+
+	for _, tc := range []struct {
+		tmpl string
+		want string
+	}{
+		{`{{with .Build.FailedStepView}}{{.Label}} ({{.Name}}) exited with {{.ExitCode}} after {{.Duration}}{{end}}`, "Step #1 (golang) exited with 2 after 2m5s"},
+		{`{{range .Build.StepViews}}{{.Index}} {{.Label}} {{.Status}} {{.Duration}} {{.DurationSeconds}};{{end}}`,
+			`0 Step #0 - "build" SUCCESS 1m2s 62;1 Step #1 FAILURE 2m5s 125;2 Step #2 - "deploy" CANCELLED  0;`},
+	} {
+		t.Run(tc.tmpl, func(t *testing.T) {
+			tmpl, err := template.New("").Parse(tc.tmpl)
+			if err != nil {
+				t.Fatalf("failed to parse template: %v", err)
+			}
+			buf := new(bytes.Buffer)
+			if err := tmpl.Execute(buf, view); err != nil {
+				t.Fatalf("failed to execute template: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+	if got := (&BuildView{Build: &cbpb.Build{}}).FailedStepView(); got != nil {
+		t.Errorf("FailedStepView() without steps = %v, want nil", got)
+	}
+}
+
+func TestBuildViewApprovalState(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		build *cbpb.Build
+		want  string
+	}{{
+		name:  "pending",
+		build: &cbpb.Build{Approval: &cbpb.BuildApproval{State: cbpb.BuildApproval_PENDING}},
+		want:  "PENDING",
+	}, {
+		name:  "rejected",
+		build: &cbpb.Build{Approval: &cbpb.BuildApproval{State: cbpb.BuildApproval_REJECTED}},
+		want:  "REJECTED",
+	}, {
+		name:  "no approval config",
+		build: &cbpb.Build{},
+		want:  "",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (&BuildView{Build: tc.build}).ApprovalState(); got != tc.want {
+				t.Errorf("ApprovalState() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildViewConsoleURL(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		build       *cbpb.Build
+		wantProject string
+		wantRegion  string
+		wantURL     string
+	}{{
+		name:        "global",
+		build:       &cbpb.Build{Id: "some-build-id", ProjectId: "my-project", Name: "projects/my-project/builds/some-build-id"},
+		wantProject: "my-project",
+		wantRegion:  "global",
+		wantURL:     "https://console.cloud.google.com/cloud-build/builds/some-build-id?project=my-project",
+	}, {
+		name:        "no resource name",
+		build:       &cbpb.Build{Id: "some-build-id", ProjectId: "my-project"},
+		wantProject: "my-project",
+		wantRegion:  "global",
+		wantURL:     "https://console.cloud.google.com/cloud-build/builds/some-build-id?project=my-project",
+	}, {
+		name:        "regional",
+		build:       &cbpb.Build{Id: "some-build-id", ProjectId: "my-project", Name: "projects/my-project/locations/europe-west4/builds/some-build-id"},
+		wantProject: "my-project",
+		wantRegion:  "europe-west4",
+		wantURL:     "https://console.cloud.google.com/cloud-build/builds;region=europe-west4/some-build-id?project=my-project",
+	}, {
+		name:        "regional global location",
+		build:       &cbpb.Build{Id: "some-build-id", ProjectId: "my-project", Name: "projects/my-project/locations/global/builds/some-build-id"},
+		wantProject: "my-project",
+		wantRegion:  "global",
+		wantURL:     "https://console.cloud.google.com/cloud-build/builds/some-build-id?project=my-project",
+	}, {
+		name:        "project from resource name",
+		build:       &cbpb.Build{Id: "some-build-id", Name: "projects/other-project/locations/us-central1/builds/some-build-id"},
+		wantProject: "other-project",
+		wantRegion:  "us-central1",
+		wantURL:     "https://console.cloud.google.com/cloud-build/builds;region=us-central1/some-build-id?project=other-project",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			bv := &BuildView{Build: tc.build}
+			if got := bv.ProjectID(); got != tc.wantProject {
+				t.Errorf("ProjectID() = %q, want %q", got, tc.wantProject)
+			}
+			if got := bv.Region(); got != tc.wantRegion {
+				t.Errorf("Region() = %q, want %q", got, tc.wantRegion)
+			}
+			if got := bv.ConsoleURL(); got != tc.wantURL {
+				t.Errorf("ConsoleURL() = %q, want %q", got, tc.wantURL)
+			}
+		})
+	}
+}
+
+func TestBuildViewFailedStep(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		steps  []*cbpb.BuildStep
+		wantID string
+	}{{
+		name: "no steps",
+	}, {
+		name: "all succeeded",
+		steps: []*cbpb.BuildStep{
+			{Id: "build", Status: cbpb.Build_SUCCESS},
+			{Id: "test", Status: cbpb.Build_SUCCESS},
+		},
+	}, {
+		name: "failed step",
+		steps: []*cbpb.BuildStep{
+			{Id: "build", Status: cbpb.Build_SUCCESS},
+			{Id: "test", Status: cbpb.Build_FAILURE},
+			{Id: "deploy", Status: cbpb.Build_CANCELLED},
+		},
+		wantID: "test",
+	}, {
+		name: "first of several",
+		steps: []*cbpb.BuildStep{
+			{Id: "lint", Status: cbpb.Build_TIMEOUT},
+			{Id: "test", Status: cbpb.Build_FAILURE},
+		},
+		wantID: "lint",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := (&BuildView{Build: &cbpb.Build{Steps: tc.steps}}).FailedStep()
+			if got.GetId() != tc.wantID {
+				t.Errorf("FailedStep() = %v, want the step with ID %q", got, tc.wantID)
+			}
+			if tc.wantID == "" && got != nil {
+				t.Errorf("FailedStep() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestTemplateViewBuildJSON(t *testing.T) {
+	view := &TemplateView{Build: &BuildView{Build: &cbpb.Build{
+		Id:            "some-build-id",
+		Substitutions: map[string]string{"BRANCH_NAME": "main"},
+		Steps: []*cbpb.BuildStep{
+			{Id: "build", Name: "gcr.io/cloud-builders/docker"},
+			{Id: "test", Name: "golang", Timing: &cbpb.TimeSpan{StartTime: timestamppb.New(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))}},
+		},
+		Source: &cbpb.Source{Source: &cbpb.Source_RepoSource{RepoSource: &cbpb.RepoSource{RepoName: "some-repo"}}},
+	}}}
+
+	for _, tc := range []struct {
+		tmpl string
+		want string
+	}{
+		{`{{.BuildJSON.id}}`, "some-build-id"},
+		{`{{index .BuildJSON.substitutions "BRANCH_NAME"}}`, "main"},
+		{`{{(index .BuildJSON.steps 1).name}}`, "golang"},
+		{`{{(index .BuildJSON.steps 1).timing.startTime}}`, "2026-01-02T03:04:05Z"},
+		{`{{.BuildJSON.source.repoSource.repoName}}`, "some-repo"},
+		{`{{len .BuildJSON.steps}}`, "2"},
+	} {
+		t.Run(tc.tmpl, func(t *testing.T) {
+			tmpl, err := template.New("").Parse(tc.tmpl)
+			if err != nil {
+				t.Fatalf("failed to parse template: %v", err)
+			}
+			buf := new(bytes.Buffer)
+			if err := tmpl.Execute(buf, view); err != nil {
+				t.Fatalf("failed to execute template: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTemplateViewBuildJSONNoBuild(t *testing.T) {
+	got, err := (&TemplateView{}).BuildJSON()
+	if err != nil || got != nil {
+		t.Errorf("BuildJSON() = (%v, %v), want (nil, nil)", got, err)
+	}
+}
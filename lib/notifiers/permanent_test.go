@@ -0,0 +1,52 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPermanent(t *testing.T) {
+	cause := errors.New("bad payload")
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "plain", err: cause, want: false},
+		{name: "permanent", err: Permanent(cause), want: true},
+		{name: "wrapped permanent", err: fmt.Errorf("failed to notify: %w", Permanent(cause)), want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsPermanent(tc.err); got != tc.want {
+				t.Errorf("IsPermanent(%v) = %t, want %t", tc.err, got, tc.want)
+			}
+		})
+	}
+
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) is not nil")
+	}
+	err := Permanent(cause)
+	if !errors.Is(err, cause) || err.Error() != cause.Error() {
+		t.Errorf("Permanent(%v) = %v, want it to wrap the error", cause, err)
+	}
+	if again := Permanent(err); again != err {
+		t.Errorf("Permanent(%v) rewrapped an already permanent error", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"cloud.google.com/go/pubsub"
+	log "github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Values of the `EVENT_SOURCE` environment variable, which selects how Main receives build events.
+const (
+	// eventSourceHTTP serves an HTTP endpoint that Pub/Sub push subscriptions deliver to. This is the default.
+	eventSourceHTTP = "http"
+	// eventSourcePubSub pulls from the Pub/Sub subscription named by `PUBSUB_SUBSCRIPTION`.
+	eventSourcePubSub = "pubsub"
+)
+
+var subscriptionPattern = regexp.MustCompile(`^projects/([^/]+)/subscriptions/([^/]+)$`)
+
+// decodeBuild unmarshals the data of a Cloud Build Pub/Sub message into a Build.
+func decodeBuild(data []byte) (*cbpb.Build, error) {
+	build := new(cbpb.Build)
+	// Be as lenient as possible in unmarshalling.
+	// `Unmarshal` will fail if we get a payload with a field that is unknown to the current proto version unless `DiscardUnknown` is set.
+	uo := protojson.UnmarshalOptions{
+		AllowPartial:   true,
+		DiscardUnknown: true,
+	}
+	bv2 := protoadapt.MessageV2Of(build)
+	if err := uo.Unmarshal(data, bv2); err != nil {
+		return nil, err
+	}
+	return protoadapt.MessageV1Of(bv2).(*cbpb.Build), nil
+}
+
+// dispatch enriches the given build and calls the notifier for it within the receiving span in ctx.
+func dispatch(ctx context.Context, notifier Notifier, build *cbpb.Build) error {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("build.id", build.Id),
+		attribute.String("build.status", build.Status.String()),
+	)
+	enrich(ctx, notifier, build)
+
+	log.V(2).Infof("got PubSub Build payload:\n%+v\nattempting to send notification", prototext.Format(build))
+	sendCtx, sendSpan := tracer().Start(ctx, "notifiers.SendNotification")
+	defer sendSpan.End()
+	if err := notifier.SendNotification(sendCtx, build); err != nil {
+		sendSpan.RecordError(err)
+		sendSpan.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// subscriptionReceiver is the subset of *pubsub.Subscription that receivePubSub uses.
+type subscriptionReceiver interface {
+	Receive(ctx context.Context, f func(context.Context, *pubsub.Message)) error
+}
+
+// receivePubSub pulls build events from the subscription and calls the notifier for each until ctx is done or receiving
+// fails. Messages are acked once handled and nacked, for redelivery, if the notification fails with a retryable error.
+func receivePubSub(ctx context.Context, sub subscriptionReceiver, notifier Notifier, params *receiverParams) error {
+	return sub.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+		handlePubSubMessage(ctx, notifier, params, m, func(ack bool) {
+			if ack {
+				m.Ack()
+			} else {
+				m.Nack()
+			}
+		})
+	})
+}
+
+// handlePubSubMessage handles a pulled Pub/Sub message and calls settle with true iff it should be acked. With a
+// dispatch queue, the message is settled once a worker has dispatched it, after handlePubSubMessage returns, or nacked
+// right away if the queue is full.
+func handlePubSubMessage(ctx context.Context, notifier Notifier, params *receiverParams, m *pubsub.Message, settle func(ack bool)) {
+	ctx, span := tracer().Start(ctx, "notifiers.Receive", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("notifier.type", fmt.Sprintf("%T", notifier)),
+			attribute.String("notifier.name", params.name),
+		))
+
+	log.V(2).Infof("%s got PubSub message with ID %q", logPrefix(params.name), m.ID)
+	build, err := decodeBuild(m.Data)
+	if err != nil {
+		defer span.End()
+		if params.ignoreBadMessages {
+			log.Warningf("%s not attempting to handle unmarshal-able Pub/Sub message id=%q data=%q publishTime=%q which gave error: %v",
+				logPrefix(params.name), m.ID, string(m.Data), m.PublishTime, err)
+			settle(true)
+			return
+		}
+		log.Errorf("%s failed to unmarshal PubSub message id=%q data=%q publishTime=%q into a Build: %v",
+			logPrefix(params.name), m.ID, string(m.Data), m.PublishTime, err)
+		span.SetStatus(codes.Error, "bad Cloud Build Pub/Sub data")
+		settle(false)
+		return
+	}
+
+	done := func(err error) {
+		defer span.End()
+		settle(pubSubAck(params, span, m.ID, build, err))
+	}
+	if params.queue == nil {
+		done(dispatch(ctx, notifier, build))
+		return
+	}
+	if err := params.queue.submit(ctx, build, done); err != nil {
+		log.Warningf("%s nacking PubSub message %q: %v", logPrefix(params.name), m.ID, err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		settle(false)
+	}
+}
+
+// pubSubAck returns true iff the Pub/Sub message of the build should be acked, given the result of dispatching it.
+func pubSubAck(params *receiverParams, span trace.Span, id string, build *cbpb.Build, err error) bool {
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to send notification")
+		if IsPermanent(err) {
+			log.Errorf("%s dropping PubSub message %q after SendNotification failed permanently: %v", logPrefix(params.name), id, err)
+			return true
+		}
+		log.Errorf("%s failed to run SendNotification: %v", logPrefix(params.name), err)
+		return false
+	}
+	log.V(2).Infof("%s acking PubSub message %q with Build payload:\n%v", logPrefix(params.name), id, prototext.Format(build))
+	return true
+}
+
+// newPubSubSubscription returns a client for the given subscription resource name, i.e.
+// `projects/{project}/subscriptions/{subscription}`, and a function to close it.
+func newPubSubSubscription(ctx context.Context, name string) (*pubsub.Subscription, func() error, error) {
+	m := subscriptionPattern.FindStringSubmatch(name)
+	if m == nil {
+		return nil, nil, fmt.Errorf("expected PUBSUB_SUBSCRIPTION %q to be of the form projects/{project}/subscriptions/{subscription}", name)
+	}
+	client, err := pubsub.NewClient(ctx, m[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create new Pub/Sub client: %w", err)
+	}
+	return client.Subscription(m[2]), client.Close, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// signalingNotifier sends every build it's notified of on builds and returns err.
+type signalingNotifier struct {
+	builds chan *cbpb.Build
+	err    error
+}
+
+func (n *signalingNotifier) SetUp(_ context.Context, _ *Config, _ string, _ SecretGetter, _ BindingResolver) error {
+	return nil
+}
+
+func (n *signalingNotifier) SendNotification(_ context.Context, b *cbpb.Build) error {
+	n.builds <- b
+	return n.err
+}
+
+// newFakeSubscription returns a subscription on a fake Pub/Sub server with the given message published to its topic.
+func newFakeSubscription(t *testing.T, data []byte) (*pstest.Server, *pubsub.Subscription) {
+	t.Helper()
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := pubsub.NewClient(ctx, "some-project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	topic, err := client.CreateTopic(ctx, "cloud-builds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := client.CreateSubscription(ctx, "notifier", pubsub.SubscriptionConfig{Topic: topic, AckDeadline: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Publish("projects/some-project/topics/cloud-builds", data, nil)
+	return srv, sub
+}
+
+// buildJSON returns the Pub/Sub message data that Cloud Build publishes for the build.
+func buildJSON(t *testing.T, b *cbpb.Build) []byte {
+	t.Helper()
+	j, err := protojson.Marshal(protoadapt.MessageV2Of(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func TestReceivePubSub(t *testing.T) {
+	sentBuild := &cbpb.Build{
+		ProjectId:     "some-project",
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		Substitutions: map[string]string{"foo": "bar"},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		data    []byte
+		params  *receiverParams
+		sendErr error
+		// wantBuild is false if the notifier is not expected to be called.
+		wantBuild bool
+		wantAcked bool
+	}{{
+		name:      "decodes and acks",
+		data:      buildJSON(t, sentBuild),
+		params:    &receiverParams{},
+		wantBuild: true,
+		wantAcked: true,
+	}, {
+		name:      "nacks failed notifications",
+		data:      buildJSON(t, sentBuild),
+		params:    &receiverParams{},
+		sendErr:   errors.New("no notification for you"),
+		wantBuild: true,
+		wantAcked: false,
+	}, {
+		name:      "acks permanently failed notifications",
+		data:      buildJSON(t, sentBuild),
+		params:    &receiverParams{},
+		sendErr:   Permanent(errors.New("no notification for you, ever")),
+		wantBuild: true,
+		wantAcked: true,
+	}, {
+		name:      "acks ignored bad messages",
+		data:      []byte("not a build"),
+		params:    &receiverParams{ignoreBadMessages: true},
+		wantAcked: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, sub := newFakeSubscription(t, tc.data)
+			n := &signalingNotifier{builds: make(chan *cbpb.Build, 10), err: tc.sendErr}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- receivePubSub(ctx, sub, n, tc.params) }()
+
+			if tc.wantBuild {
+				select {
+				case got := <-n.builds:
+					if diff := cmp.Diff(sentBuild, got, protocmp.Transform()); diff != "" {
+						t.Errorf("unexpected difference between published Build and received Build:\n%s", diff)
+					}
+				case <-time.After(10 * time.Second):
+					t.Fatal("failed to receive a Build from the notifier before the timeout")
+				}
+			}
+			// Wait for the (n)ack to reach the server.
+			deadline := time.Now().Add(10 * time.Second)
+			for {
+				msgs := srv.Messages()
+				if len(msgs) == 1 && (msgs[0].Acks > 0 || (!tc.wantAcked && msgs[0].Deliveries > 0)) {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("message was not handled before the timeout: %+v", msgs)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("receivePubSub returned error: %v", err)
+			}
+
+			if got := srv.Messages()[0].Acks > 0; got != tc.wantAcked {
+				t.Errorf("message acked = %t, want %t", got, tc.wantAcked)
+			}
+		})
+	}
+}
+
+func TestNewPubSubSubscriptionBadName(t *testing.T) {
+	if _, _, err := newPubSubSubscription(context.Background(), "my-subscription"); err == nil {
+		t.Error("newPubSubSubscription succeeded with a bare subscription name, want error")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/idtoken"
+)
+
+// errNoToken is returned for push requests without a bearer token.
+var errNoToken = errors.New("request has no bearer token")
+
+// pushAuthenticator verifies the Google-signed OIDC tokens that authenticated Pub/Sub push subscriptions send as the
+// `Authorization: Bearer` header of their push requests.
+// See https://cloud.google.com/pubsub/docs/authenticate-push-subscriptions.
+type pushAuthenticator struct {
+	// audience is the audience the tokens must be issued for, as configured on the push subscription.
+	audience string
+	// serviceAccount, if non-empty, is the email of the service account the tokens must be issued to.
+	serviceAccount string
+	// validate checks the token's signature, expiry, issuer, and audience. It is idtoken.Validate outside of tests.
+	validate func(ctx context.Context, token, audience string) (*idtoken.Payload, error)
+}
+
+// getPushAuthenticator returns an authenticator of push requests if the `PUSH_AUTH_AUDIENCE` environment variable is
+// set, and nil otherwise. `PUSH_AUTH_SERVICE_ACCOUNT` optionally restricts the service account the tokens are issued to.
+func getPushAuthenticator() (*pushAuthenticator, error) {
+	sa, hasSA := GetEnv("PUSH_AUTH_SERVICE_ACCOUNT")
+	aud, ok := GetEnv("PUSH_AUTH_AUDIENCE")
+	if !ok {
+		if hasSA {
+			return nil, errors.New("expected PUSH_AUTH_AUDIENCE to be non-empty with PUSH_AUTH_SERVICE_ACCOUNT")
+		}
+		return nil, nil
+	}
+	return &pushAuthenticator{audience: aud, serviceAccount: sa, validate: idtoken.Validate}, nil
+}
+
+// authenticate returns an error unless the request carries a valid token for the audience and, if configured, the
+// service account. A nil authenticator accepts every request.
+func (a *pushAuthenticator) authenticate(ctx context.Context, r *http.Request) error {
+	if a == nil {
+		return nil
+	}
+	authz := r.Header.Get("Authorization")
+	const scheme = "bearer "
+	if len(authz) <= len(scheme) || !strings.EqualFold(authz[:len(scheme)], scheme) {
+		return errNoToken
+	}
+	p, err := a.validate(ctx, strings.TrimSpace(authz[len(scheme):]), a.audience)
+	if err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	if a.serviceAccount == "" {
+		return nil
+	}
+	email, _ := p.Claims["email"].(string)
+	if verified, _ := p.Claims["email_verified"].(bool); !verified || !strings.EqualFold(email, a.serviceAccount) {
+		return fmt.Errorf("token was issued to %q (verified: %t), want %q", email, verified, a.serviceAccount)
+	}
+	return nil
+}
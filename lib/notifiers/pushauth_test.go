@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"google.golang.org/api/idtoken"
+)
+
+const (
+	validToken = "valid-token"
+	pushAud    = "https://notifier.example.com/"
+	pushSA     = "push@some-project.iam.gserviceaccount.com"
+)
+
+// fakeValidate accepts validToken for pushAud as issued to the given email.
+func fakeValidate(email string, verified bool) func(context.Context, string, string) (*idtoken.Payload, error) {
+	return func(_ context.Context, token, audience string) (*idtoken.Payload, error) {
+		if token != validToken {
+			return nil, errors.New("bad signature")
+		}
+		if audience != pushAud {
+			return nil, fmt.Errorf("audience provided does not match aud claim in the JWT")
+		}
+		return &idtoken.Payload{Audience: audience, Claims: map[string]interface{}{"email": email, "email_verified": verified}}, nil
+	}
+}
+
+func TestNewReceiverPushAuth(t *testing.T) {
+	data := buildJSON(t, &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_SUCCESS})
+	body := fmt.Sprintf(`{"message": {"data": %q, "id": "some-message-id"}}`, base64.StdEncoding.EncodeToString(data))
+
+	for _, tc := range []struct {
+		name     string
+		auth     *pushAuthenticator
+		authz    string
+		wantCode int
+	}{{
+		name:     "disabled",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "valid token",
+		auth:     &pushAuthenticator{audience: pushAud, validate: fakeValidate(pushSA, true)},
+		authz:    "Bearer " + validToken,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "valid token of the service account",
+		auth:     &pushAuthenticator{audience: pushAud, serviceAccount: pushSA, validate: fakeValidate(pushSA, true)},
+		authz:    "bearer " + validToken,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "missing token",
+		auth:     &pushAuthenticator{audience: pushAud, validate: fakeValidate(pushSA, true)},
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "not a bearer token",
+		auth:     &pushAuthenticator{audience: pushAud, validate: fakeValidate(pushSA, true)},
+		authz:    "Basic dXNlcjpwYXNz",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "invalid token",
+		auth:     &pushAuthenticator{audience: pushAud, validate: fakeValidate(pushSA, true)},
+		authz:    "Bearer forged-token",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "wrong audience",
+		auth:     &pushAuthenticator{audience: "https://other.example.com/", validate: fakeValidate(pushSA, true)},
+		authz:    "Bearer " + validToken,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "other service account",
+		auth:     &pushAuthenticator{audience: pushAud, serviceAccount: pushSA, validate: fakeValidate("intruder@example.com", true)},
+		authz:    "Bearer " + validToken,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "unverified email",
+		auth:     &pushAuthenticator{audience: pushAud, serviceAccount: pushSA, validate: fakeValidate(pushSA, false)},
+		authz:    "Bearer " + validToken,
+		wantCode: http.StatusUnauthorized,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := &fakeNotifier{notifs: make(chan *cbpb.Build, 1)}
+			req := httptest.NewRequest(http.MethodPost, pushAud, bytes.NewBufferString(body))
+			if tc.authz != "" {
+				req.Header.Set("Authorization", tc.authz)
+			}
+			w := httptest.NewRecorder()
+			newReceiver(n, &receiverParams{auth: tc.auth})(w, req)
+
+			if got := w.Result().StatusCode; got != tc.wantCode {
+				t.Fatalf("got status code %d, want %d", got, tc.wantCode)
+			}
+			wantBuilds := 0
+			if tc.wantCode == http.StatusOK {
+				wantBuilds = 1
+			}
+			if got := len(n.notifs); got != wantBuilds {
+				t.Errorf("notifier got %d builds, want %d", got, wantBuilds)
+			}
+		})
+	}
+}
+
+func TestGetPushAuthenticator(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		aud, sa string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "unset", wantNil: true},
+		{name: "audience", aud: pushAud},
+		{name: "audience and service account", aud: pushAud, sa: pushSA},
+		{name: "service account only", sa: pushSA, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("PUSH_AUTH_AUDIENCE", tc.aud)
+			t.Setenv("PUSH_AUTH_SERVICE_ACCOUNT", tc.sa)
+			a, err := getPushAuthenticator()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("getPushAuthenticator succeeded unexpectedly")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getPushAuthenticator failed unexpectedly: %v", err)
+			}
+			if tc.wantNil {
+				if a != nil {
+					t.Errorf("getPushAuthenticator = %+v, want nil", a)
+				}
+				return
+			}
+			if a == nil || a.audience != tc.aud || a.serviceAccount != tc.sa || a.validate == nil {
+				t.Errorf("getPushAuthenticator = %+v, want audience %q and service account %q", a, tc.aud, tc.sa)
+			}
+		})
+	}
+}
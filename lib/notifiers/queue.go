@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"strconv"
+	"sync"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+// defaultDispatchWorkers is how many workers process the dispatch queue if `DISPATCH_WORKERS` isn't set.
+const defaultDispatchWorkers = 4
+
+// errQueueFull is returned for build events that arrive while the dispatch queue is full. They're nacked, so that
+// Pub/Sub redelivers them once the queue has drained.
+var errQueueFull = errors.New("dispatch queue is full")
+
+// queueRejectedTotal counts build events nacked because the dispatch queue was full.
+var queueRejectedTotal = expvar.NewInt("dispatch_queue_rejected_total")
+
+// queuedEvent is a build event waiting in the dispatch queue. done is called with the notifier's result once a worker
+// has dispatched it.
+type queuedEvent struct {
+	ctx   context.Context
+	build *cbpb.Build
+	done  func(error)
+}
+
+// dispatchQueue is a bounded queue of build events that a fixed pool of workers dispatches to the notifier, so that slow
+// notifications don't hold up receiving events and bursts of events are absorbed up to the queue's size. Events are
+// only acked once dispatched, so an event in the queue when the instance stops is redelivered.
+type dispatchQueue struct {
+	notifier Notifier
+	events   chan queuedEvent
+	wg       sync.WaitGroup
+}
+
+// newDispatchQueue returns a queue holding up to size events, dispatched by the given number of workers, which it starts.
+func newDispatchQueue(notifier Notifier, size, workers int) *dispatchQueue {
+	q := &dispatchQueue{notifier: notifier, events: make(chan queuedEvent, size)}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// getDispatchQueue returns the dispatch queue configured by the `DISPATCH_QUEUE_SIZE` and `DISPATCH_WORKERS` environment
+// variables, or nil if `DISPATCH_QUEUE_SIZE` isn't set, in which case events are dispatched as they're received.
+func getDispatchQueue(notifier Notifier) (*dispatchQueue, error) {
+	s, ok := GetEnv("DISPATCH_QUEUE_SIZE")
+	if !ok {
+		return nil, nil
+	}
+	size, err := strconv.Atoi(s)
+	if err != nil || size < 1 {
+		return nil, fmt.Errorf("expected DISPATCH_QUEUE_SIZE to be a positive integer, got %q", s)
+	}
+	workers := defaultDispatchWorkers
+	if w, ok := GetEnv("DISPATCH_WORKERS"); ok {
+		if workers, err = strconv.Atoi(w); err != nil || workers < 1 {
+			return nil, fmt.Errorf("expected DISPATCH_WORKERS to be a positive integer, got %q", w)
+		}
+	}
+	return newDispatchQueue(notifier, size, workers), nil
+}
+
+func (q *dispatchQueue) work() {
+	defer q.wg.Done()
+	for e := range q.events {
+		// An event whose receiver gave up on it while it was queued is redelivered, so it's not dispatched.
+		if err := e.ctx.Err(); err != nil {
+			e.done(fmt.Errorf("gave up on Build %q while it was queued: %w", e.build.Id, err))
+			continue
+		}
+		e.done(dispatch(e.ctx, q.notifier, e.build))
+	}
+}
+
+// submit queues the build for dispatch, calling done with the result, or returns errQueueFull without queuing it.
+func (q *dispatchQueue) submit(ctx context.Context, build *cbpb.Build, done func(error)) error {
+	select {
+	case q.events <- queuedEvent{ctx: ctx, build: build, done: done}:
+		return nil
+	default:
+		queueRejectedTotal.Add(1)
+		return errQueueFull
+	}
+}
+
+// send dispatches the build and returns the notifier's error, via the queue if there is one, in which case it waits
+// for a worker to dispatch it, or returns errQueueFull right away. It stops waiting, returning ctx's error, once ctx is
+// done, in which case the queued build isn't dispatched unless a worker has already started on it.
+func (q *dispatchQueue) send(ctx context.Context, notifier Notifier, build *cbpb.Build) error {
+	if q == nil {
+		return dispatch(ctx, notifier, build)
+	}
+	result := make(chan error, 1)
+	if err := q.submit(ctx, build, func(err error) { result <- err }); err != nil {
+		return err
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("gave up waiting for Build %q to be dispatched: %w", build.Id, ctx.Err())
+	}
+}
+
+// close stops accepting events and waits for the workers to dispatch the queued ones. It's a no-op on a nil queue.
+func (q *dispatchQueue) close() {
+	if q == nil {
+		return
+	}
+	close(q.events)
+	q.wg.Wait()
+}
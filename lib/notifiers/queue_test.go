@@ -0,0 +1,246 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"cloud.google.com/go/pubsub"
+	"github.com/google/go-cmp/cmp"
+)
+
+// blockingNotifier sends every build it's notified of on started and then waits for release before returning err.
+type blockingNotifier struct {
+	started chan *cbpb.Build
+	release chan struct{}
+	err     error
+}
+
+func newBlockingNotifier() *blockingNotifier {
+	return &blockingNotifier{started: make(chan *cbpb.Build, 10), release: make(chan struct{})}
+}
+
+func (n *blockingNotifier) SetUp(_ context.Context, _ *Config, _ string, _ SecretGetter, _ BindingResolver) error {
+	return nil
+}
+
+func (n *blockingNotifier) SendNotification(_ context.Context, b *cbpb.Build) error {
+	n.started <- b
+	<-n.release
+	return n.err
+}
+
+// waitForBuild returns the next build the notifier starts on, failing the test if there's none within a timeout.
+func (n *blockingNotifier) waitForBuild(t *testing.T) *cbpb.Build {
+	t.Helper()
+	select {
+	case b := <-n.started:
+		return b
+	case <-time.After(10 * time.Second):
+		t.Fatal("the notifier was not called before the timeout")
+		return nil
+	}
+}
+
+func TestDispatchQueue(t *testing.T) {
+	n := newBlockingNotifier()
+	q := newDispatchQueue(n, 2, 1)
+	results := make(chan string, 10)
+	submit := func(id string) error {
+		return q.submit(context.Background(), &cbpb.Build{Id: id}, func(err error) {
+			if err != nil {
+				t.Errorf("Build %q failed: %v", id, err)
+			}
+			results <- id
+		})
+	}
+
+	// The only worker takes the first build, and the next two fill the queue.
+	if err := submit("first"); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got := n.waitForBuild(t).Id; got != "first" {
+		t.Fatalf("worker started on Build %q, want %q", got, "first")
+	}
+	for _, id := range []string{"second", "third"} {
+		if err := submit(id); err != nil {
+			t.Fatalf("submit(%q) failed: %v", id, err)
+		}
+	}
+
+	rejected := queueRejectedTotal.Value()
+	if err := submit("fourth"); !errors.Is(err, errQueueFull) {
+		t.Errorf("submit to a full queue = %v, want %v", err, errQueueFull)
+	}
+	if got := queueRejectedTotal.Value() - rejected; got != 1 {
+		t.Errorf("dispatch_queue_rejected_total increased by %d, want 1", got)
+	}
+
+	// Releasing the worker drains the queue in order.
+	close(n.release)
+	q.close()
+	close(results)
+	var got []string
+	for id := range results {
+		got = append(got, id)
+	}
+	if diff := cmp.Diff([]string{"first", "second", "third"}, got); diff != "" {
+		t.Errorf("unexpected dispatched Builds (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewReceiverQueue(t *testing.T) {
+	n := newBlockingNotifier()
+	params := &receiverParams{queue: newDispatchQueue(n, 1, 1)}
+	defer params.queue.close()
+	handler := newReceiver(n, params)
+	post := func() chan int {
+		codes := make(chan int, 1)
+		go func() {
+			req := httptest.NewRequest(http.MethodPost, "http://notifer.example.com/", buildToBuffer(t, &cbpb.Build{Id: "some-build-id"}))
+			w := httptest.NewRecorder()
+			handler(w, req)
+			codes <- w.Result().StatusCode
+		}()
+		return codes
+	}
+
+	// The first event occupies the worker, and the second fills the queue.
+	first := post()
+	n.waitForBuild(t)
+	second := post()
+	deadline := time.Now().Add(10 * time.Second)
+	for len(params.queue.events) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the second event was not queued before the timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := <-post(); got != http.StatusTooManyRequests {
+		t.Errorf("event received while the queue is full got status code %d, want %d", got, http.StatusTooManyRequests)
+	}
+
+	// Queued events are only answered once dispatched.
+	select {
+	case got := <-first:
+		t.Fatalf("event got status code %d before it was dispatched", got)
+	default:
+	}
+	close(n.release)
+	for _, codes := range []chan int{first, second} {
+		if got := <-codes; got != http.StatusOK {
+			t.Errorf("queued event got status code %d, want %d", got, http.StatusOK)
+		}
+	}
+}
+
+func TestDispatchQueueSendCanceled(t *testing.T) {
+	n := newBlockingNotifier()
+	q := newDispatchQueue(n, 1, 1)
+
+	// The first build occupies the worker, so the second waits in the queue until its context is canceled.
+	firstErr := make(chan error, 1)
+	go func() { firstErr <- q.send(context.Background(), n, &cbpb.Build{Id: "first"}) }()
+	n.waitForBuild(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	secondErr := make(chan error, 1)
+	go func() { secondErr <- q.send(ctx, n, &cbpb.Build{Id: "second"}) }()
+	deadline := time.Now().Add(10 * time.Second)
+	for len(q.events) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the second build was not queued before the timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-secondErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("send of a canceled build = %v, want %v", err, context.Canceled)
+	}
+
+	// The canceled build is dropped from the queue instead of being dispatched.
+	close(n.release)
+	if err := <-firstErr; err != nil {
+		t.Errorf("send of the first build failed: %v", err)
+	}
+	q.close()
+	select {
+	case b := <-n.started:
+		t.Errorf("the notifier was called for the canceled Build %q", b.Id)
+	default:
+	}
+}
+
+func TestHandlePubSubMessageQueue(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		sendErr   error
+		wantAcked bool
+	}{
+		{name: "acks dispatched messages", wantAcked: true},
+		{name: "nacks failed messages", sendErr: errors.New("no notification for you")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newBlockingNotifier()
+			n.err = tc.sendErr
+			params := &receiverParams{queue: newDispatchQueue(n, 1, 1)}
+			defer params.queue.close()
+			acks := make(chan bool, 3)
+			handle := func() {
+				m := &pubsub.Message{ID: "some-message-id", Data: buildJSON(t, &cbpb.Build{Id: "some-build-id"})}
+				handlePubSubMessage(context.Background(), n, params, m, func(ack bool) { acks <- ack })
+			}
+
+			// Handling returns before the notifier is done, leaving the messages unsettled.
+			handle()
+			n.waitForBuild(t)
+			handle()
+			select {
+			case got := <-acks:
+				t.Fatalf("message settled with ack = %t before it was dispatched", got)
+			default:
+			}
+
+			// A message received while the queue is full is nacked right away.
+			handle()
+			select {
+			case got := <-acks:
+				if got {
+					t.Error("message received while the queue is full was acked, want nacked")
+				}
+			default:
+				t.Error("message received while the queue is full was not settled")
+			}
+
+			close(n.release)
+			for i := 0; i < 2; i++ {
+				select {
+				case got := <-acks:
+					if got != tc.wantAcked {
+						t.Errorf("queued message settled with ack = %t, want %t", got, tc.wantAcked)
+					}
+				case <-time.After(10 * time.Second):
+					t.Fatal("queued message was not settled before the timeout")
+				}
+			}
+		})
+	}
+}
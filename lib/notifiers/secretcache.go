@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultMaxCachedSecrets is how many secrets a CachingSecretGetter keeps if its MaxEntries is unset.
+const defaultMaxCachedSecrets = 100
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// CachingSecretGetter is a SecretGetter that serves repeated requests for the same secret resource name from memory
+// until TTL has passed since it was fetched from Getter. Failed fetches aren't cached. It's safe for concurrent use.
+type CachingSecretGetter struct {
+	Getter SecretGetter
+	TTL    time.Duration
+	// MaxEntries bounds how many secrets are cached. When the cache is full, the secret expiring soonest is evicted. It
+	// defaults to defaultMaxCachedSecrets if zero.
+	MaxEntries int
+	// Now returns the current time. It defaults to time.Now if nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+// getSecretGetter returns the given SecretGetter wrapped in a CachingSecretGetter if the `SECRET_CACHE_TTL` environment
+// variable is set to a positive duration, e.g. `5m`, and as-is otherwise.
+func getSecretGetter(sg SecretGetter) (SecretGetter, error) {
+	s, ok := GetEnv("SECRET_CACHE_TTL")
+	if !ok {
+		return sg, nil
+	}
+	ttl, err := time.ParseDuration(s)
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("expected SECRET_CACHE_TTL to be a positive duration, got %q", s)
+	}
+	return &CachingSecretGetter{Getter: sg, TTL: ttl}, nil
+}
+
+func (c *CachingSecretGetter) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// GetSecret implements SecretGetter. Concurrent requests for a secret that isn't cached each fetch it.
+func (c *CachingSecretGetter) GetSecret(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expires) {
+		return e.value, nil
+	}
+
+	value, err := c.Getter.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	c.put(name, value)
+	return value, nil
+}
+
+// put caches the secret's value, making room for it if the cache is full.
+func (c *CachingSecretGetter) put(name, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.entries == nil {
+		c.entries = map[string]cachedSecret{}
+	}
+	max := c.MaxEntries
+	if max <= 0 {
+		max = defaultMaxCachedSecrets
+	}
+	if _, ok := c.entries[name]; !ok && len(c.entries) >= max {
+		soonest := ""
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			} else if soonest == "" || e.expires.Before(c.entries[soonest].expires) {
+				soonest = k
+			}
+		}
+		if len(c.entries) >= max {
+			delete(c.entries, soonest)
+		}
+	}
+	c.entries[name] = cachedSecret{value: value, expires: now.Add(c.TTL)}
+}
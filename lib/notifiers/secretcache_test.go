@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// countingSecretGetter returns the current value of each secret and counts how many times each was fetched.
+type countingSecretGetter struct {
+	secrets map[string]string
+	calls   map[string]int
+}
+
+func (c *countingSecretGetter) GetSecret(_ context.Context, name string) (string, error) {
+	if c.calls == nil {
+		c.calls = map[string]int{}
+	}
+	c.calls[name]++
+	s, ok := c.secrets[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return s, nil
+}
+
+func TestCachingSecretGetter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sg := &countingSecretGetter{secrets: map[string]string{
+		"projects/p/secrets/a/versions/latest": "a1",
+		"projects/p/secrets/b/versions/latest": "b1",
+	}}
+	c := &CachingSecretGetter{Getter: sg, TTL: time.Minute, Now: func() time.Time { return now }}
+
+	get := func(name, want string) {
+		t.Helper()
+		got, err := c.GetSecret(ctx, name)
+		if err != nil {
+			t.Fatalf("GetSecret(%q) failed unexpectedly: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("GetSecret(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	// Miss, then a hit, even once the underlying secret changed.
+	get("projects/p/secrets/a/versions/latest", "a1")
+	sg.secrets["projects/p/secrets/a/versions/latest"] = "a2"
+	now = now.Add(59 * time.Second)
+	get("projects/p/secrets/a/versions/latest", "a1")
+
+	// A different resource isn't served the cached one.
+	get("projects/p/secrets/b/versions/latest", "b1")
+
+	// Expired.
+	now = now.Add(time.Second)
+	get("projects/p/secrets/a/versions/latest", "a2")
+
+	// Failures aren't cached.
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetSecret(ctx, "projects/p/secrets/missing/versions/latest"); err == nil {
+			t.Error("GetSecret of a missing secret succeeded unexpectedly")
+		}
+	}
+
+	want := map[string]int{
+		"projects/p/secrets/a/versions/latest":       2,
+		"projects/p/secrets/b/versions/latest":       1,
+		"projects/p/secrets/missing/versions/latest": 2,
+	}
+	if diff := cmp.Diff(want, sg.calls); diff != "" {
+		t.Errorf("unexpected fetches (-want +got):\n%s", diff)
+	}
+}
+
+func TestCachingSecretGetterBounded(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sg := &countingSecretGetter{secrets: map[string]string{"a": "a", "b": "b", "c": "c"}}
+	c := &CachingSecretGetter{Getter: sg, TTL: time.Minute, MaxEntries: 2, Now: func() time.Time { return now }}
+
+	for _, name := range []string{"a", "b", "c", "b", "c", "a"} {
+		if _, err := c.GetSecret(ctx, name); err != nil {
+			t.Fatalf("GetSecret(%q) failed unexpectedly: %v", name, err)
+		}
+		now = now.Add(time.Second)
+	}
+	if got := len(c.entries); got != 2 {
+		t.Errorf("got %d cached secrets, want 2", got)
+	}
+	// c evicted a, the soonest to expire, so only a is fetched again.
+	want := map[string]int{"a": 2, "b": 1, "c": 1}
+	if diff := cmp.Diff(want, sg.calls); diff != "" {
+		t.Errorf("unexpected fetches (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetSecretGetter(t *testing.T) {
+	sg := &countingSecretGetter{}
+	for _, tc := range []struct {
+		env     string
+		wantTTL time.Duration
+		wantErr bool
+	}{
+		{env: "", wantTTL: 0},
+		{env: "5m", wantTTL: 5 * time.Minute},
+		{env: "0s", wantErr: true},
+		{env: "soon", wantErr: true},
+	} {
+		t.Run(tc.env, func(t *testing.T) {
+			t.Setenv("SECRET_CACHE_TTL", tc.env)
+			got, err := getSecretGetter(sg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("getSecretGetter succeeded unexpectedly with SECRET_CACHE_TTL=%q", tc.env)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getSecretGetter failed unexpectedly: %v", err)
+			}
+			if tc.wantTTL == 0 {
+				if got != sg {
+					t.Errorf("getSecretGetter = %v, want the getter as-is", got)
+				}
+				return
+			}
+			c, ok := got.(*CachingSecretGetter)
+			if !ok || c.TTL != tc.wantTTL || c.Getter != sg {
+				t.Errorf("getSecretGetter = %+v, want a cache of the getter with TTL %v", got, tc.wantTTL)
+			}
+		})
+	}
+}
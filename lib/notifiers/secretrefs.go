@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaskedSecretRef is what templates render instead of substitution values that reference a secret.
+const MaskedSecretRef = "[SECRET]"
+
+// MaskSecretRefs replaces the view's build with a copy in which the values of substitutions that are Secret Manager
+// secret or secret version resource names are masked as MaskedSecretRef, so templates can't leak them by accident.
+// Templates that need a secret's value can resolve it deliberately with Secret, which uses the given SecretGetter.
+func (v *TemplateView) MaskSecretRefs(sg SecretGetter) {
+	v.sg = sg
+	if v.Build == nil || v.Build.Build == nil {
+		return
+	}
+	var masked *cbpb.Build
+	for k, s := range v.Build.GetSubstitutions() {
+		if !secretNamePattern.MatchString(s) {
+			continue
+		}
+		if masked == nil {
+			masked = proto.Clone(v.Build.Build).(*cbpb.Build)
+			v.secretRefs = map[string]string{}
+		}
+		masked.Substitutions[k] = MaskedSecretRef
+		v.secretRefs[k] = s
+	}
+	if masked != nil {
+		v.Build = &BuildView{Build: masked}
+	}
+}
+
+// Secret returns the value of the secret that the named substitution references, e.g.
+// `{{.Secret "_DEPLOY_TOKEN"}}`, for templates that deliberately render it. It fails for substitutions that
+// MaskSecretRefs didn't mask.
+func (v *TemplateView) Secret(substitution string) (string, error) {
+	ref, ok := v.secretRefs[substitution]
+	if !ok {
+		return "", fmt.Errorf("substitution %q doesn't reference a secret", substitution)
+	}
+	if v.sg == nil {
+		return "", fmt.Errorf("can't resolve the secret of substitution %q: the notifier has no SecretGetter", substitution)
+	}
+	s, err := v.sg.GetSecret(context.Background(), ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the secret of substitution %q: %w", substitution, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+const tokenRef = "projects/some-project/secrets/deploy-token/versions/3"
+
+func secretRefView() (*TemplateView, *cbpb.Build) {
+	build := &cbpb.Build{
+		Id: "some-build-id",
+		Substitutions: map[string]string{
+			"_DEPLOY_TOKEN": tokenRef,
+			"_API_KEY":      "projects/some-project/secrets/api-key",
+			"_ENV":          "projects/some-project/staging",
+		},
+	}
+	return &TemplateView{Build: &BuildView{Build: build}}, build
+}
+
+func render(t *testing.T, text string, v *TemplateView) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	err := template.Must(template.New("").Parse(text)).Execute(&buf, v)
+	return buf.String(), err
+}
+
+func TestMaskSecretRefs(t *testing.T) {
+	v, build := secretRefView()
+	v.MaskSecretRefs(&fakeSecretGetter{})
+
+	got, err := render(t, `{{.Build.Substitutions._DEPLOY_TOKEN}} {{.Build.Substitutions._API_KEY}} {{.Build.Substitutions._ENV}} {{index .BuildJSON.substitutions "_DEPLOY_TOKEN"}}`, v)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if want := "[SECRET] [SECRET] projects/some-project/staging [SECRET]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if build.Substitutions["_DEPLOY_TOKEN"] != tokenRef {
+		t.Errorf("MaskSecretRefs changed the original build's substitution to %q", build.Substitutions["_DEPLOY_TOKEN"])
+	}
+}
+
+func TestTemplateViewSecret(t *testing.T) {
+	v, _ := secretRefView()
+	v.MaskSecretRefs(&fakeSecretGetter{secrets: map[string]string{tokenRef: "s3cr3t"}})
+
+	got, err := render(t, `{{.Secret "_DEPLOY_TOKEN"}}`, v)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got != "s3cr3t" {
+		t.Errorf("got %q, want %q", got, "s3cr3t")
+	}
+
+	for _, name := range []string{
+		// Not a secret ref.
+		"_ENV",
+		// Not a substitution.
+		"_MISSING",
+		// Not in the SecretGetter.
+		"_API_KEY",
+	} {
+		if got, err := render(t, `{{.Secret "`+name+`"}}`, v); err == nil {
+			t.Errorf("Secret(%q) = %q, want error", name, got)
+		}
+	}
+}
+
+func TestTemplateViewSecretUnmasked(t *testing.T) {
+	v, _ := secretRefView()
+	if got, err := render(t, `{{.Secret "_DEPLOY_TOKEN"}}`, v); err == nil {
+		t.Errorf("Secret without MaskSecretRefs = %q, want error", got)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+)
+
+// StateStoreField is the delivery config field that GetStateStore reads the state store location from.
+const StateStoreField = "stateStore"
+
+// StateStore keeps small pieces of notifier state, such as suppression windows and last-seen build statuses, by key.
+// Every value expires after the TTL it was stored with. Implementations must be safe for concurrent use.
+type StateStore interface {
+	// Get returns the key's unexpired value, and false if it has none.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Put stores the value for the key.
+	Put(ctx context.Context, key, value string, ttl time.Duration) error
+	// PutIfAbsent stores the value for the key unless it has an unexpired value, and returns true iff it stored it.
+	PutIfAbsent(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Swap stores the value for the key and returns the key's previous unexpired value, if any.
+	Swap(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error)
+}
+
+// GetStateStore returns the state store configured in the `stateStore` delivery config field, a `gs://bucket[/prefix]`
+// path, or, if it's unset, a new in-memory store. In-memory state is lost when the instance is recycled and isn't shared
+// between instances.
+func GetStateStore(ctx context.Context, cfg *Config) (StateStore, error) {
+	v, ok := cfg.Spec.Notification.Delivery[StateStoreField]
+	if !ok {
+		return new(MemoryStateStore), nil
+	}
+	s, _ := v.(string)
+	path := strings.TrimPrefix(s, "gs://")
+	if path == s || path == "" {
+		return nil, fmt.Errorf("expected delivery config field %q to be a gs://bucket[/prefix] path, got %v", StateStoreField, v)
+	}
+	split := strings.SplitN(path, "/", 2)
+	prefix := ""
+	if len(split) == 2 && split[1] != "" {
+		prefix = strings.TrimSuffix(split[1], "/") + "/"
+	}
+	if Validating(ctx) {
+		return new(MemoryStateStore), nil
+	}
+	sc, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new GCS client: %w", err)
+	}
+	return &GCSStateStore{Bucket: sc.Bucket(split[0]), Prefix: prefix}, nil
+}
+
+type memoryEntry struct {
+	value   string
+	expires time.Time
+}
+
+// MemoryStateStore is an in-process StateStore. The zero value is ready to use.
+type MemoryStateStore struct {
+	// Now returns the current time. It defaults to time.Now if nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func (m *MemoryStateStore) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// get returns the key's unexpired value. It must be called with m.mu held.
+func (m *MemoryStateStore) get(key string, now time.Time) (string, bool) {
+	e, ok := m.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return "", false
+	}
+	return e.value, true
+}
+
+// put stores the value for the key and drops expired entries. It must be called with m.mu held.
+func (m *MemoryStateStore) put(key, value string, ttl time.Duration, now time.Time) {
+	if m.entries == nil {
+		m.entries = map[string]memoryEntry{}
+	}
+	for k, e := range m.entries {
+		if !now.Before(e.expires) {
+			delete(m.entries, k)
+		}
+	}
+	m.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+}
+
+// Get implements StateStore.
+func (m *MemoryStateStore) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.get(key, m.now())
+	return v, ok, nil
+}
+
+// Put implements StateStore.
+func (m *MemoryStateStore) Put(_ context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(key, value, ttl, m.now())
+	return nil
+}
+
+// PutIfAbsent implements StateStore.
+func (m *MemoryStateStore) PutIfAbsent(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if _, ok := m.get(key, now); ok {
+		return false, nil
+	}
+	m.put(key, value, ttl, now)
+	return true, nil
+}
+
+// Swap implements StateStore.
+func (m *MemoryStateStore) Swap(_ context.Context, key, value string, ttl time.Duration) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	prev, ok := m.get(key, now)
+	m.put(key, value, ttl, now)
+	return prev, ok, nil
+}
+
+// maxSwapAttempts is how many times GCSStateStore.Swap tries to win a race with concurrent writers.
+const maxSwapAttempts = 5
+
+// GCSStateStore is a StateStore keeping each key in a GCS object, so that state survives instance restarts and is shared
+// between instances. Conditional writes keep PutIfAbsent and Swap atomic across instances. Expired objects are ignored
+// but not deleted; use a lifecycle rule on the bucket to delete them.
+type GCSStateStore struct {
+	Bucket *storage.BucketHandle
+	// Prefix is prepended to keys to make object names.
+	Prefix string
+}
+
+// read returns the object's unexpired value and its generation, which is 0 if the object doesn't exist.
+func (g *GCSStateStore) read(ctx context.Context, key string) (value string, ok bool, gen int64, err error) {
+	r, err := g.Bucket.Object(g.Prefix + key).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return "", false, 0, nil
+	}
+	if err != nil {
+		return "", false, 0, fmt.Errorf("failed to read state %q: %w", key, err)
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", false, 0, fmt.Errorf("failed to read state %q: %w", key, err)
+	}
+	// Objects hold the value's expiry, in Unix nanoseconds, and the value, separated by a newline.
+	split := strings.SplitN(string(b), "\n", 2)
+	expires, perr := strconv.ParseInt(split[0], 10, 64)
+	if len(split) != 2 || perr != nil || time.Now().UnixNano() >= expires {
+		return "", false, r.Attrs.Generation, nil
+	}
+	return split[1], true, r.Attrs.Generation, nil
+}
+
+// write stores the value, optionally only if the object is still at the given generation (0 meaning it doesn't exist).
+// It returns false if the condition doesn't hold.
+func (g *GCSStateStore) write(ctx context.Context, key, value string, ttl time.Duration, conditional bool, gen int64) (bool, error) {
+	obj := g.Bucket.Object(g.Prefix + key)
+	if conditional {
+		if gen == 0 {
+			obj = obj.If(storage.Conditions{DoesNotExist: true})
+		} else {
+			obj = obj.If(storage.Conditions{GenerationMatch: gen})
+		}
+	}
+	w := obj.NewWriter(ctx)
+	fmt.Fprintf(w, "%d\n%s", time.Now().Add(ttl).UnixNano(), value)
+	if err := w.Close(); err != nil {
+		var ge *googleapi.Error
+		if conditional && errors.As(err, &ge) && ge.Code == http.StatusPreconditionFailed {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to write state %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Get implements StateStore.
+func (g *GCSStateStore) Get(ctx context.Context, key string) (string, bool, error) {
+	v, ok, _, err := g.read(ctx, key)
+	return v, ok, err
+}
+
+// Put implements StateStore.
+func (g *GCSStateStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	_, err := g.write(ctx, key, value, ttl, false, 0)
+	return err
+}
+
+// PutIfAbsent implements StateStore.
+func (g *GCSStateStore) PutIfAbsent(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	_, ok, gen, err := g.read(ctx, key)
+	if err != nil || ok {
+		return false, err
+	}
+	// If another writer got there first, its value is unexpired.
+	return g.write(ctx, key, value, ttl, true, gen)
+}
+
+// Swap implements StateStore.
+func (g *GCSStateStore) Swap(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error) {
+	for i := 0; i < maxSwapAttempts; i++ {
+		prev, ok, gen, err := g.read(ctx, key)
+		if err != nil {
+			return "", false, err
+		}
+		written, err := g.write(ctx, key, value, ttl, true, gen)
+		if err != nil {
+			return "", false, err
+		}
+		if written {
+			return prev, ok, nil
+		}
+	}
+	return "", false, fmt.Errorf("failed to swap state %q: lost %d races with concurrent writers", key, maxSwapAttempts)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStateStore(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	m := &MemoryStateStore{Now: func() time.Time { return now }}
+
+	if _, ok, err := m.Get(ctx, "a"); err != nil || ok {
+		t.Fatalf("Get(unset) = _, %v, %v, want false, nil", ok, err)
+	}
+	if ok, err := m.PutIfAbsent(ctx, "a", "1", time.Minute); err != nil || !ok {
+		t.Fatalf("PutIfAbsent(unset) = %v, %v, want true, nil", ok, err)
+	}
+	if ok, err := m.PutIfAbsent(ctx, "a", "2", time.Minute); err != nil || ok {
+		t.Fatalf("PutIfAbsent(set) = %v, %v, want false, nil", ok, err)
+	}
+	if prev, ok, err := m.Swap(ctx, "a", "3", time.Minute); err != nil || !ok || prev != "1" {
+		t.Fatalf("Swap(set) = %q, %v, %v, want %q, true, nil", prev, ok, err, "1")
+	}
+	if v, ok, err := m.Get(ctx, "a"); err != nil || !ok || v != "3" {
+		t.Fatalf("Get(set) = %q, %v, %v, want %q, true, nil", v, ok, err, "3")
+	}
+
+	now = start.Add(time.Minute)
+	if _, ok, err := m.Get(ctx, "a"); err != nil || ok {
+		t.Fatalf("Get(expired) = _, %v, %v, want false, nil", ok, err)
+	}
+	if prev, ok, err := m.Swap(ctx, "a", "4", time.Minute); err != nil || ok {
+		t.Fatalf("Swap(expired) = %q, %v, %v, want false, nil", prev, ok, err)
+	}
+	if err := m.Put(ctx, "a", "5", time.Minute); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if v, _, _ := m.Get(ctx, "a"); v != "5" {
+		t.Errorf("Get after Put = %q, want %q", v, "5")
+	}
+}
+
+func TestGetStateStore(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		delivery map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "default", delivery: map[string]interface{}{}},
+		{name: "not a gs path", delivery: map[string]interface{}{StateStoreField: "bucket/prefix"}, wantErr: true},
+		{name: "not a string", delivery: map[string]interface{}{StateStoreField: 3}, wantErr: true},
+		{name: "no bucket", delivery: map[string]interface{}{StateStoreField: "gs://"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Spec: &Spec{Notification: &Notification{Delivery: tc.delivery}}}
+			s, err := GetStateStore(context.Background(), cfg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("GetStateStore succeeded unexpectedly: %v", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetStateStore failed: %v", err)
+			}
+			if _, ok := s.(*MemoryStateStore); !ok {
+				t.Errorf("GetStateStore = %T, want *MemoryStateStore", s)
+			}
+		})
+	}
+}
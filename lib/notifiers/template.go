@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	log "github.com/golang/glog"
+)
+
+// execErrorPattern matches the message of a text/template execution error, e.g.
+// `template: issue:2:15: executing "issue" at <.Build.Foo>: can't evaluate field Foo in type ...`.
+var execErrorPattern = regexp.MustCompile(`^template: [^:]*:(\d+):(\d+): executing "[^"]*" at <(.*?)>: (.*)$`)
+
+// TemplateError is returned by ExecuteTemplate when a template fails to render.
+type TemplateError struct {
+	// Name is the name of the template that failed.
+	Name string
+	// Line and Col locate the failing action in the template text. They are 0 if unknown.
+	Line, Col int
+	// Action is the failing action, e.g. `.Build.Foo`. It is empty if unknown.
+	Action string
+	// Reason describes why the action failed, e.g. "can't evaluate field Foo in type ...".
+	Reason string
+	// Err is the underlying text/template error.
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Action == "" {
+		return fmt.Sprintf("failed to execute template %q: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("failed to execute template %q at line %d, column %d, action {{%s}}: %s", e.Name, e.Line, e.Col, e.Action, e.Reason)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteTemplate executes t with the given data, like t.Execute. On failure, it logs which action of the template
+// failed and why and returns a *TemplateError.
+func ExecuteTemplate(t *template.Template, w io.Writer, data interface{}) error {
+	err := t.Execute(w, data)
+	if err == nil {
+		return nil
+	}
+
+	te := &TemplateError{Name: t.Name(), Reason: err.Error(), Err: err}
+	var ee template.ExecError
+	if errors.As(err, &ee) {
+		te.Name = ee.Name
+	}
+	if m := execErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		te.Line, _ = strconv.Atoi(m[1])
+		te.Col, _ = strconv.Atoi(m[2])
+		te.Action, te.Reason = m[3], m[4]
+	}
+	log.Warningf("template %q failed to render: %v", te.Name, te)
+	return te
+}
+
+// TemplateFuncs returns the helper functions available to all notifier templates:
+//   - `replace`: `{{replace .Build.Id "-" "_"}}` replaces all occurrences of a substring.
+//   - `json`: `{{json .Build.Substitutions.COMMIT_MESSAGE}}` renders any value as JSON, e.g. a string as a quoted and
+//     escaped JSON string, so it can be safely interpolated into JSON payloads.
+//   - `compareUrl`: `{{compareUrl .Build}}` renders the GitHub URL of the build's diff (see CompareURL).
+//   - `stripAnsi`: `{{stripAnsi .Build.Substitutions._OUTPUT}}` removes ANSI escape sequences (see StripANSI).
+//
+// The map is usable with both text/template and html/template.
+func TemplateFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"replace": func(s, old, new string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"json":       toJSON,
+		"compareUrl": CompareURL,
+		"stripAnsi":  StripANSI,
+	}
+}
+
+// PreviousCommitSubstitution is the substitution that CompareURL reads the commit preceding the build's COMMIT_SHA from,
+// e.g. set by a webhook trigger to `$(body.before)` of a GitHub push event.
+const PreviousCommitSubstitution = "_PREVIOUS_COMMIT_SHA"
+
+// defaultGitHubURL is the web URL of github.com, which builds link to unless their source is on GitHub Enterprise.
+const defaultGitHubURL = "https://github.com"
+
+// CompareURL returns the GitHub URL comparing the build's previous commit (per PreviousCommitSubstitution) to its
+// COMMIT_SHA in its REPO_FULL_NAME repo, or, if the build has no previous commit, the URL of its COMMIT_SHA commit. The
+// URL is on the host of the build's repo URL, so builds of GitHub Enterprise repos link there. It returns "" if the
+// build has no REPO_FULL_NAME or COMMIT_SHA.
+func CompareURL(build *BuildView) string {
+	if build == nil || build.Build == nil {
+		return ""
+	}
+	repo, sha := build.Substitutions["REPO_FULL_NAME"], build.Substitutions["COMMIT_SHA"]
+	if repo == "" || sha == "" {
+		return ""
+	}
+	base := gitHubURL(build.Build) + "/" + repo
+	// Pushes that create a branch have an all-zero previous commit.
+	if prev := build.Substitutions[PreviousCommitSubstitution]; strings.Trim(prev, "0") != "" && prev != sha {
+		return base + "/compare/" + url.PathEscape(prev) + "..." + url.PathEscape(sha)
+	}
+	return base + "/commit/" + url.PathEscape(sha)
+}
+
+// gitHubURL returns the scheme and host of the web URL of the build's repo, per its _HEAD_REPO_URL substitution or Git
+// source, or of github.com if it has neither.
+func gitHubURL(build *cbpb.Build) string {
+	for _, raw := range []string{build.Substitutions["_HEAD_REPO_URL"], build.GetSource().GetGitSource().GetUrl()} {
+		if raw == "" {
+			continue
+		}
+		// scp-like remotes, e.g. git@github.example.com:owner/repo.git, are served over HTTPS on the same host.
+		if !strings.Contains(raw, "://") {
+			if i := strings.Index(raw, ":"); i >= 0 {
+				raw = "https://" + raw[strings.LastIndex(raw[:i], "@")+1:i]
+			}
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			// Git's own ports, e.g. SSH's, don't serve the web UI.
+			return "https://" + u.Hostname()
+		}
+		return u.Scheme + "://" + u.Host
+	}
+	return defaultGitHubURL
+}
+
+// toJSON returns the JSON encoding of v. Unlike json.Marshal, it doesn't escape HTML characters like `<`, which are
+// valid in JSON strings.
+func toJSON(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", fmt.Errorf("failed to encode %v as JSON: %w", v, err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
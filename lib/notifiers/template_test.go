@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	view := &TemplateView{Build: &BuildView{Build: &cbpb.Build{Id: "some-build"}}}
+
+	for _, tc := range []struct {
+		name       string
+		text       string
+		want       string
+		wantErr    *TemplateError
+		wantSubstr string
+	}{{
+		name: "renders",
+		text: `{"id": "{{.Build.Id}}"}`,
+		want: `{"id": "some-build"}`,
+	}, {
+		name: "missing field",
+		text: "{\n  \"title\": \"{{.Build.Nope}}\"\n}",
+		wantErr: &TemplateError{
+			Name:   "issue",
+			Line:   2,
+			Col:    20,
+			Action: ".Build.Nope",
+		},
+		wantSubstr: "can't evaluate field Nope",
+	}, {
+		name: "missing nested field",
+		text: `{{.Build.Id}} {{.Build.Status.Foo}}`,
+		wantErr: &TemplateError{
+			Name:   "issue",
+			Line:   1,
+			Col:    22,
+			Action: ".Build.Status.Foo",
+		},
+		wantSubstr: "can't evaluate field Foo",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("issue").Parse(tc.text))
+			var buf bytes.Buffer
+			err := ExecuteTemplate(tmpl, &buf, view)
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("ExecuteTemplate got unexpected error: %v", err)
+				}
+				if buf.String() != tc.want {
+					t.Errorf("ExecuteTemplate rendered %q, want %q", buf.String(), tc.want)
+				}
+				return
+			}
+
+			var te *TemplateError
+			if !errors.As(err, &te) {
+				t.Fatalf("ExecuteTemplate got error %v (%T), want a *TemplateError", err, err)
+			}
+			if te.Name != tc.wantErr.Name || te.Line != tc.wantErr.Line || te.Col != tc.wantErr.Col || te.Action != tc.wantErr.Action {
+				t.Errorf("got TemplateError{Name: %q, Line: %d, Col: %d, Action: %q}, want {%q, %d, %d, %q}",
+					te.Name, te.Line, te.Col, te.Action, tc.wantErr.Name, tc.wantErr.Line, tc.wantErr.Col, tc.wantErr.Action)
+			}
+			if !strings.Contains(te.Reason, tc.wantSubstr) {
+				t.Errorf("got Reason %q, want it to contain %q", te.Reason, tc.wantSubstr)
+			}
+			if !strings.Contains(err.Error(), "{{"+tc.wantErr.Action+"}}") {
+				t.Errorf("got error %q, want it to quote the action", err)
+			}
+			var ee template.ExecError
+			if !errors.As(err, &ee) {
+				t.Errorf("got error %v, want it to wrap a template.ExecError", err)
+			}
+		})
+	}
+}
+
+func TestJSONTemplateFunc(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data interface{}
+		want string
+	}{{
+		name: "plain string",
+		data: "fix the build",
+		want: `{"msg": "fix the build"}`,
+	}, {
+		name: "quotes and backslashes",
+		data: `say "hi" from C:\tmp`,
+		want: `{"msg": "say \"hi\" from C:\\tmp"}`,
+	}, {
+		name: "control characters",
+		data: "first line\nsecond\tline\r\x01",
+		want: `{"msg": "first line\nsecond\tline\r\u0001"}`,
+	}, {
+		name: "html characters are kept",
+		data: "<b>a & b</b>",
+		want: `{"msg": "<b>a & b</b>"}`,
+	}, {
+		name: "unicode",
+		data: "héllo 🚀 \u2028",
+		want: "{\"msg\": \"héllo 🚀 \\u2028\"}",
+	}, {
+		name: "nil",
+		data: nil,
+		want: `{"msg": null}`,
+	}, {
+		name: "number",
+		data: 42,
+		want: `{"msg": 42}`,
+	}, {
+		name: "list",
+		data: []string{"a", `"b"`},
+		want: `{"msg": ["a","\"b\""]}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("json").Funcs(TemplateFuncs()).Parse(`{"msg": {{json .}}}`))
+			var buf bytes.Buffer
+			if err := ExecuteTemplate(tmpl, &buf, tc.data); err != nil {
+				t.Fatalf("ExecuteTemplate got unexpected error: %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("rendered %s, want %s", buf.String(), tc.want)
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Errorf("rendered %s, want valid JSON", buf.String())
+			}
+		})
+	}
+}
+
+func TestJSONTemplateFuncError(t *testing.T) {
+	tmpl := template.Must(template.New("json").Funcs(TemplateFuncs()).Parse(`{{json .}}`))
+	if err := ExecuteTemplate(tmpl, new(bytes.Buffer), make(chan int)); err == nil {
+		t.Error("ExecuteTemplate succeeded for an unencodable value, want an error")
+	}
+}
+
+func TestCompareURLTemplateFunc(t *testing.T) {
+	const sha = "3f1c0de"
+	for _, tc := range []struct {
+		name   string
+		subs   map[string]string
+		source *cbpb.Source
+		want   string
+	}{{
+		name: "compare",
+		subs: map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha, PreviousCommitSubstitution: "a1b2c3d"},
+		want: "https://github.com/owner/repo/compare/a1b2c3d...3f1c0de",
+	}, {
+		name: "no previous commit",
+		subs: map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha},
+		want: "https://github.com/owner/repo/commit/3f1c0de",
+	}, {
+		name: "new branch",
+		subs: map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha, PreviousCommitSubstitution: "0000000000000000000000000000000000000000"},
+		want: "https://github.com/owner/repo/commit/3f1c0de",
+	}, {
+		name: "previous commit is the commit",
+		subs: map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha, PreviousCommitSubstitution: sha},
+		want: "https://github.com/owner/repo/commit/3f1c0de",
+	}, {
+		name: "enterprise head repo",
+		subs: map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha, "_HEAD_REPO_URL": "https://github.example.com/owner/repo"},
+		want: "https://github.example.com/owner/repo/commit/3f1c0de",
+	}, {
+		name:   "enterprise git source",
+		subs:   map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha, PreviousCommitSubstitution: "a1b2c3d"},
+		source: &cbpb.Source{Source: &cbpb.Source_GitSource{GitSource: &cbpb.GitSource{Url: "https://github.example.com:8443/owner/repo.git"}}},
+		want:   "https://github.example.com:8443/owner/repo/compare/a1b2c3d...3f1c0de",
+	}, {
+		name:   "enterprise scp-like remote",
+		subs:   map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha},
+		source: &cbpb.Source{Source: &cbpb.Source_GitSource{GitSource: &cbpb.GitSource{Url: "git@github.example.com:owner/repo.git"}}},
+		want:   "https://github.example.com/owner/repo/commit/3f1c0de",
+	}, {
+		name:   "enterprise ssh remote",
+		subs:   map[string]string{"REPO_FULL_NAME": "owner/repo", "COMMIT_SHA": sha},
+		source: &cbpb.Source{Source: &cbpb.Source_GitSource{GitSource: &cbpb.GitSource{Url: "ssh://git@github.example.com:22/owner/repo.git"}}},
+		want:   "https://github.example.com/owner/repo/commit/3f1c0de",
+	}, {
+		name: "no repo",
+		subs: map[string]string{"COMMIT_SHA": sha},
+		want: "",
+	}, {
+		name: "no commit",
+		subs: map[string]string{"REPO_FULL_NAME": "owner/repo"},
+		want: "",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("compare").Funcs(TemplateFuncs()).Parse(`{{compareUrl .Build}}`))
+			view := &TemplateView{Build: &BuildView{Build: &cbpb.Build{Substitutions: tc.subs, Source: tc.source}}}
+			var buf bytes.Buffer
+			if err := ExecuteTemplate(tmpl, &buf, view); err != nil {
+				t.Fatalf("ExecuteTemplate got unexpected error: %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("rendered %q, want %q", buf.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	log "github.com/golang/glog"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+
+// setUpTracing installs a global OpenTelemetry TracerProvider that exports spans via OTLP iff the
+// `OTEL_EXPORTER_OTLP_ENDPOINT` environment variable is set. Otherwise, the global no-op provider is left in place.
+// The exporter honors the standard `OTEL_EXPORTER_OTLP_*` environment variables.
+// The returned function flushes and stops the exporter and is always non-nil.
+func setUpTracing(ctx context.Context) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if _, ok := GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT"); !ok {
+		return noop, nil
+	}
+
+	exp, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		// Default honors `OTEL_SERVICE_NAME` and `OTEL_RESOURCE_ATTRIBUTES`.
+		sdktrace.WithResource(resource.Default()),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	log.V(2).Infoln("exporting OpenTelemetry traces via OTLP")
+
+	return tp.Shutdown, nil
+}
+
+// tracer returns the Tracer used for notifier spans from the current global TracerProvider.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// NewTracingHTTPClient returns an HTTP client whose requests are recorded as child spans of the span in their
+// request context. When tracing is not enabled, it behaves like http.DefaultClient.
+func NewTracingHTTPClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// outboundNotifier makes a single outbound HTTP call to url from SendNotification.
+type outboundNotifier struct {
+	url string
+}
+
+func (o *outboundNotifier) SetUp(_ context.Context, _ *Config, _ string, _ SecretGetter, _ BindingResolver) error {
+	return nil
+}
+
+func (o *outboundNotifier) SendNotification(ctx context.Context, _ *cbpb.Build) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := NewTracingHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestReceiverSpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	orig := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(orig)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	build := &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE}
+	data, err := protojson.Marshal(protoadapt.MessageV2Of(build))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := json.Marshal(&pubSubPushWrapper{Message: pubSubPushMessage{Data: data, ID: "id-do-not-care"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := newReceiver(&outboundNotifier{url: srv.URL}, &receiverParams{name: "my-notifier"})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "http://notifer.example.com/", bytes.NewBuffer(j)))
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Fatalf("result.StatusCode = %d, expected %d", s, http.StatusOK)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range sr.Ended() {
+		spans[s.Name()] = s
+	}
+	recv, ok := spans["notifiers.Receive"]
+	if !ok {
+		t.Fatalf("missing receive span; got spans %v", spans)
+	}
+	send, ok := spans["notifiers.SendNotification"]
+	if !ok {
+		t.Fatalf("missing SendNotification span; got spans %v", spans)
+	}
+	out, ok := spans["HTTP POST"]
+	if !ok {
+		t.Fatalf("missing outbound HTTP span; got spans %v", spans)
+	}
+
+	if got, want := send.Parent().SpanID(), recv.SpanContext().SpanID(); got != want {
+		t.Errorf("SendNotification span parent = %v, want receive span %v", got, want)
+	}
+	if got, want := out.Parent().SpanID(), send.SpanContext().SpanID(); got != want {
+		t.Errorf("outbound HTTP span parent = %v, want SendNotification span %v", got, want)
+	}
+
+	attrs := map[attribute.Key]string{}
+	for _, kv := range recv.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	for k, want := range map[attribute.Key]string{
+		"build.id":      "some-build-id",
+		"build.status":  "FAILURE",
+		"notifier.type": "*notifiers.outboundNotifier",
+		"notifier.name": "my-notifier",
+	} {
+		if attrs[k] != want {
+			t.Errorf("receive span attribute %q = %q, want %q", k, attrs[k], want)
+		}
+	}
+}
+
+func TestSetUpTracingDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	shutdown, err := setUpTracing(context.Background())
+	if err != nil {
+		t.Fatalf("setUpTracing() got unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() got unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// ValidationErrors lists the problems that the `--validate` mode found in a config, in the order they were found.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, "- "+err.Error())
+	}
+	return fmt.Sprintf("found %d problem(s):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+type validationKey struct{}
+
+// WithValidation returns a context for a SetUp that only validates its config, as in the `--validate` mode (see
+// Validating).
+func WithValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, validationKey{}, true)
+}
+
+// Validating returns true iff ctx is that of a SetUp that only validates its config. The secret values that SetUp gets
+// are then placeholders, so it mustn't parse them, and it mustn't create clients of other services, which may lack
+// credentials. The helpers of this package that would (e.g. GetStateStore) check their fields and return stand-ins.
+func Validating(ctx context.Context) bool {
+	v, _ := ctx.Value(validationKey{}).(bool)
+	return v
+}
+
+// validateOffline decodes the YAML config from r and validates it without sending anything or accessing secrets. The
+// template is read from templateFile if it's non-empty, which is required for configs whose template has a URI. The
+// notifier's own SetUp checks (e.g. of required delivery fields) only run once the common checks pass, since they
+// would repeat their problems. It returns ValidationErrors listing every problem found.
+func validateOffline(ctx context.Context, notifier Notifier, r io.Reader, templateFile string) error {
+	cfg, err := decodeConfig(r)
+	if err != nil {
+		return ValidationErrors{fmt.Errorf("failed to decode YAML config: %w", err)}
+	}
+	if err := validateConfig(cfg); err != nil {
+		return ValidationErrors{err}
+	}
+
+	var errs ValidationErrors
+	if _, err := MakeCELPredicate(cfg.Spec.Notification.Filter); err != nil {
+		errs = append(errs, err)
+	}
+
+	tmpl, err := offlineTemplate(cfg.Spec.Notification.Template, templateFile)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	br, err := newResolver(cfg)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid params: %w", err))
+	}
+
+	errs = append(errs, validateSecrets(cfg)...)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if err := notifier.SetUp(WithValidation(ctx), cfg, tmpl, new(setupCheckSecretGetter), br); err != nil {
+		return ValidationErrors{fmt.Errorf("notifier rejected config: %w", err)}
+	}
+	return nil
+}
+
+// offlineTemplate returns the config's template, read from templateFile if it's non-empty, and checks that it parses.
+func offlineTemplate(tmpl *Template, templateFile string) (string, error) {
+	var s string
+	switch {
+	case templateFile != "":
+		b, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template file: %w", err)
+		}
+		s = string(b)
+	case tmpl == nil:
+		return "", nil
+	case tmpl.URI != "":
+		return "", fmt.Errorf("template URI %q can't be read offline, pass its contents with --template_file", tmpl.URI)
+	default:
+		s = tmpl.Content
+	}
+	if tmpl != nil && !allowedTemplateTypes[tmpl.Type] {
+		return "", fmt.Errorf("got invalid Template Type: %v", tmpl.Type)
+	}
+	if err := validateTemplate(s); err != nil {
+		return "", fmt.Errorf("got invalid template: %w", err)
+	}
+	return s, nil
+}
+
+// validateSecrets checks that the config's secrets are well-formed Secret Manager resource names with unique local
+// names, and that every `secretRef` in the delivery config, however deeply nested, names one of them.
+func validateSecrets(cfg *Config) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, s := range cfg.Spec.Secrets {
+		if seen[s.LocalName] {
+			errs = append(errs, fmt.Errorf("secret name %q is used more than once", s.LocalName))
+		}
+		seen[s.LocalName] = true
+		if _, err := secretVersionName(s.ResourceName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, ref := range secretRefs(cfg.Spec.Notification.Delivery) {
+		if _, err := FindSecretResourceName(cfg.Spec.Secrets, ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// secretRefs returns the `secretRef` values found anywhere in v, a decoded YAML value, in sorted order.
+func secretRefs(v interface{}) []string {
+	var refs []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, e := range v {
+			refs = append(refs, secretRefs(e)...)
+		}
+	case map[interface{}]interface{}:
+		if ref, ok := v[secretRef].(string); ok {
+			refs = append(refs, ref)
+		}
+		for k, e := range v {
+			if k != secretRef {
+				refs = append(refs, secretRefs(e)...)
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			refs = append(refs, secretRefs(e)...)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
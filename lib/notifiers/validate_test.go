@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+// setUpNotifier is a notifier whose SetUp records its arguments and returns err.
+type setUpNotifier struct {
+	err        error
+	tmpl       string
+	secret     string
+	validating bool
+}
+
+func (s *setUpNotifier) SetUp(ctx context.Context, cfg *Config, tmpl string, sg SecretGetter, _ BindingResolver) error {
+	s.tmpl = tmpl
+	s.validating = Validating(ctx)
+	ref, err := GetSecretRef(cfg.Spec.Notification.Delivery, "token")
+	if err != nil {
+		return err
+	}
+	resource, err := FindSecretResourceName(cfg.Spec.Secrets, ref)
+	if err != nil {
+		return err
+	}
+	if s.secret, err = sg.GetSecret(ctx, resource); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func (s *setUpNotifier) SendNotification(context.Context, *cbpb.Build) error {
+	return errors.New("unexpected notification")
+}
+
+const offlineConfig = `
+apiVersion: cloud-build-notifiers/v1
+kind: TestNotifier
+spec:
+  notification:
+    filter: build.status == Build.Status.FAILURE
+    delivery:
+      token:
+        secretRef: token
+    params:
+      buildId: $(build.id)
+    template:
+      type: golang
+      content: '{{.Build.Id}}'
+  secrets:
+  - name: token
+    value: projects/my-project/secrets/token/versions/latest
+`
+
+func TestValidateOffline(t *testing.T) {
+	n := new(setUpNotifier)
+	if err := validateOffline(context.Background(), n, strings.NewReader(offlineConfig), ""); err != nil {
+		t.Fatalf("validateOffline failed: %v", err)
+	}
+	if n.tmpl != "{{.Build.Id}}" {
+		t.Errorf("SetUp got template %q, want %q", n.tmpl, "{{.Build.Id}}")
+	}
+	if !strings.HasPrefix(n.secret, "[SECRET VALUE FOR") {
+		t.Errorf("SetUp got secret %q, want a placeholder", n.secret)
+	}
+}
+
+func TestValidateOfflineValidating(t *testing.T) {
+	n := new(setUpNotifier)
+	if err := validateOffline(context.Background(), n, strings.NewReader(offlineConfig), ""); err != nil {
+		t.Fatalf("validateOffline failed: %v", err)
+	}
+	if !n.validating {
+		t.Error("SetUp got a context that isn't validating")
+	}
+	if Validating(context.Background()) {
+		t.Error("Validating(context.Background()) = true, want false")
+	}
+}
+
+func TestValidatingStandIns(t *testing.T) {
+	ctx := WithValidation(context.Background())
+	cfg := func(field string, v interface{}) *Config {
+		return &Config{Spec: &Spec{Notification: &Notification{Delivery: map[string]interface{}{field: v}}}}
+	}
+
+	ss, err := GetStateStore(ctx, cfg(StateStoreField, "gs://some-bucket/some/prefix"))
+	if err != nil {
+		t.Fatalf("GetStateStore failed: %v", err)
+	}
+	if _, ok := ss.(*MemoryStateStore); !ok {
+		t.Errorf("GetStateStore got %T, want a *MemoryStateStore", ss)
+	}
+	if _, err := GetStateStore(ctx, cfg(StateStoreField, "some-bucket")); err == nil {
+		t.Error("GetStateStore got no error for a path without gs://")
+	}
+
+	al, err := GetArtifactLinker(ctx, cfg(ArtifactURLTTLField, "1h"))
+	if err != nil {
+		t.Fatalf("GetArtifactLinker failed: %v", err)
+	}
+	if al.TTL != time.Hour {
+		t.Errorf("GetArtifactLinker got TTL %v, want %v", al.TTL, time.Hour)
+	}
+	if _, err := GetArtifactLinker(ctx, cfg(ArtifactURLTTLField, "forever")); err == nil {
+		t.Error("GetArtifactLinker got no error for an invalid TTL")
+	}
+
+	if _, err := GetIdentities(ctx, cfg(IdentitiesField, "gs://some-bucket/identities.yaml"), new(setupCheckSecretGetter)); err != nil {
+		t.Errorf("GetIdentities failed: %v", err)
+	}
+	if _, err := GetIdentities(ctx, cfg(IdentitiesField, "some-bucket/identities.yaml"), new(setupCheckSecretGetter)); err == nil {
+		t.Error("GetIdentities got no error for a path without gs://")
+	}
+
+	if _, err := NewLogTailEnricher(ctx, 10); err != nil {
+		t.Errorf("NewLogTailEnricher failed: %v", err)
+	}
+	if _, err := NewBuildLogs(ctx); err != nil {
+		t.Errorf("NewBuildLogs failed: %v", err)
+	}
+}
+
+func TestValidateOfflineTemplateFile(t *testing.T) {
+	cfg := strings.Replace(offlineConfig, "content: '{{.Build.Id}}'", "uri: gs://some-bucket/template.json", 1)
+
+	err := validateOffline(context.Background(), new(setUpNotifier), strings.NewReader(cfg), "")
+	if err == nil || !strings.Contains(err.Error(), "--template_file") {
+		t.Errorf("validateOffline without a template file got error %v, want one suggesting --template_file", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "template.json")
+	if err := ioutil.WriteFile(path, []byte(`{{.Build.Status}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n := new(setUpNotifier)
+	if err := validateOffline(context.Background(), n, strings.NewReader(cfg), path); err != nil {
+		t.Fatalf("validateOffline failed: %v", err)
+	}
+	if n.tmpl != "{{.Build.Status}}" {
+		t.Errorf("SetUp got template %q, want %q", n.tmpl, "{{.Build.Status}}")
+	}
+}
+
+func TestValidateOfflineErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  string
+		// setUpErr is returned by the notifier's SetUp.
+		setUpErr error
+		want     []string
+	}{{
+		name: "not YAML",
+		cfg:  "apiVersion: [",
+		want: []string{"failed to decode YAML config"},
+	}, {
+		name: "bad apiVersion",
+		cfg:  strings.Replace(offlineConfig, "cloud-build-notifiers/v1", "cloud-build-notifiers/v0", 1),
+		want: []string{"apiVersion"},
+	}, {
+		name: "multiple problems",
+		cfg: strings.NewReplacer(
+			"build.status == Build.Status.FAILURE", "build.salad == 1",
+			"'{{.Build.Id}}'", "'{{.Build.Id'",
+			"$(build.id)", "$(build.id",
+			"projects/my-project/secrets/token/versions/latest", "my-token",
+			"secretRef: token", "secretRef: unknown",
+		).Replace(offlineConfig),
+		want: []string{
+			"failed to compile CEL filter",
+			"got invalid template",
+			"invalid params",
+			"expected secret \"my-token\"",
+			"Secret with reference name \"unknown\"",
+		},
+	}, {
+		name: "duplicate secret",
+		cfg:  offlineConfig + "  - name: token\n    value: projects/my-project/secrets/other\n",
+		want: []string{"secret name \"token\" is used more than once"},
+	}, {
+		name:     "notifier rejects config",
+		cfg:      offlineConfig,
+		setUpErr: errors.New("expected delivery field \"channel\""),
+		want:     []string{"notifier rejected config: expected delivery field \"channel\""},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateOffline(context.Background(), &setUpNotifier{err: tc.setUpErr}, strings.NewReader(tc.cfg), "")
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("validateOffline got error %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tc.want) {
+				t.Errorf("validateOffline got %d problems, want %d:\n%v", len(errs), len(tc.want), err)
+			}
+			for i, want := range tc.want {
+				if i < len(errs) && !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
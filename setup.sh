@@ -27,6 +27,7 @@ the repo) are:
 * smtp
 * googlechat (alpha)
 * githubissues (alpha)
+* teams (alpha)
 
 Usage [in the cloud-build-notifiers repo root]:
 
@@ -77,7 +78,7 @@ main() {
   # Check that the user is using a supported notifier type in the correct
   # directory.
   case "${NOTIFIER_TYPE}" in
-  http | smtp | slack | bigquery | googlechat | githubissues) ;;
+  http | smtp | slack | bigquery | googlechat | githubissues | teams) ;;
   *) fail "${HELP}" ;;
   esac
 
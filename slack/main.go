@@ -19,7 +19,6 @@ import (
 	"context"
 	"fmt"
 	"text/template"
-	"strings"
 
 	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
 	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
@@ -29,6 +28,7 @@ import (
 
 const (
 	webhookURLSecretName = "webhookUrl"
+	logTailLinesField    = "logTailLines"
 )
 
 func main() {
@@ -41,11 +41,16 @@ type slackNotifier struct {
 	filter     notifiers.EventFilter
 	tmpl       *template.Template
 	webhookURL string
+	identities notifiers.Identities
+	enrichers  []notifiers.Enricher
+	name       string
 	br         notifiers.BindingResolver
 	tmplView   *notifiers.TemplateView
 }
 
 func (s *slackNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, blockKitTemplate string, sg notifiers.SecretGetter, br notifiers.BindingResolver) error {
+	s.name = notifiers.NotifierName(cfg, s)
+
 	prd, err := notifiers.MakeCELPredicate(cfg.Spec.Notification.Filter)
 	if err != nil {
 		return fmt.Errorf("failed to make a CEL predicate: %w", err)
@@ -65,11 +70,24 @@ func (s *slackNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, blockK
 		return fmt.Errorf("failed to get token secret: %w", err)
 	}
 	s.webhookURL = wu
-	tmpl, err := template.New("blockkit_template").Funcs(template.FuncMap{
-		"replace": func(s, old, new string) string {
-			return strings.ReplaceAll(s, old, new)
-		},
-	}).Parse(blockKitTemplate)
+
+	s.identities, err = notifiers.GetIdentities(ctx, cfg, sg)
+	if err != nil {
+		return fmt.Errorf("failed to get identities: %w", err)
+	}
+
+	if l, ok := cfg.Spec.Notification.Delivery[logTailLinesField]; ok {
+		lines, ok := l.(int)
+		if !ok || lines < 1 {
+			return fmt.Errorf("expected delivery config field %q to be a positive integer, got %v", logTailLinesField, l)
+		}
+		lt, err := notifiers.NewLogTailEnricher(ctx, lines)
+		if err != nil {
+			return fmt.Errorf("failed to create log tail enricher: %w", err)
+		}
+		s.enrichers = append(s.enrichers, lt)
+	}
+	tmpl, err := template.New("blockkit_template").Funcs(notifiers.TemplateFuncs()).Parse(blockKitTemplate)
 
 	s.tmpl = tmpl
 	s.br = br
@@ -77,6 +95,11 @@ func (s *slackNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, blockK
 	return nil
 }
 
+// Enrichers implements notifiers.EnrichingNotifier.
+func (s *slackNotifier) Enrichers() []notifiers.Enricher {
+	return s.enrichers
+}
+
 func (s *slackNotifier) SendNotification(ctx context.Context, build *cbpb.Build) error {
 
 	if !s.filter.Apply(ctx, build) {
@@ -91,8 +114,10 @@ func (s *slackNotifier) SendNotification(ctx context.Context, build *cbpb.Build)
 	}
 
 	s.tmplView = &notifiers.TemplateView{
-		Build:  &notifiers.BuildView{Build: build},
-		Params: bindings,
+		Build:        &notifiers.BuildView{Build: build},
+		Params:       bindings,
+		NotifierName: s.name,
+		Identities:   s.identities,
 	}
 
 	msg, err := s.writeMessage()
@@ -103,3 +103,35 @@ func TestWriteMessage(t *testing.T) {
 		t.Errorf("writeMessage got unexpected diff: %s", diff)
 	}
 }
+
+func TestWriteMessageIdentities(t *testing.T) {
+	n := new(slackNotifier)
+	tmpl, err := template.New("blockkit_template").Parse(`[{"type": "section", "text": {"type": "mrkdwn", "text": "Broken by {{with .Identities.Lookup .Build.Substitutions._COMMITTER}}<@{{.SlackID}}>{{else}}{{.Build.Substitutions._COMMITTER}}{{end}}"}}]`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	n.tmpl = tmpl
+
+	for _, tc := range []struct {
+		committer string
+		want      string
+	}{
+		{"octocat", "Broken by <@U0123ABCD>"},
+		{"someone-else", "Broken by someone-else"},
+	} {
+		n.tmplView = &notifiers.TemplateView{
+			Build: &notifiers.BuildView{Build: &cbpb.Build{
+				Status:        cbpb.Build_FAILURE,
+				Substitutions: map[string]string{"_COMMITTER": tc.committer},
+			}},
+			Identities: notifiers.Identities{"octocat": {SlackID: "U0123ABCD"}},
+		}
+		got, err := n.writeMessage()
+		if err != nil {
+			t.Fatalf("writeMessage failed: %v", err)
+		}
+		if text := got.Attachments[0].Blocks.BlockSet[0].(*slack.SectionBlock).Text.Text; text != tc.want {
+			t.Errorf("got text %q, want %q", text, tc.want)
+		}
+	}
+}
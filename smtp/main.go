@@ -43,6 +43,7 @@ type smtpNotifier struct {
 	filter   notifiers.EventFilter
 	tmpl     *template.Template
 	mcfg     mailConfig
+	name     string
 	br       notifiers.BindingResolver
 	tmplView *notifiers.TemplateView
 }
@@ -53,12 +54,14 @@ type mailConfig struct {
 }
 
 func (s *smtpNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, cfgTemplate string, sg notifiers.SecretGetter, br notifiers.BindingResolver) error {
+	s.name = notifiers.NotifierName(cfg, s)
+
 	prd, err := notifiers.MakeCELPredicate(cfg.Spec.Notification.Filter)
 	if err != nil {
 		return fmt.Errorf("failed to create CELPredicate: %w", err)
 	}
 	s.filter = prd
-	tmpl, err := template.New("email_template").Parse(cfgTemplate)
+	tmpl, err := template.New("email_template").Funcs(notifiers.TemplateFuncs()).Parse(cfgTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML email template: %w", err)
 	}
@@ -143,8 +146,9 @@ func (s *smtpNotifier) SendNotification(ctx context.Context, build *cbpb.Build)
 		log.Errorf("failed to resolve bindings :%v", err)
 	}
 	s.tmplView = &notifiers.TemplateView{
-		Build:  &notifiers.BuildView{Build: build},
-		Params: bindings,
+		Build:        &notifiers.BuildView{Build: build},
+		Params:       bindings,
+		NotifierName: s.name,
 	}
 	log.Infof("sending email for (build id = %q, status = %s)", build.GetId(), build.GetStatus())
 	return s.sendSMTPNotification()
@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	log "github.com/golang/glog"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+)
+
+const (
+	webhookURLSecretName = "webhookUrl"
+	adaptiveCardType     = "application/vnd.microsoft.card.adaptive"
+	adaptiveCardSchema   = "http://adaptivecards.io/schemas/adaptive-card.json"
+	// adaptiveCardVersion is the newest Adaptive Card schema version that Teams renders.
+	adaptiveCardVersion = "1.4"
+)
+
+// defaultFactsTemplate renders the card's fact rows if the config has no template: the build's project, trigger, branch
+// or tag, and duration, each only if known.
+const defaultFactsTemplate = `[
+  {"title": "Project", "value": {{json .Build.ProjectID}}}
+  {{- with .Build.Substitutions.TRIGGER_NAME}}, {"title": "Trigger", "value": {{json .}}}{{end}}
+  {{- with .Build.Substitutions.BRANCH_NAME}}, {"title": "Branch", "value": {{json .}}}
+  {{- else}}{{with .Build.Substitutions.TAG_NAME}}, {"title": "Tag", "value": {{json .}}}{{end}}{{end}}
+  {{- with .Build.WorkDuration}}, {"title": "Duration", "value": {{json .}}}{{end}}
+]`
+
+func main() {
+	if err := notifiers.Main(new(teamsNotifier)); err != nil {
+		log.Fatalf("fatal error: %v", err)
+	}
+}
+
+type teamsNotifier struct {
+	filter     notifiers.EventFilter
+	webhookURL string
+	// client is used for the webhook calls. SetUp defaults it to a tracing client if unset.
+	client *http.Client
+	// factsTmpl renders the card's fact rows as a JSON list of `{"title": ..., "value": ...}` objects.
+	factsTmpl *template.Template
+	name      string
+	br        notifiers.BindingResolver
+}
+
+// message is the payload of a Teams incoming webhook, which wraps the card in an attachment.
+// See https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using.
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string        `json:"contentType"`
+	ContentURL  *string       `json:"contentUrl"`
+	Content     *adaptiveCard `json:"content"`
+}
+
+// adaptiveCard is the subset of the Adaptive Card schema that the notifier uses.
+// See https://adaptivecards.io/explorer/.
+type adaptiveCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []cardElement `json:"body"`
+	Actions []cardAction  `json:"actions,omitempty"`
+	MSTeams *msTeams      `json:"msteams,omitempty"`
+}
+
+// cardElement is a Container, TextBlock, or FactSet element, depending on its Type.
+type cardElement struct {
+	Type   string        `json:"type"`
+	Style  string        `json:"style,omitempty"`
+	Bleed  bool          `json:"bleed,omitempty"`
+	Items  []cardElement `json:"items,omitempty"`
+	Text   string        `json:"text,omitempty"`
+	Size   string        `json:"size,omitempty"`
+	Weight string        `json:"weight,omitempty"`
+	Color  string        `json:"color,omitempty"`
+	Wrap   bool          `json:"wrap,omitempty"`
+	Facts  []fact        `json:"facts,omitempty"`
+}
+
+type fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type cardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type msTeams struct {
+	Width string `json:"width"`
+}
+
+func (t *teamsNotifier) SetUp(ctx context.Context, cfg *notifiers.Config, factsTemplate string, sg notifiers.SecretGetter, br notifiers.BindingResolver) error {
+	t.name = notifiers.NotifierName(cfg, t)
+
+	prd, err := notifiers.MakeCELPredicate(cfg.Spec.Notification.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to make a CEL predicate: %w", err)
+	}
+	t.filter = prd
+
+	refs, err := notifiers.GetSecretRefs(cfg.Spec.Notification.Delivery, webhookURLSecretName)
+	if err != nil {
+		return fmt.Errorf("failed to get Secret refs from delivery config: %w", err)
+	}
+	secrets, err := notifiers.GetSecrets(ctx, sg, cfg.Spec.Secrets, refs)
+	if err != nil {
+		return err
+	}
+	t.webhookURL = secrets[webhookURLSecretName]
+
+	if factsTemplate == "" {
+		factsTemplate = defaultFactsTemplate
+	}
+	t.factsTmpl, err = template.New("facts_template").Funcs(notifiers.TemplateFuncs()).Parse(factsTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse facts template: %w", err)
+	}
+
+	if t.client == nil {
+		t.client = notifiers.NewTracingHTTPClient()
+	}
+	t.br = br
+	return nil
+}
+
+func (t *teamsNotifier) SendNotification(ctx context.Context, build *cbpb.Build) error {
+	if !t.filter.Apply(ctx, build) {
+		log.V(2).Infof("not sending Teams message for event (build id = %s, status = %v)", build.Id, build.Status)
+		return nil
+	}
+
+	log.Infof("sending Teams message for Build %q (status: %q)", build.Id, build.Status)
+	bindings, err := t.br.Resolve(ctx, nil, build)
+	if err != nil {
+		return fmt.Errorf("failed to resolve bindings: %w", err)
+	}
+	view := &notifiers.TemplateView{
+		Build:        &notifiers.BuildView{Build: build},
+		Params:       bindings,
+		NotifierName: t.name,
+	}
+
+	msg, err := t.writeMessage(view)
+	if err != nil {
+		return fmt.Errorf("failed to write Teams message: %w", err)
+	}
+	payload := new(bytes.Buffer)
+	if err := json.NewEncoder(payload).Encode(msg); err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.webhookURL, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create a new HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GCB-Notifier/0.1 (http)")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The webhook URL is a secret, so it's not logged.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("got a non-OK response status %q (%d) from the Teams webhook", resp.Status, resp.StatusCode)
+	}
+
+	log.V(2).Infoln("send HTTP request successfully")
+	return nil
+}
+
+// statusColors returns the TextBlock color and Container style that the card's header shows the build status in.
+func statusColors(s cbpb.Build_Status) (color, style string) {
+	switch s {
+	case cbpb.Build_SUCCESS:
+		return "Good", "good"
+	case cbpb.Build_FAILURE, cbpb.Build_INTERNAL_ERROR, cbpb.Build_TIMEOUT:
+		return "Attention", "attention"
+	case cbpb.Build_CANCELLED, cbpb.Build_EXPIRED:
+		return "Warning", "warning"
+	default:
+		// STATUS_UNKNOWN, PENDING, QUEUED, and WORKING.
+		return "Accent", "accent"
+	}
+}
+
+// facts renders the card's fact rows from the template view.
+func (t *teamsNotifier) facts(view *notifiers.TemplateView) ([]fact, error) {
+	var buf bytes.Buffer
+	if err := notifiers.ExecuteTemplate(t.factsTmpl, &buf, view); err != nil {
+		return nil, err
+	}
+	var facts []fact
+	if err := json.Unmarshal(buf.Bytes(), &facts); err != nil {
+		return nil, fmt.Errorf("expected facts template to render a JSON list of facts, got %q: %w", buf.String(), err)
+	}
+	return facts, nil
+}
+
+// writeMessage returns the webhook message for the build in the template view.
+func (t *teamsNotifier) writeMessage(view *notifiers.TemplateView) (*message, error) {
+	b := view.Build
+	facts, err := t.facts(view)
+	if err != nil {
+		return nil, err
+	}
+	logURL, err := notifiers.AddUTMParams(b.LogUrl, notifiers.ChatMedium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add UTM params: %w", err)
+	}
+
+	color, style := statusColors(b.Status)
+	card := &adaptiveCard{
+		Schema:  adaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: adaptiveCardVersion,
+		Body: []cardElement{{
+			Type:  "Container",
+			Style: style,
+			Bleed: true,
+			Items: []cardElement{{
+				Type:   "TextBlock",
+				Text:   fmt.Sprintf("Build %s: %s", b.Status, b.Id),
+				Size:   "Large",
+				Weight: "Bolder",
+				Color:  color,
+				Wrap:   true,
+			}},
+		}, {
+			Type:  "FactSet",
+			Facts: facts,
+		}},
+		Actions: []cardAction{{
+			Type:  "Action.OpenUrl",
+			Title: "View logs",
+			URL:   logURL,
+		}, {
+			Type:  "Action.OpenUrl",
+			Title: "Open in Cloud console",
+			URL:   b.ConsoleURL(),
+		}},
+		MSTeams: &msTeams{Width: "Full"},
+	}
+	if detail := b.GetFailureInfo().GetDetail(); detail != "" {
+		card.Body = append(card.Body, cardElement{Type: "TextBlock", Text: detail, Color: "Attention", Wrap: true})
+	}
+
+	return &message{
+		Type:        "message",
+		Attachments: []attachment{{ContentType: adaptiveCardType, Content: card}},
+	}, nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+	"time"
+
+	cbpb "cloud.google.com/go/cloudbuild/apiv1/v2/cloudbuildpb"
+	"github.com/GoogleCloudPlatform/cloud-build-notifiers/lib/notifiers"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const webhookSecretResource = "projects/test-project/secrets/teams-webhook/versions/latest"
+
+type fakeSecretGetter struct {
+	url string
+}
+
+func (f *fakeSecretGetter) GetSecret(_ context.Context, name string) (string, error) {
+	if name != webhookSecretResource {
+		return "", fmt.Errorf("unexpected secret %s", name)
+	}
+	return f.url, nil
+}
+
+type fakeBindingResolver struct {
+	bindings map[string]string
+}
+
+func (f *fakeBindingResolver) Resolve(_ context.Context, _ notifiers.SecretGetter, _ *cbpb.Build) (map[string]string, error) {
+	if f.bindings == nil {
+		return map[string]string{}, nil
+	}
+	return f.bindings, nil
+}
+
+func testConfig(delivery map[string]interface{}) *notifiers.Config {
+	return &notifiers.Config{
+		Spec: &notifiers.Spec{
+			Notification: &notifiers.Notification{
+				Filter:   `build.status == Build.Status.FAILURE`,
+				Delivery: delivery,
+			},
+			Secrets: []*notifiers.Secret{{LocalName: "teams-webhook", ResourceName: webhookSecretResource}},
+		},
+	}
+}
+
+// newTestNotifier returns a teamsNotifier set up with the given facts template that posts to a test server recording the
+// messages it receives and replying with the given status code.
+func newTestNotifier(t *testing.T, factsTemplate string, code int) (*teamsNotifier, *[]message) {
+	t.Helper()
+	var got []message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("got Content-Type %q, want application/json", ct)
+		}
+		var m message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		got = append(got, m)
+		w.WriteHeader(code)
+		fmt.Fprint(w, "1")
+	}))
+	t.Cleanup(srv.Close)
+
+	n := new(teamsNotifier)
+	cfg := testConfig(map[string]interface{}{
+		"webhookUrl": map[interface{}]interface{}{"secretRef": "teams-webhook"},
+	})
+	if err := n.SetUp(context.Background(), cfg, factsTemplate, &fakeSecretGetter{url: srv.URL}, new(fakeBindingResolver)); err != nil {
+		t.Fatalf("SetUp failed: %v", err)
+	}
+	return n, &got
+}
+
+func TestSetUpMissingWebhook(t *testing.T) {
+	n := new(teamsNotifier)
+	if err := n.SetUp(context.Background(), testConfig(map[string]interface{}{}), "", new(fakeSecretGetter), new(fakeBindingResolver)); err == nil {
+		t.Error("SetUp succeeded without a webhookUrl, want an error")
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	n, got := newTestNotifier(t, "", http.StatusOK)
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	build := &cbpb.Build{
+		ProjectId:     "my-project-id",
+		Id:            "some-build-id",
+		Status:        cbpb.Build_FAILURE,
+		LogUrl:        "https://some.example.com/log/url",
+		StartTime:     timestamppb.New(start),
+		FinishTime:    timestamppb.New(start.Add(3*time.Minute + 25*time.Second)),
+		FailureInfo:   &cbpb.Build_FailureInfo{Detail: "step 2 exited with code 1"},
+		Substitutions: map[string]string{"TRIGGER_NAME": "deploy-prod", "BRANCH_NAME": "main"},
+	}
+	if err := n.SendNotification(context.Background(), build); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+
+	want := []message{{
+		Type: "message",
+		Attachments: []attachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: &adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body: []cardElement{{
+					Type:  "Container",
+					Style: "attention",
+					Bleed: true,
+					Items: []cardElement{{
+						Type:   "TextBlock",
+						Text:   "Build FAILURE: some-build-id",
+						Size:   "Large",
+						Weight: "Bolder",
+						Color:  "Attention",
+						Wrap:   true,
+					}},
+				}, {
+					Type: "FactSet",
+					Facts: []fact{
+						{Title: "Project", Value: "my-project-id"},
+						{Title: "Trigger", Value: "deploy-prod"},
+						{Title: "Branch", Value: "main"},
+						{Title: "Duration", Value: "3m25s"},
+					},
+				}, {
+					Type:  "TextBlock",
+					Text:  "step 2 exited with code 1",
+					Color: "Attention",
+					Wrap:  true,
+				}},
+				Actions: []cardAction{{
+					Type:  "Action.OpenUrl",
+					Title: "View logs",
+					URL:   "https://some.example.com/log/url?utm_campaign=google-cloud-build-notifiers&utm_medium=chat&utm_source=google-cloud-build",
+				}, {
+					Type:  "Action.OpenUrl",
+					Title: "Open in Cloud console",
+					URL:   "https://console.cloud.google.com/cloud-build/builds/some-build-id?project=my-project-id",
+				}},
+				MSTeams: &msTeams{Width: "Full"},
+			},
+		}},
+	}}
+	if diff := cmp.Diff(want, *got); diff != "" {
+		t.Errorf("got unexpected Teams messages (-want +got):\n%s", diff)
+	}
+}
+
+func TestSendNotificationFiltered(t *testing.T) {
+	n, got := newTestNotifier(t, "", http.StatusOK)
+	if err := n.SendNotification(context.Background(), &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_SUCCESS}); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if len(*got) != 0 {
+		t.Errorf("got %d messages for a filtered build, want none", len(*got))
+	}
+}
+
+func TestWriteMessageStatusColors(t *testing.T) {
+	for _, tc := range []struct {
+		status    cbpb.Build_Status
+		wantColor string
+		wantStyle string
+	}{
+		{cbpb.Build_SUCCESS, "Good", "good"},
+		{cbpb.Build_FAILURE, "Attention", "attention"},
+		{cbpb.Build_INTERNAL_ERROR, "Attention", "attention"},
+		{cbpb.Build_TIMEOUT, "Attention", "attention"},
+		{cbpb.Build_CANCELLED, "Warning", "warning"},
+		{cbpb.Build_EXPIRED, "Warning", "warning"},
+		{cbpb.Build_WORKING, "Accent", "accent"},
+		{cbpb.Build_QUEUED, "Accent", "accent"},
+	} {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			n := &teamsNotifier{factsTmpl: template.Must(template.New("facts_template").Funcs(notifiers.TemplateFuncs()).Parse(defaultFactsTemplate))}
+			msg, err := n.writeMessage(&notifiers.TemplateView{Build: &notifiers.BuildView{Build: &cbpb.Build{Id: "b", Status: tc.status}}})
+			if err != nil {
+				t.Fatalf("writeMessage failed: %v", err)
+			}
+			header := msg.Attachments[0].Content.Body[0]
+			if header.Style != tc.wantStyle {
+				t.Errorf("got header style %q, want %q", header.Style, tc.wantStyle)
+			}
+			if title := header.Items[0]; title.Color != tc.wantColor || title.Text != "Build "+tc.status.String()+": b" {
+				t.Errorf("got title %q in color %q, want %q in %q", title.Text, title.Color, "Build "+tc.status.String()+": b", tc.wantColor)
+			}
+		})
+	}
+}
+
+func TestSendNotificationFactsTemplate(t *testing.T) {
+	n, got := newTestNotifier(t, `[{"title": "Build", "value": {{json .Build.Id}}}, {"title": "Env", "value": {{json .Params.env}}}]`, http.StatusOK)
+	n.br = &fakeBindingResolver{bindings: map[string]string{"env": "prod"}}
+
+	if err := n.SendNotification(context.Background(), &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE}); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if len(*got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(*got))
+	}
+	want := []fact{{Title: "Build", Value: "some-build-id"}, {Title: "Env", Value: "prod"}}
+	if diff := cmp.Diff(want, (*got)[0].Attachments[0].Content.Body[1].Facts); diff != "" {
+		t.Errorf("got unexpected facts (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetUpInvalidFactsTemplate(t *testing.T) {
+	n := new(teamsNotifier)
+	cfg := testConfig(map[string]interface{}{
+		"webhookUrl": map[interface{}]interface{}{"secretRef": "teams-webhook"},
+	})
+	if err := n.SetUp(context.Background(), cfg, "{{.Build", &fakeSecretGetter{url: "https://example.com"}, new(fakeBindingResolver)); err == nil {
+		t.Error("SetUp succeeded with an unparsable facts template, want an error")
+	}
+}
+
+func TestSendNotificationFactsTemplateNotJSON(t *testing.T) {
+	n, got := newTestNotifier(t, "Build {{.Build.Id}}", http.StatusOK)
+	err := n.SendNotification(context.Background(), &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE})
+	if err == nil {
+		t.Fatal("SendNotification succeeded with a facts template that doesn't render JSON, want an error")
+	}
+	if len(*got) != 0 {
+		t.Errorf("got %d messages, want none", len(*got))
+	}
+}
+
+func TestSendNotificationNonOKResponse(t *testing.T) {
+	n, got := newTestNotifier(t, "", http.StatusBadRequest)
+	if err := n.SendNotification(context.Background(), &cbpb.Build{Id: "some-build-id", Status: cbpb.Build_FAILURE}); err == nil {
+		t.Error("SendNotification succeeded on a 400 response, want an error")
+	}
+	if len(*got) != 1 {
+		t.Errorf("got %d messages, want 1", len(*got))
+	}
+}